@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/conjunction"
+	"github.com/Funkit/tle-provider/jobs"
+	"github.com/Funkit/tle-provider/sgp4"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// JobConjunctionScreening job type of the conjunction screenings, with a ScreeningRequest as parameters
+const JobConjunctionScreening = "conjunction_screening"
+
+// ScreeningRequest conjunction screening of the primary satellites, selected by name, NORAD ID or constellation,
+// against the whole catalog. The window starts now and lasts one day by default.
+type ScreeningRequest struct {
+	Satellites     []string  `json:"satellites,omitempty"`
+	NORADIDs       []int     `json:"norad_ids,omitempty"`
+	Constellations []string  `json:"constellations,omitempty"`
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"`
+	ThresholdKm    float64   `json:"threshold_km,omitempty"`
+	StepSeconds    float64   `json:"step_seconds,omitempty"`
+}
+
+// options screening options of the request
+func (sr ScreeningRequest) options() conjunction.Options {
+	return conjunction.Options{
+		Start:     sr.Start,
+		End:       sr.End,
+		Threshold: sr.ThresholdKm,
+		Step:      time.Duration(sr.StepSeconds * float64(time.Second)),
+	}
+}
+
+// screeningPrimaries propagators of the primary satellites of the request in the current catalog
+func (s *Server) screeningPrimaries(request ScreeningRequest) ([]*sgp4.Propagator, error) {
+	filter, err := newSatelliteFilter(request.Satellites, request.NORADIDs, request.Constellations)
+	if err != nil {
+		return nil, err
+	}
+	primaries, _ := s.filteredPropagators(filter)
+	if len(primaries) == 0 {
+		return nil, apierror.Wrap(fmt.Errorf("no primary satellite found"), apierror.ErrNotFound)
+	}
+	return primaries, nil
+}
+
+// prepareScreening validates the screening request at submission, and sets the default window
+func (s *Server) prepareScreening(params json.RawMessage) (interface{}, error) {
+	var request ScreeningRequest
+	if err := json.Unmarshal(params, &request); err != nil {
+		return nil, fmt.Errorf("invalid screening request: %v", err)
+	}
+	if len(request.Satellites) == 0 && len(request.NORADIDs) == 0 && len(request.Constellations) == 0 {
+		return nil, fmt.Errorf("no primary satellite, expected satellites, norad_ids or constellations")
+	}
+	if request.Start.IsZero() {
+		request.Start = time.Now().UTC().Truncate(time.Second)
+	}
+	if request.End.IsZero() {
+		request.End = request.Start.Add(defaultSearchWindow)
+	}
+
+	primaries, err := s.screeningPrimaries(request)
+	if err != nil {
+		return nil, err
+	}
+	if err := request.options().Validate(len(primaries)); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// runScreening screens the primary satellites against the catalog at the time the job runs
+func (s *Server) runScreening(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var request ScreeningRequest
+	if err := json.Unmarshal(params, &request); err != nil {
+		return nil, err
+	}
+	primaries, err := s.screeningPrimaries(request)
+	if err != nil {
+		return nil, err
+	}
+	catalog, _ := s.filteredPropagators(satelliteFilter{})
+
+	return conjunction.Screen(ctx, primaries, catalog, request.options())
+}
+
+// postScreening submits a conjunction screening job, shortcut of a job submission with the conjunction_screening type
+func (s *Server) postScreening() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params json.RawMessage
+		if err := render.DecodeJSON(r.Body, &params); err != nil {
+			badRequest(w, r, fmt.Errorf("invalid screening request: %v", err))
+			return
+		}
+		s.submitJob(w, r, JobConjunctionScreening, params)
+	}
+}
+
+// getScreenings lists the conjunction screening jobs, same as /jobs filtered by the conjunction_screening type
+func (s *Server) getScreenings() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.renderJobs(w, r, JobConjunctionScreening)
+	}
+}
+
+// getScreening returns the status of a conjunction screening job, same as /jobs/{id} for the screenings only
+func (s *Server) getScreening() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		job, err := s.jobManager().Get(id)
+		if err == nil && job.Type != JobConjunctionScreening {
+			err = fmt.Errorf("%w: %v is not a conjunction screening", jobs.ErrNotFound, id)
+		}
+		if err != nil {
+			handleJobError(w, r, err)
+			return
+		}
+
+		if err := render.Render(w, r, job); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Funkit/tle-provider/conjunction"
+	"github.com/Funkit/tle-provider/jobs"
+)
+
+func TestPostScreening(t *testing.T) {
+	s := newSampleServer(t)
+
+	tests := []struct {
+		name         string
+		body         string
+		wantRespCode int
+	}{
+		{"satellite", `{"satellites":["ONEWEB-0012"],"start":"2022-07-26T00:00:00Z","end":"2022-07-26T06:00:00Z"}`, http.StatusAccepted},
+		{"constellation with threshold", `{"constellations":["oneweb"],"start":"2022-07-26T00:00:00Z","threshold_km":5000,"step_seconds":120}`, http.StatusAccepted},
+		{"default window", `{"norad_ids":[44057]}`, http.StatusAccepted},
+		{"invalid body", `{"satellites":"ONEWEB-0012"}`, http.StatusBadRequest},
+		{"no primary", `{"start":"2022-07-26T00:00:00Z"}`, http.StatusBadRequest},
+		{"invalid window", `{"satellites":["ONEWEB-0012"],"start":"2022-07-26T00:00:00Z","end":"2022-07-25T00:00:00Z"}`, http.StatusBadRequest},
+		{"too many samples", `{"satellites":["ONEWEB-0012"],"start":"2022-07-26T00:00:00Z","step_seconds":0.01}`, http.StatusBadRequest},
+		{"unknown satellite", `{"satellites":["UNKNOWN"]}`, http.StatusNotFound},
+		{"unknown constellation", `{"constellations":["unknown"]}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "/conjunctions", strings.NewReader(tt.body))
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+			if response.Code != http.StatusAccepted {
+				return
+			}
+
+			var job jobs.Job
+			if err := json.Unmarshal(response.Body.Bytes(), &job); err != nil || job.Type != JobConjunctionScreening || response.Header().Get("Location") != "/jobs/"+job.ID {
+				t.Fatalf("invalid job %s", response.Body.String())
+			}
+
+			job = waitJob(t, s, job.ID)
+			if job.Status != jobs.StatusSucceeded {
+				t.Fatalf("expected a successful screening, got %+v", job)
+			}
+			var result conjunction.Result
+			if err := json.Unmarshal(jobResult(t, s, job.ID), &result); err != nil {
+				t.Fatalf("invalid result: %v", err)
+			}
+			if tt.name == "constellation with threshold" && (result.Primaries != 3 || len(result.Conjunctions) == 0) {
+				t.Errorf("expected conjunctions between the 3 primaries, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestGetScreenings(t *testing.T) {
+	s := newSampleServer(t)
+
+	submit := func(target, body string) jobs.Job {
+		req, _ := http.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		response := executeRequest(req, s)
+		var job jobs.Job
+		if err := json.Unmarshal(response.Body.Bytes(), &job); err != nil || response.Code != http.StatusAccepted {
+			t.Fatalf("job not submitted, got %d %s", response.Code, response.Body.String())
+		}
+		return job
+	}
+	screening := submit("/conjunctions", `{"satellites":["ONEWEB-0012"],"start":"2022-07-26T00:00:00Z","end":"2022-07-26T01:00:00Z"}`)
+	passes := submit("/jobs", `{"type":"passes","params":{"satellite":"ONEWEB-0012","observer":{"latitude":48.8566,"longitude":2.3522},"start":"2022-07-26T00:00:00Z"}}`)
+
+	req, _ := http.NewRequest(http.MethodGet, "/conjunctions", nil)
+	response := executeRequest(req, s)
+	var list []jobs.Job
+	if err := json.Unmarshal(response.Body.Bytes(), &list); err != nil || len(list) != 1 || list[0].ID != screening.ID {
+		t.Errorf("expected only the screening job, got %s", response.Body.String())
+	}
+
+	tests := []struct {
+		name         string
+		id           string
+		wantRespCode int
+	}{
+		{"screening", screening.ID, http.StatusOK},
+		{"other job type", passes.ID, http.StatusNotFound},
+		{"unknown", "unknown", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/conjunctions/"+tt.id, nil)
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+			var job jobs.Job
+			if tt.wantRespCode == http.StatusOK && (json.Unmarshal(response.Body.Bytes(), &job) != nil || job.Type != JobConjunctionScreening) {
+				t.Errorf("invalid job %s", response.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/ephemeris"
+	"github.com/Funkit/tle-provider/sgp4"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// JobEphemeris job type of the bulk ephemeris generations, with an EphemerisRequest as parameters
+	JobEphemeris = "ephemeris"
+	// defaultEphemerisStep step of the ephemeris when not specified
+	defaultEphemerisStep = time.Minute
+	// maxEphemerisJobPoints maximum number of states of an ephemeris job, all satellites included
+	maxEphemerisJobPoints = 2000000
+)
+
+// EphemerisRequest generation of the ephemeris files of the satellites selected by name, NORAD ID or constellation.
+// The window starts now and lasts one day by default, and the frame defaults to the one of the format.
+type EphemerisRequest struct {
+	Satellites     []string  `json:"satellites,omitempty"`
+	NORADIDs       []int     `json:"norad_ids,omitempty"`
+	Constellations []string  `json:"constellations,omitempty"`
+	Format         string    `json:"format"`
+	Frame          string    `json:"frame,omitempty"`
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"`
+	StepSeconds    float64   `json:"step_seconds,omitempty"`
+}
+
+// options ephemeris options of the request
+func (er EphemerisRequest) options() ephemeris.Options {
+	return ephemeris.Options{
+		Start: er.Start,
+		End:   er.End,
+		Step:  time.Duration(er.StepSeconds * float64(time.Second)),
+		Frame: er.Frame,
+	}
+}
+
+// EphemerisFile ephemeris file of a satellite generated by a job, with the error of the satellites which cannot be propagated
+type EphemerisFile struct {
+	SatelliteName string `json:"satellite_name"`
+	NORADID       int    `json:"norad_id"`
+	Filename      string `json:"filename"`
+	Content       string `json:"content,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// ephemerisFilename name of the ephemeris file of the satellite, from its NORAD ID
+func ephemerisFilename(noradID int, format string) string {
+	extension, _ := ephemeris.Extension(format)
+	return strconv.Itoa(noradID) + extension
+}
+
+// getEphemeris returns the ephemeris file of a satellite over a time window, as an attachment
+func (s *Server) getEphemeris() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = ephemeris.FormatOEM
+		}
+		contentType, err := ephemeris.ContentType(format)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		p, err := s.propagator(chi.URLParam(r, "satellite"))
+		if err != nil {
+			handleFilterError(w, r, err)
+			return
+		}
+
+		start, end, step, err := parseSearchWindow(r, defaultEphemerisStep)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		options := ephemeris.Options{Start: start, End: end, Step: step, Frame: r.URL.Query().Get("frame"), EOP: s.eopTable()}
+
+		eph, err := ephemeris.New(p, format, options)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		var body bytes.Buffer
+		if err := ephemeris.Write(&body, format, eph); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", ephemerisFilename(p.Satellite.NORADID, format)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(body.Bytes())
+	}
+}
+
+// ephemerisPropagators propagators of the satellites of the request in the current catalog
+func (s *Server) ephemerisPropagators(request EphemerisRequest) ([]*sgp4.Propagator, error) {
+	filter, err := newSatelliteFilter(request.Satellites, request.NORADIDs, request.Constellations)
+	if err != nil {
+		return nil, err
+	}
+	propagators, _ := s.filteredPropagators(filter)
+	if len(propagators) == 0 {
+		return nil, apierror.Wrap(fmt.Errorf("no satellite found"), apierror.ErrNotFound)
+	}
+	return propagators, nil
+}
+
+// prepareEphemeris validates the ephemeris request at submission, and sets the default values
+func (s *Server) prepareEphemeris(params json.RawMessage) (interface{}, error) {
+	var request EphemerisRequest
+	if err := json.Unmarshal(params, &request); err != nil {
+		return nil, fmt.Errorf("invalid ephemeris request: %v", err)
+	}
+	if len(request.Satellites) == 0 && len(request.NORADIDs) == 0 && len(request.Constellations) == 0 {
+		return nil, fmt.Errorf("no satellite, expected satellites, norad_ids or constellations")
+	}
+	if request.Format == "" {
+		request.Format = ephemeris.FormatOEM
+	}
+	if request.Frame == "" {
+		request.Frame = ephemeris.DefaultFrame(request.Format)
+	}
+	if request.Start.IsZero() {
+		request.Start = time.Now().UTC().Truncate(time.Second)
+	}
+	if request.End.IsZero() {
+		request.End = request.Start.Add(defaultSearchWindow)
+	}
+	if request.StepSeconds == 0 {
+		request.StepSeconds = defaultEphemerisStep.Seconds()
+	}
+
+	options := request.options()
+	if err := options.Validate(request.Format); err != nil {
+		return nil, err
+	}
+	propagators, err := s.ephemerisPropagators(request)
+	if err != nil {
+		return nil, err
+	}
+	if points := int64(options.Points()) * int64(len(propagators)); points > maxEphemerisJobPoints {
+		return nil, fmt.Errorf("too many points (%d), the maximum is %d", points, maxEphemerisJobPoints)
+	}
+	return request, nil
+}
+
+// runEphemeris generates the ephemeris files of the satellites, the satellites which cannot be propagated having an error instead
+func (s *Server) runEphemeris(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var request EphemerisRequest
+	if err := json.Unmarshal(params, &request); err != nil {
+		return nil, err
+	}
+	propagators, err := s.ephemerisPropagators(request)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]EphemerisFile, 0, len(propagators))
+	for _, p := range propagators {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		file := EphemerisFile{
+			SatelliteName: p.Satellite.SatelliteName,
+			NORADID:       p.Satellite.NORADID,
+			Filename:      ephemerisFilename(p.Satellite.NORADID, request.Format),
+		}
+
+		options := request.options()
+		options.EOP = s.eopTable()
+		var body bytes.Buffer
+		eph, err := ephemeris.New(p, request.Format, options)
+		if err == nil {
+			err = ephemeris.Write(&body, request.Format, eph)
+		}
+		if err != nil {
+			file.Error = err.Error()
+		} else {
+			file.Content = body.String()
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Funkit/tle-provider/jobs"
+)
+
+func TestGetEphemeris(t *testing.T) {
+	s := newSampleServer(t)
+
+	tests := []struct {
+		name         string
+		target       string
+		wantRespCode int
+		wantFilename string
+		wantLine     string
+	}{
+		{"OEM", "/tle/ONEWEB-0012/ephemeris?start=2022-07-26T00:00:00Z&end=2022-07-26T01:00:00Z", http.StatusOK, "44057.oem", "REF_FRAME = TEME"},
+		{"OEM in ITRF", "/tle/ONEWEB-0012/ephemeris?format=oem&frame=ITRF&start=2022-07-26T00:00:00Z", http.StatusOK, "44057.oem", "REF_FRAME = ITRF"},
+		{"STK", "/tle/ONEWEB-0012/ephemeris?format=stk&start=2022-07-26T00:00:00Z&step=30", http.StatusOK, "44057.e", "NumberOfEphemerisPoints 2881"},
+		{"STK in GCRF", "/tle/ONEWEB-0012/ephemeris?format=stk&frame=GCRF&start=2022-07-26T00:00:00Z", http.StatusOK, "44057.e", "CoordinateSystem        ICRF"},
+		{"SP3", "/tle/ONEWEB-0012/ephemeris?format=sp3&start=2022-07-26T00:00:00Z&step=300", http.StatusOK, "44057.sp3", "EOF"},
+		{"invalid format", "/tle/ONEWEB-0012/ephemeris?format=txt", http.StatusBadRequest, "", ""},
+		{"invalid frame", "/tle/ONEWEB-0012/ephemeris?format=sp3&frame=TEME", http.StatusBadRequest, "", ""},
+		{"too many points", "/tle/ONEWEB-0012/ephemeris?step=0.1", http.StatusBadRequest, "", ""},
+		{"unknown satellite", "/tle/UNKNOWN/ephemeris", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.target, nil)
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+			if response.Code != http.StatusOK {
+				return
+			}
+
+			if got := response.Header().Get("Content-Disposition"); got != `attachment; filename="`+tt.wantFilename+`"` {
+				t.Errorf("unexpected Content-Disposition %v", got)
+			}
+			if !strings.Contains(response.Body.String(), "\n"+tt.wantLine+"\n") {
+				t.Errorf("expected the line %q in %s", tt.wantLine, response.Body.String()[:200])
+			}
+		})
+	}
+}
+
+func TestEphemerisJob(t *testing.T) {
+	s := newSampleServer(t)
+
+	tests := []struct {
+		name         string
+		params       string
+		wantRespCode int
+		wantFiles    int
+	}{
+		{"constellation", `{"constellations":["oneweb"],"format":"stk","start":"2022-07-26T00:00:00Z","end":"2022-07-26T06:00:00Z"}`, http.StatusAccepted, 3},
+		{"default format", `{"norad_ids":[44057]}`, http.StatusAccepted, 1},
+		{"no satellite", `{"format":"oem"}`, http.StatusBadRequest, 0},
+		{"invalid format", `{"norad_ids":[44057],"format":"txt"}`, http.StatusBadRequest, 0},
+		{"too many points", `{"constellations":["oneweb"],"step_seconds":0.1}`, http.StatusBadRequest, 0},
+		{"unknown satellite", `{"satellites":["UNKNOWN"]}`, http.StatusNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "/jobs", strings.NewReader(`{"type":"ephemeris","params":`+tt.params+`}`))
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+			if response.Code != http.StatusAccepted {
+				return
+			}
+
+			var job jobs.Job
+			if err := json.Unmarshal(response.Body.Bytes(), &job); err != nil {
+				t.Fatalf("invalid job %s", response.Body.String())
+			}
+			if job = waitJob(t, s, job.ID); job.Status != jobs.StatusSucceeded {
+				t.Fatalf("expected a successful job, got %+v", job)
+			}
+
+			var files []EphemerisFile
+			if err := json.Unmarshal(jobResult(t, s, job.ID), &files); err != nil || len(files) != tt.wantFiles {
+				t.Fatalf("expected %d files, got %d (%v)", tt.wantFiles, len(files), err)
+			}
+			for _, file := range files {
+				if file.Content == "" || file.Error != "" || file.Filename == "" {
+					t.Errorf("invalid file %+v", file)
+				}
+			}
+		})
+	}
+}
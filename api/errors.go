@@ -0,0 +1,49 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/go-chi/render"
+)
+
+// errorResponse error body, following the format used by apierror
+type errorResponse struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+func (e errorResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// errorStatus renders an error with the given status code and the reason why the request was refused
+func errorStatus(w http.ResponseWriter, r *http.Request, status int, err error) {
+	render.Status(r, status)
+	if renderErr := render.Render(w, r, errorResponse{Status: status, Message: err.Error()}); renderErr != nil {
+		http.Error(w, err.Error(), status)
+	}
+}
+
+// badRequest renders a 400 error with the reason why the request was refused
+func badRequest(w http.ResponseWriter, r *http.Request, err error) {
+	errorStatus(w, r, http.StatusBadRequest, err)
+}
+
+// unauthorized renders a 401 error for the requests without valid credentials
+func unauthorized(w http.ResponseWriter, r *http.Request) {
+	render.Status(r, http.StatusUnauthorized)
+	if err := render.Render(w, r, errorResponse{Status: http.StatusUnauthorized, Message: "missing or invalid API token"}); err != nil {
+		http.Error(w, "missing or invalid API token", http.StatusUnauthorized)
+	}
+}
+
+// handleFilterError renders a 404 error for unknown constellations, and a 400 error otherwise
+func handleFilterError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, apierror.ErrNotFound) {
+		apierror.Handle(w, r, err)
+		return
+	}
+	badRequest(w, r, err)
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/data"
+	"github.com/go-chi/render"
+)
+
+// maxOrbitEvents number of detected orbit events kept in memory
+const maxOrbitEvents = 1000
+
+// detectOrbitEvents looks for maneuvers, decay and bad element sets in the updated satellites
+func (s *Server) detectOrbitEvents(changes []SatelliteChange) {
+	var events []data.OrbitEvent
+	for _, change := range changes {
+		if change.Type != ChangeUpdated || change.Previous == nil {
+			continue
+		}
+
+		event, err := data.DetectOrbitEvent(*change.Previous, change.Satellite, s.DetectionThresholds)
+		if err != nil {
+			log.Println(err.Error())
+			continue
+		}
+		if event == nil {
+			continue
+		}
+
+		log.Printf("%s detected for %s (%v): %s\n", event.Type, event.SatelliteName, event.NORADID, event.Description)
+		events = append(events, *event)
+	}
+
+	if len(events) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	s.orbitEvents = append(s.orbitEvents, events...)
+	if len(s.orbitEvents) > maxOrbitEvents {
+		s.orbitEvents = s.orbitEvents[len(s.orbitEvents)-maxOrbitEvents:]
+	}
+	s.mu.Unlock()
+}
+
+// getOrbitEvents returns the detected orbit events, most recent first
+func (s *Server) getOrbitEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseSatelliteFilter(r)
+		if err != nil {
+			handleFilterError(w, r, err)
+			return
+		}
+
+		eventType := r.URL.Query().Get("type")
+
+		var since time.Time
+		if sinceParam := r.URL.Query().Get("since"); sinceParam != "" {
+			if since, err = time.Parse(time.RFC3339, sinceParam); err != nil {
+				badRequest(w, r, fmt.Errorf("invalid since parameter %v, expected RFC3339 format", sinceParam))
+				return
+			}
+		}
+
+		s.mu.RLock()
+		renderList := []render.Renderer{}
+		for i := len(s.orbitEvents) - 1; i >= 0; i-- {
+			event := s.orbitEvents[i]
+			if eventType != "" && string(event.Type) != eventType {
+				continue
+			}
+			if event.Epoch.Before(since) {
+				continue
+			}
+			if !filter.matches(data.Satellite{SatelliteName: event.SatelliteName, NORADID: event.NORADID}) {
+				continue
+			}
+			renderList = append(renderList, event)
+		}
+		s.mu.RUnlock()
+
+		if err := render.RenderList(w, r, renderList); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+)
+
+func TestGetOrbitEvents(t *testing.T) {
+	source := data.NewFileSource("../samples/tle_server_testing.txt")
+	s := NewServer(80, source, time.Duration(30)*time.Second)
+	s.InitializeRoutes()
+
+	sats, err := s.source.GetData()
+	if err != nil {
+		t.Fatalf("data from source %s not working", s.source.GetDataSource())
+	}
+	s.UpdateAllValues(sats)
+
+	// ONEWEB-0012 raised its orbit, LAGEOS 1 evolved nominally
+	updated := make([]data.Satellite, len(sats))
+	copy(updated, sats)
+	for i := range updated {
+		switch updated[i].SatelliteName {
+		case "ONEWEB-0012":
+			updated[i].TLELine1 = strings.Replace(updated[i].TLELine1, "22206.", "22207.", 1)
+			updated[i].TLELine2 = strings.Replace(updated[i].TLELine2, "13.16592117", "13.10592117", 1)
+		case "LAGEOS 1":
+			updated[i].TLELine1 = strings.Replace(updated[i].TLELine1, "22206.", "22207.", 1)
+		}
+	}
+	s.UpdateAllValues(updated)
+
+	tests := []struct {
+		name         string
+		path         string
+		wantRespCode int
+		wantEvents   int
+	}{
+		{
+			name:         "all events",
+			path:         "/events",
+			wantRespCode: http.StatusOK,
+			wantEvents:   1,
+		},
+		{
+			name:         "filtered by constellation",
+			path:         "/events?constellation=starlink",
+			wantRespCode: http.StatusOK,
+			wantEvents:   0,
+		},
+		{
+			name:         "filtered by type",
+			path:         "/events?type=maneuver&norad_id=44057",
+			wantRespCode: http.StatusOK,
+			wantEvents:   1,
+		},
+		{
+			name:         "invalid date",
+			path:         "/events?since=yesterday",
+			wantRespCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d\n", tt.wantRespCode, response.Code)
+			}
+			if tt.wantRespCode != http.StatusOK {
+				return
+			}
+
+			var events []data.OrbitEvent
+			if err := json.Unmarshal(response.Body.Bytes(), &events); err != nil {
+				t.Fatalf("could not parse response: %v", err)
+			}
+			if len(events) != tt.wantEvents {
+				t.Errorf("Expected %d events. Got %s\n", tt.wantEvents, response.Body.String())
+			}
+		})
+	}
+}
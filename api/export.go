@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/geo"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+// maxExportPoints maximum number of propagated points of an export, all satellites included
+const maxExportPoints = 500000
+
+// getExport renders the current positions of the filtered satellites, or their trajectories over a time window when end is set,
+// as GeoJSON, KML or CZML. Satellites which cannot be propagated are skipped, their number being given in the X-Skipped-Satellites header.
+func (s *Server) getExport() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = geo.FormatGeoJSON
+		}
+		contentType, err := geo.ContentType(format)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		filter, err := parseSatelliteFilter(r)
+		if err != nil {
+			handleFilterError(w, r, err)
+			return
+		}
+
+		now := time.Now().UTC().Truncate(time.Second)
+		at, err := parseTime(r, "time", now)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		start, err := parseTime(r, "start", now)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		end, err := parseTime(r, "end", time.Time{})
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		stepSeconds, err := parseFloat(r, "step", defaultTrackStep.Seconds())
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		step := time.Duration(stepSeconds * float64(time.Second))
+
+		propagators, skipped := s.filteredPropagators(filter)
+		if len(propagators) == 0 && skipped == 0 {
+			apierror.Handle(w, r, apierror.Wrap(fmt.Errorf("no satellite found"), apierror.ErrNotFound))
+			return
+		}
+
+		window := !end.IsZero()
+		if window {
+			if step <= 0 || !end.After(start) {
+				badRequest(w, r, fmt.Errorf("invalid window, end must be after start and step must be positive"))
+				return
+			}
+			if count := end.Sub(start) / step; count >= geo.MaxTrackPoints {
+				badRequest(w, r, fmt.Errorf("too many points per satellite (%d), the maximum is %d", count+1, geo.MaxTrackPoints))
+				return
+			}
+			if points := int64(end.Sub(start)/step+1) * int64(len(propagators)); points > maxExportPoints {
+				badRequest(w, r, fmt.Errorf("too many points (%d), the maximum is %d", points, maxExportPoints))
+				return
+			}
+		}
+
+		trajectories := make([]geo.Trajectory, 0, len(propagators))
+		for _, p := range propagators {
+			trajectory, err := exportTrajectory(p, window, at, start, end, step)
+			if err != nil {
+				skipped++
+				continue
+			}
+			trajectories = append(trajectories, trajectory)
+		}
+
+		var body bytes.Buffer
+		if err := geo.Export(&body, format, trajectories); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("X-Skipped-Satellites", strconv.Itoa(skipped))
+		w.WriteHeader(http.StatusOK)
+		w.Write(body.Bytes())
+	}
+}
+
+// exportTrajectory propagates the satellite at the given time, or over the window
+func exportTrajectory(p *sgp4.Propagator, window bool, at, start, end time.Time, step time.Duration) (geo.Trajectory, error) {
+	trajectory := geo.Trajectory{SatelliteName: p.Satellite.SatelliteName, NORADID: p.Satellite.NORADID}
+	if !window {
+		point, err := geo.SubSatellitePoint(p, at)
+		if err != nil {
+			return geo.Trajectory{}, err
+		}
+		trajectory.Points = []geo.TrackPoint{point}
+		return trajectory, nil
+	}
+
+	var err error
+	if trajectory.Points, err = geo.Sample(p, start, end, step); err != nil {
+		return geo.Trajectory{}, err
+	}
+	return trajectory, nil
+}
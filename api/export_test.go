@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Funkit/tle-provider/geo"
+)
+
+func TestGetExport(t *testing.T) {
+	s := newSampleServer(t)
+
+	tests := []struct {
+		name            string
+		target          string
+		wantRespCode    int
+		wantContentType string
+		wantBody        string
+	}{
+		{"all positions", "/export", http.StatusOK, geo.GeoJSONContentType, `"type":"Point"`},
+		{"constellation trajectories", "/export?constellation=oneweb&start=2022-07-25T20:00:00Z&end=2022-07-25T22:00:00Z&format=geojson", http.StatusOK, geo.GeoJSONContentType, `"type":"MultiLineString"`},
+		{"KML", "/export?satellite=LAGEOS%201&format=kml", http.StatusOK, geo.KMLContentType, "<Placemark>"},
+		{"CZML", "/export?norad_id=8820&end=2022-07-26T00:00:00Z&start=2022-07-25T20:00:00Z&step=300&format=czml", http.StatusOK, geo.CZMLContentType, `"referenceFrame":"FIXED"`},
+		{"unknown constellation", "/export?constellation=unknown", http.StatusNotFound, "", ""},
+		{"no satellite", "/export?satellite=UNKNOWN", http.StatusNotFound, "", ""},
+		{"invalid format", "/export?format=shp", http.StatusBadRequest, "", ""},
+		{"invalid window", "/export?start=2022-07-25T20:00:00Z&end=2022-07-25T19:00:00Z", http.StatusBadRequest, "", ""},
+		{"too many points", "/export?start=2022-07-25T20:00:00Z&end=2022-08-25T20:00:00Z&step=10", http.StatusBadRequest, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.target, nil)
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+			if response.Code != http.StatusOK {
+				return
+			}
+			if contentType := response.Header().Get("Content-Type"); contentType != tt.wantContentType {
+				t.Errorf("content type = %v, want %v", contentType, tt.wantContentType)
+			}
+			if !strings.Contains(response.Body.String(), tt.wantBody) {
+				t.Errorf("body does not contain %v: %s", tt.wantBody, response.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/go-chi/render"
+)
+
+// EnableEOP uses the Earth orientation parameters and leap seconds of the source in the frame conversions, the source being
+// refreshed with the satellite data. Without source, polar motion and UT1 are neglected.
+func (s *Server) EnableEOP(source *data.EOPSource) {
+	s.mu.Lock()
+	s.eop = source
+	s.mu.Unlock()
+}
+
+// eopSource returns the EOP source of the server, nil when not enabled
+func (s *Server) eopSource() *data.EOPSource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.eop
+}
+
+// refreshEOP reloads the EOP source, the errors being only logged as the previous data is kept
+func (s *Server) refreshEOP() {
+	source := s.eopSource()
+	if source == nil {
+		return
+	}
+	if err := source.Refresh(); err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// eopTable returns the Earth orientation parameters of the server, nil when not loaded
+func (s *Server) eopTable() *frames.EOPTable {
+	return s.eopSource().EOP()
+}
+
+// eopAt returns the Earth orientation parameters at the given time, zero when unknown
+func (s *Server) eopAt(t time.Time) frames.EOP {
+	eop, _ := s.eopTable().At(t)
+	return eop
+}
+
+// getEOP returns the origin and validity of the Earth orientation parameters and leap seconds
+func (s *Server) getEOP() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := render.Render(w, r, s.eopSource().Status(time.Now().UTC())); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
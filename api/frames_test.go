@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Funkit/tle-provider/data"
+)
+
+func TestGetEOP(t *testing.T) {
+	finalsPath := filepath.Join(t.TempDir(), "finals.all")
+	if err := os.WriteFile(finalsPath, []byte("invalid\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := data.NewEOPSource(data.EOPConfiguration{FinalsFile: finalsPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		source     *data.EOPSource
+		wantSource string
+		wantError  bool
+	}{
+		{"without source", nil, data.EOPSourceNone, false},
+		{"invalid file", source, data.EOPSourceFile, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSampleServer(t)
+			s.EnableEOP(tt.source)
+			s.refreshEOP()
+
+			req, _ := http.NewRequest(http.MethodGet, "/eop", nil)
+			response := executeRequest(req, s)
+			if response.Code != http.StatusOK {
+				t.Fatalf("Expected response code %d. Got %d", http.StatusOK, response.Code)
+			}
+			var status data.EOPStatus
+			if err := json.Unmarshal(response.Body.Bytes(), &status); err != nil {
+				t.Fatalf("invalid body %s", response.Body.String())
+			}
+			if status.Finals.Source != tt.wantSource || status.Finals.Valid || (status.Finals.Error != "") != tt.wantError ||
+				status.LeapSeconds.Source != data.EOPSourceBuiltin || status.LeapSeconds.Latest.TAIMinusUTC != 37 {
+				t.Errorf("unexpected status %s", response.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/data"
+	"github.com/go-chi/render"
+)
+
+// postGenerate builds a TLE from the orbital elements of the body. With inject=true, the TLE is also stored as an override,
+// which requires the overrides to be enabled and an API token.
+func (s *Server) postGenerate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		inject := r.URL.Query().Get("inject")
+		if inject != "" && inject != "true" && inject != "false" {
+			badRequest(w, r, fmt.Errorf("invalid inject %v", inject))
+			return
+		}
+
+		var elements data.KeplerianElements
+		if err := render.DecodeJSON(r.Body, &elements); err != nil {
+			badRequest(w, r, fmt.Errorf("invalid elements: %v", err))
+			return
+		}
+
+		sat, err := data.GenerateTLE(elements)
+		if err != nil {
+			badRequest(w, r, fmt.Errorf("invalid elements: %v", err))
+			return
+		}
+
+		if inject != "true" {
+			if err := render.Render(w, r, sat); err != nil {
+				apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+			}
+			return
+		}
+
+		s.requireOverrides(s.requireToken(s.injectGenerated(sat)))(w, r)
+	}
+}
+
+// injectGenerated stores the generated TLE as an override
+func (s *Server) injectGenerated(sat data.Satellite) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		overrides, err := newOverrides(r, []data.Satellite{sat})
+		if err != nil {
+			badRequest(w, r, fmt.Errorf("invalid override: %v", err))
+			return
+		}
+		if err := s.checkSuperseded(overrides); err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		stored, err := s.storeOverrides(overrides)
+		if err != nil {
+			handleStoreError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		if err := render.Render(w, r, stored[0]); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+)
+
+func TestPostGenerate(t *testing.T) {
+	elements := `{"satellite_name":"PLANNED-1","norad_id":99901,"epoch":"` + time.Now().UTC().Format(time.RFC3339) + `",` +
+		`"apogee_km":550,"perigee_km":540,"inclination":97.5,"raan":120}`
+
+	planned := `{"satellite_name":"PLANNED-2","norad_id":99902,"epoch":"` + time.Now().UTC().AddDate(0, 0, 30).Format(time.RFC3339) + `",` +
+		`"apogee_km":550,"perigee_km":540,"inclination":97.5,"raan":120}`
+
+	tests := []struct {
+		name         string
+		overrides    bool
+		req          *http.Request
+		wantRespCode int
+	}{
+		{"generate", false, overrideRequest(http.MethodPost, "/generate", "", elements), http.StatusOK},
+		{"invalid elements", false, overrideRequest(http.MethodPost, "/generate", "", `{"norad_id":99901}`), http.StatusBadRequest},
+		{"invalid JSON", false, overrideRequest(http.MethodPost, "/generate", "", `{`), http.StatusBadRequest},
+		{"inject without overrides", false, overrideRequest(http.MethodPost, "/generate?inject=true", "token", elements), http.StatusNotFound},
+		{"inject without token", true, overrideRequest(http.MethodPost, "/generate?inject=true", "", elements), http.StatusUnauthorized},
+		{"inject", true, overrideRequest(http.MethodPost, "/generate?inject=true&ttl=72h", "token", elements), http.StatusCreated},
+		{"inject planned launch", true, overrideRequest(http.MethodPost, "/generate?inject=true", "token", planned), http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s *Server
+			if tt.overrides {
+				s = newOverrideServer(t, t.TempDir())
+			} else {
+				s = NewServer(80, data.NewFileSource("../samples/tle_server_testing.txt"), time.Duration(30)*time.Second)
+				s.InitializeRoutes()
+			}
+
+			response := executeRequest(tt.req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+			if response.Code != http.StatusOK {
+				return
+			}
+
+			var sat data.Satellite
+			if err := json.Unmarshal(response.Body.Bytes(), &sat); err != nil {
+				t.Fatalf("invalid response %s", response.Body.String())
+			}
+			if err := data.ValidateSatellite(sat, time.Now()); err != nil {
+				t.Errorf("generated TLE is invalid: %v", err)
+			}
+		})
+	}
+}
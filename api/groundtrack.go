@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/geo"
+	"github.com/Funkit/tle-provider/sgp4"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// defaultTrackStep step of the ground track when not specified
+const defaultTrackStep = time.Minute
+
+// newPropagators initializes the propagators of the satellites, reusing the previous ones for unchanged TLEs.
+// The satellites which cannot be propagated are left out.
+func newPropagators(sats []data.Satellite, previous map[string]*sgp4.Propagator) map[string]*sgp4.Propagator {
+	propagators := make(map[string]*sgp4.Propagator, len(sats))
+	for _, sat := range sats {
+		if p, ok := previous[sat.SatelliteName]; ok && p.Satellite == sat {
+			propagators[sat.SatelliteName] = p
+			continue
+		}
+
+		p, err := sgp4.New(sat)
+		if err != nil {
+			continue
+		}
+		propagators[sat.SatelliteName] = p
+	}
+	return propagators
+}
+
+// propagator returns the propagator of the stored satellite with the given name
+func (s *Server) propagator(name string) (*sgp4.Propagator, error) {
+	s.mu.RLock()
+	sat, ok := s.satellitesTLEsMap[name]
+	p := s.propagators[name]
+	s.mu.RUnlock()
+
+	if !ok || sat.IsNull() {
+		return nil, apierror.Wrap(fmt.Errorf("satellite %v not found", name), apierror.ErrNotFound)
+	}
+	if p == nil {
+		return nil, fmt.Errorf("satellite %v cannot be propagated", name)
+	}
+
+	return p, nil
+}
+
+// parseTime reads an RFC3339 time query parameter, with a default value when absent
+func parseTime(r *http.Request, name string, defaultValue time.Time) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %v parameter %v, expected RFC3339 format", name, value)
+	}
+	return t, nil
+}
+
+// parseFloat reads a float query parameter, with a default value when absent
+func parseFloat(r *http.Request, name string, defaultValue float64) (float64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v parameter %v", name, value)
+	}
+	return f, nil
+}
+
+// parseGeoFormat reads the format query parameter, json or geojson
+func parseGeoFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		return "json", nil
+	case "geojson":
+		return format, nil
+	default:
+		return "", fmt.Errorf("invalid format %v, expected json or geojson", format)
+	}
+}
+
+// renderGeoJSON writes a GeoJSON document with its media type
+func renderGeoJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		return
+	}
+	w.Header().Set("Content-Type", geo.GeoJSONContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// getGroundTrack returns the sub-satellite points of a satellite over a time window, one orbit from now by default
+func (s *Server) getGroundTrack() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format, err := parseGeoFormat(r)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		p, err := s.propagator(chi.URLParam(r, "satellite"))
+		if err != nil {
+			handleFilterError(w, r, err)
+			return
+		}
+
+		start, err := parseTime(r, "start", time.Now().UTC().Truncate(time.Second))
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		end, err := parseTime(r, "end", start.Add(p.Period()))
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		stepSeconds, err := parseFloat(r, "step", defaultTrackStep.Seconds())
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		track, err := geo.NewGroundTrack(p, start, end, time.Duration(stepSeconds*float64(time.Second)))
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		if format == "geojson" {
+			renderGeoJSON(w, r, track.GeoJSON())
+			return
+		}
+		if err := render.Render(w, r, track); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
+
+// getFootprint returns the area seeing a satellite above a minimum elevation, now by default
+func (s *Server) getFootprint() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format, err := parseGeoFormat(r)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		p, err := s.propagator(chi.URLParam(r, "satellite"))
+		if err != nil {
+			handleFilterError(w, r, err)
+			return
+		}
+
+		at, err := parseTime(r, "time", time.Now().UTC().Truncate(time.Second))
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		minElevation, err := parseFloat(r, "min_elevation", 0)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		footprint, err := geo.NewFootprint(p, at, minElevation)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		if format == "geojson" {
+			renderGeoJSON(w, r, footprint.GeoJSON())
+			return
+		}
+		if err := render.Render(w, r, footprint); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/geo"
+)
+
+// newSampleServer serves the satellites of the server testing sample
+func newSampleServer(t *testing.T) *Server {
+	t.Helper()
+	s := NewServer(80, data.NewFileSource("../samples/tle_server_testing.txt"), time.Duration(30)*time.Second)
+	s.InitializeRoutes()
+
+	sats, err := s.source.GetData()
+	if err != nil {
+		t.Fatalf("data from source %s not working", s.source.GetDataSource())
+	}
+	s.UpdateAllValues(sats)
+
+	return s
+}
+
+func TestGetGroundTrack(t *testing.T) {
+	s := newSampleServer(t)
+
+	tests := []struct {
+		name            string
+		target          string
+		wantRespCode    int
+		wantContentType string
+	}{
+		{"default window", "/tle/ONEWEB-0012/groundtrack", http.StatusOK, "application/json"},
+		{"window", "/tle/ONEWEB-0012/groundtrack?start=2022-07-25T20:00:00Z&end=2022-07-25T22:00:00Z&step=30", http.StatusOK, "application/json"},
+		{"geojson", "/tle/ONEWEB-0012/groundtrack?start=2022-07-25T20:00:00Z&format=geojson", http.StatusOK, geo.GeoJSONContentType},
+		{"unknown satellite", "/tle/UNKNOWN/groundtrack", http.StatusNotFound, ""},
+		{"invalid start", "/tle/ONEWEB-0012/groundtrack?start=yesterday", http.StatusBadRequest, ""},
+		{"invalid step", "/tle/ONEWEB-0012/groundtrack?step=-1", http.StatusBadRequest, ""},
+		{"too many points", "/tle/ONEWEB-0012/groundtrack?start=2022-07-25T20:00:00Z&end=2022-08-25T20:00:00Z&step=1", http.StatusBadRequest, ""},
+		{"invalid format", "/tle/ONEWEB-0012/groundtrack?format=kml", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.target, nil)
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+			if response.Code != http.StatusOK {
+				return
+			}
+			if contentType := response.Header().Get("Content-Type"); len(contentType) < len(tt.wantContentType) || contentType[:len(tt.wantContentType)] != tt.wantContentType {
+				t.Errorf("content type = %v, want %v", contentType, tt.wantContentType)
+			}
+
+			if tt.wantContentType == geo.GeoJSONContentType {
+				var feature geo.Feature
+				if err := json.Unmarshal(response.Body.Bytes(), &feature); err != nil || feature.Geometry.Type != "MultiLineString" {
+					t.Errorf("invalid GeoJSON %s", response.Body.String())
+				}
+				return
+			}
+
+			var track geo.GroundTrack
+			if err := json.Unmarshal(response.Body.Bytes(), &track); err != nil || len(track.Segments) == 0 {
+				t.Errorf("invalid ground track %s", response.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetFootprint(t *testing.T) {
+	s := newSampleServer(t)
+
+	tests := []struct {
+		name         string
+		target       string
+		wantRespCode int
+	}{
+		{"now", "/tle/ONEWEB-0012/footprint", http.StatusOK},
+		{"minimum elevation", "/tle/ONEWEB-0012/footprint?time=2022-07-25T20:00:00Z&min_elevation=15", http.StatusOK},
+		{"geojson", "/tle/LAGEOS%201/footprint?format=geojson", http.StatusOK},
+		{"unknown satellite", "/tle/UNKNOWN/footprint", http.StatusNotFound},
+		{"invalid time", "/tle/ONEWEB-0012/footprint?time=now", http.StatusBadRequest},
+		{"invalid elevation", "/tle/ONEWEB-0012/footprint?min_elevation=95", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.target, nil)
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+			if response.Code != http.StatusOK {
+				return
+			}
+
+			var footprint struct {
+				Polygons [][][2]float64 `json:"polygons"`
+				Geometry *geo.Geometry  `json:"geometry"`
+			}
+			if err := json.Unmarshal(response.Body.Bytes(), &footprint); err != nil || (len(footprint.Polygons) == 0 && footprint.Geometry == nil) {
+				t.Errorf("invalid footprint %s", response.Body.String())
+			}
+		})
+	}
+}
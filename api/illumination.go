@@ -0,0 +1,153 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/geo"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+const (
+	// defaultSearchWindow window of the eclipse and pass searches when the end is not specified
+	defaultSearchWindow = 24 * time.Hour
+	// defaultEclipseStep sampling step of the eclipse search when not specified
+	defaultEclipseStep = time.Minute
+	// defaultPassStep sampling step of the pass search when not specified
+	defaultPassStep = 30 * time.Second
+)
+
+// parseSearchWindow reads the start, end and step query parameters of an eclipse or pass search, from now for one day by default
+func parseSearchWindow(r *http.Request, defaultStep time.Duration) (time.Time, time.Time, time.Duration, error) {
+	start, err := parseTime(r, "start", time.Now().UTC().Truncate(time.Second))
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, err
+	}
+	end, err := parseTime(r, "end", start.Add(defaultSearchWindow))
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, err
+	}
+	stepSeconds, err := parseFloat(r, "step", defaultStep.Seconds())
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, err
+	}
+	return start, end, time.Duration(stepSeconds * float64(time.Second)), nil
+}
+
+// getIllumination returns whether a satellite is sunlit or in the shadow of the Earth, now by default
+func (s *Server) getIllumination() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, err := s.propagator(chi.URLParam(r, "satellite"))
+		if err != nil {
+			handleFilterError(w, r, err)
+			return
+		}
+
+		at, err := parseTime(r, "time", time.Now().UTC().Truncate(time.Second))
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		illumination, err := geo.NewIllumination(p, at)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		if err := render.Render(w, r, illumination); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
+
+// getEclipses returns the eclipse entry and exit times of a satellite over a time window
+func (s *Server) getEclipses() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, err := s.propagator(chi.URLParam(r, "satellite"))
+		if err != nil {
+			handleFilterError(w, r, err)
+			return
+		}
+
+		start, end, step, err := parseSearchWindow(r, defaultEclipseStep)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		eclipses, err := geo.NewEclipses(p, start, end, step)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		if err := render.Render(w, r, eclipses); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
+
+// getPasses returns the passes of a satellite above an observer over a time window, and whether they are optically visible
+func (s *Server) getPasses() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		observer, err := parseObserver(r)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		p, err := s.propagator(chi.URLParam(r, "satellite"))
+		if err != nil {
+			handleFilterError(w, r, err)
+			return
+		}
+
+		start, end, step, err := parseSearchWindow(r, defaultPassStep)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		options := geo.PassOptions{Step: step, EOP: s.eopTable()}
+		if options.MinElevation, err = parseFloat(r, "min_elevation", 0); err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		if options.SunElevation, err = parseFloat(r, "sun_elevation", geo.DefaultSunElevation); err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		visibleOnly, err := parseBool(r, "visible")
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		passes, err := geo.NewPasses(r.Context(), p, observer, start, end, options)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		if visibleOnly {
+			passes = visiblePasses(passes)
+		}
+
+		if err := render.Render(w, r, passes); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
+
+// visiblePasses keeps the optically visible passes
+func visiblePasses(passes geo.Passes) geo.Passes {
+	visible := make([]geo.Pass, 0, len(passes.Passes))
+	for _, pass := range passes.Passes {
+		if pass.Visible {
+			visible = append(visible, pass)
+		}
+	}
+	passes.Passes = visible
+	return passes
+}
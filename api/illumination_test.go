@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Funkit/tle-provider/geo"
+)
+
+func TestGetIllumination(t *testing.T) {
+	s := newSampleServer(t)
+
+	tests := []struct {
+		name         string
+		target       string
+		wantRespCode int
+		want         interface{}
+	}{
+		{"illumination", "/tle/ONEWEB-0012/illumination?time=2022-07-26T00:00:00Z", http.StatusOK, &geo.Illumination{}},
+		{"illumination now", "/tle/ONEWEB-0012/illumination", http.StatusOK, &geo.Illumination{}},
+		{"illumination of unknown satellite", "/tle/UNKNOWN/illumination", http.StatusNotFound, nil},
+		{"eclipses", "/tle/ONEWEB-0012/eclipses?start=2022-07-26T00:00:00Z&end=2022-07-27T00:00:00Z", http.StatusOK, &geo.Eclipses{}},
+		{"eclipses with default window", "/tle/LAGEOS%201/eclipses?step=300", http.StatusOK, &geo.Eclipses{}},
+		{"eclipses with invalid step", "/tle/ONEWEB-0012/eclipses?step=zero", http.StatusBadRequest, nil},
+		{"eclipses with too many samples", "/tle/ONEWEB-0012/eclipses?step=0.1", http.StatusBadRequest, nil},
+		{"passes", "/tle/ONEWEB-0012/passes?latitude=48.85&longitude=2.35&start=2022-07-26T00:00:00Z&min_elevation=10", http.StatusOK, &geo.Passes{}},
+		{"visible passes", "/tle/ONEWEB-0012/passes?latitude=48.85&longitude=2.35&start=2022-07-26T00:00:00Z&visible=true&sun_elevation=-12", http.StatusOK, &geo.Passes{}},
+		{"passes without observer", "/tle/ONEWEB-0012/passes", http.StatusBadRequest, nil},
+		{"passes with invalid sun elevation", "/tle/ONEWEB-0012/passes?latitude=48.85&longitude=2.35&sun_elevation=-100", http.StatusBadRequest, nil},
+		{"passes of unknown satellite", "/tle/UNKNOWN/passes?latitude=48.85&longitude=2.35", http.StatusNotFound, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.target, nil)
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+			if tt.want == nil {
+				return
+			}
+			if err := json.Unmarshal(response.Body.Bytes(), tt.want); err != nil {
+				t.Fatalf("invalid body %s", response.Body.String())
+			}
+
+			switch got := tt.want.(type) {
+			case *geo.Illumination:
+				if got.Shadow == "" || got.SunlitFraction < 0 || got.SunlitFraction > 1 {
+					t.Errorf("invalid illumination %+v", got)
+				}
+			case *geo.Eclipses:
+				if got.Eclipses == nil {
+					t.Errorf("invalid eclipses %s", response.Body.String())
+				}
+			case *geo.Passes:
+				if len(got.Passes) == 0 {
+					t.Errorf("expected passes, got %s", response.Body.String())
+				}
+				for _, pass := range got.Passes {
+					if pass.MaxElevation < got.MinElevation || (tt.name == "visible passes" && !pass.Visible) {
+						t.Errorf("invalid pass %+v", pass)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/data"
+	"github.com/go-chi/render"
+)
+
+// defaultIngestHistory number of ingest reports kept when IngestHistory is not set
+const defaultIngestHistory = 20
+
+// IngestReport statistics of a data pull from the source
+type IngestReport struct {
+	Source          string              `json:"source"`
+	StartedAt       time.Time           `json:"started_at"`
+	DurationMs      int64               `json:"duration_ms"`
+	Fetched         int                 `json:"fetched"`
+	Converted       int                 `json:"converted"`
+	Accepted        int                 `json:"accepted"`
+	Rejected        int                 `json:"rejected"`
+	Duplicates      int                 `json:"duplicates"`
+	Error           string              `json:"error,omitempty"`
+	RejectedRecords []data.SatelliteErr `json:"rejected_records"`
+}
+
+func (ir IngestReport) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// pull gets the data from the source, quarantines the invalid records and updates the served values with the valid ones.
+// The statistics of the pull are recorded in the ingest reports.
+func (s *Server) pull() error {
+	report := IngestReport{
+		Source:          s.source.GetDataSource(),
+		StartedAt:       time.Now().UTC(),
+		RejectedRecords: []data.SatelliteErr{},
+	}
+
+	sats, err := s.source.GetData()
+	if errors.Is(err, data.ErrNotModified) {
+		log.Printf("data from %s not modified\n", report.Source)
+		if s.overridesStore() != nil {
+			// Drop the expired overrides
+			s.refreshOverrides()
+		}
+		return nil
+	}
+	if err != nil {
+		report.Error = err.Error()
+		s.recordIngest(report)
+		return err
+	}
+
+	if reporter, ok := s.source.(data.RejectReporter); ok {
+		report.RejectedRecords = append(report.RejectedRecords, reporter.Rejected()...)
+	}
+	report.Converted = len(sats)
+	report.Fetched = len(sats) + len(report.RejectedRecords)
+
+	valid, invalid := data.ValidateAll(sats, time.Now())
+	report.RejectedRecords = append(report.RejectedRecords, invalid...)
+	report.Accepted = len(valid)
+	report.Rejected = len(report.RejectedRecords)
+
+	for _, record := range report.RejectedRecords {
+		if errors.Is(record.Err, data.ErrDuplicate) {
+			report.Duplicates++
+		}
+		log.Printf("record rejected: %s\n", record.Error())
+	}
+
+	s.applyOverrides(valid)
+	s.recordIngest(report)
+
+	return nil
+}
+
+// recordIngest adds the report to the history, dropping the oldest ones
+func (s *Server) recordIngest(report IngestReport) {
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+
+	history := s.IngestHistory
+	if history <= 0 {
+		history = defaultIngestHistory
+	}
+
+	s.mu.Lock()
+	s.ingestReports = append(s.ingestReports, report)
+	if len(s.ingestReports) > history {
+		s.ingestReports = s.ingestReports[len(s.ingestReports)-history:]
+	}
+	if report.Error == "" {
+		s.quarantine = report.RejectedRecords
+	}
+	s.mu.Unlock()
+}
+
+// getIngestReports returns the last ingest reports, most recent first
+func (s *Server) getIngestReports() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := -1
+		if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+			var err error
+			if limit, err = strconv.Atoi(limitParam); err != nil || limit < 0 {
+				badRequest(w, r, fmt.Errorf("invalid limit %v", limitParam))
+				return
+			}
+		}
+
+		s.mu.RLock()
+		renderList := []render.Renderer{}
+		for i := len(s.ingestReports) - 1; i >= 0 && (limit < 0 || len(renderList) < limit); i-- {
+			renderList = append(renderList, s.ingestReports[i])
+		}
+		s.mu.RUnlock()
+
+		if err := render.RenderList(w, r, renderList); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
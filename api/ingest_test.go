@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+)
+
+func TestGetIngestReports(t *testing.T) {
+	source := data.NewFileSource("../samples/tle_validation_testing.txt")
+	s := NewServer(80, source, time.Duration(30)*time.Second)
+	s.InitializeRoutes()
+
+	if err := s.pull(); err != nil {
+		t.Fatalf("data from source %s not working", s.source.GetDataSource())
+	}
+
+	// Second pull from a missing file, failing
+	s.source = data.NewFileSource("../samples/DOESNOTEXIST.txt")
+	if err := s.pull(); err == nil {
+		t.Fatalf("pull from missing file must fail")
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		wantRespCode int
+		want         []IngestReport
+	}{
+		{
+			name:         "all reports",
+			path:         "/ingest",
+			wantRespCode: http.StatusOK,
+			want: []IngestReport{
+				{Source: "file", Error: "file not found"},
+				{Source: "file", Fetched: 4, Converted: 4, Accepted: 2, Rejected: 2, Duplicates: 1},
+			},
+		},
+		{
+			name:         "last report",
+			path:         "/ingest?limit=1",
+			wantRespCode: http.StatusOK,
+			want: []IngestReport{
+				{Source: "file", Error: "file not found"},
+			},
+		},
+		{
+			name:         "invalid limit",
+			path:         "/ingest?limit=-1",
+			wantRespCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d\n", tt.wantRespCode, response.Code)
+			}
+			if tt.wantRespCode != http.StatusOK {
+				return
+			}
+
+			var got []struct {
+				IngestReport
+				RejectedRecords []json.RawMessage `json:"rejected_records"`
+			}
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatalf("could not parse response: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d reports. Got %s\n", len(tt.want), response.Body.String())
+			}
+			for i := range got {
+				if len(got[i].RejectedRecords) != tt.want[i].Rejected {
+					t.Errorf("Expected %d rejected records in report %d. Got %d\n", tt.want[i].Rejected, i, len(got[i].RejectedRecords))
+				}
+				got[i].IngestReport.StartedAt, got[i].IngestReport.DurationMs = time.Time{}, 0
+				if got[i].IngestReport.Source != tt.want[i].Source || (got[i].IngestReport.Error != "") != (tt.want[i].Error != "") ||
+					got[i].IngestReport.Fetched != tt.want[i].Fetched || got[i].IngestReport.Accepted != tt.want[i].Accepted ||
+					got[i].IngestReport.Rejected != tt.want[i].Rejected || got[i].IngestReport.Duplicates != tt.want[i].Duplicates {
+					t.Errorf("Expected report %+v. Got %+v\n", tt.want[i], got[i].IngestReport)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/geo"
+	"github.com/Funkit/tle-provider/jobs"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// JobPasses job type of the pass predictions, with a PassesRequest as parameters
+const JobPasses = "passes"
+
+// JobRequest submission of a job of the given type
+type JobRequest struct {
+	Type   string          `json:"type"`
+	Params json.RawMessage `json:"params"`
+}
+
+// PassesRequest pass prediction of a satellite above an observer. The window starts now and lasts one day by default,
+// and the Sun elevation defaults to the end of the civil twilight.
+type PassesRequest struct {
+	Satellite    string          `json:"satellite"`
+	Observer     frames.Geodetic `json:"observer"`
+	Start        time.Time       `json:"start"`
+	End          time.Time       `json:"end"`
+	StepSeconds  float64         `json:"step_seconds,omitempty"`
+	MinElevation float64         `json:"min_elevation,omitempty"`
+	SunElevation *float64        `json:"sun_elevation,omitempty"`
+	Visible      bool            `json:"visible,omitempty"`
+}
+
+// options pass search options of the request
+func (pr PassesRequest) options() geo.PassOptions {
+	return geo.PassOptions{
+		MinElevation: pr.MinElevation,
+		SunElevation: *pr.SunElevation,
+		Step:         time.Duration(pr.StepSeconds * float64(time.Second)),
+	}
+}
+
+// EnableJobs replaces the in-memory job manager with one using the configuration, before the server runs
+func (s *Server) EnableJobs(config data.JobsConfiguration) error {
+	manager, err := s.newJobManager(config)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	previous := s.jobs
+	s.jobs = manager
+	s.mu.Unlock()
+	previous.Stop()
+	return nil
+}
+
+// newJobManager starts a job manager running the job types of the server
+func (s *Server) newJobManager(config data.JobsConfiguration) (*jobs.Manager, error) {
+	manager, err := jobs.NewManager(config)
+	if err != nil {
+		return nil, err
+	}
+	manager.Register(JobConjunctionScreening, jobs.Type{Prepare: s.prepareScreening, Run: s.runScreening})
+	manager.Register(JobPasses, jobs.Type{Prepare: s.preparePasses, Run: s.runPasses})
+	manager.Register(JobEphemeris, jobs.Type{Prepare: s.prepareEphemeris, Run: s.runEphemeris})
+	manager.Start()
+	return manager, nil
+}
+
+func (s *Server) jobManager() *jobs.Manager {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.jobs
+}
+
+// preparePasses validates the pass prediction request at submission, and sets the default values
+func (s *Server) preparePasses(params json.RawMessage) (interface{}, error) {
+	var request PassesRequest
+	if err := json.Unmarshal(params, &request); err != nil {
+		return nil, fmt.Errorf("invalid passes request: %v", err)
+	}
+	if request.Start.IsZero() {
+		request.Start = time.Now().UTC().Truncate(time.Second)
+	}
+	if request.End.IsZero() {
+		request.End = request.Start.Add(defaultSearchWindow)
+	}
+	if request.StepSeconds == 0 {
+		request.StepSeconds = defaultPassStep.Seconds()
+	}
+	if request.SunElevation == nil {
+		sunElevation := geo.DefaultSunElevation
+		request.SunElevation = &sunElevation
+	}
+
+	if _, err := s.propagator(request.Satellite); err != nil {
+		return nil, err
+	}
+	if err := geo.ValidatePassSearch(request.Observer, request.Start, request.End, request.options()); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// runPasses searches the passes with the TLE of the satellite at the time the job runs
+func (s *Server) runPasses(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var request PassesRequest
+	if err := json.Unmarshal(params, &request); err != nil {
+		return nil, err
+	}
+	p, err := s.propagator(request.Satellite)
+	if err != nil {
+		return nil, err
+	}
+
+	options := request.options()
+	options.EOP = s.eopTable()
+	passes, err := geo.NewPasses(ctx, p, request.Observer, request.Start, request.End, options)
+	if err != nil {
+		return nil, err
+	}
+	if request.Visible {
+		passes = visiblePasses(passes)
+	}
+	return passes, nil
+}
+
+// handleJobError renders the error of a job operation with the matching status code
+func handleJobError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrNotFound))
+	case errors.Is(err, jobs.ErrNotCompleted), errors.Is(err, jobs.ErrCompleted), errors.Is(err, jobs.ErrNoResult):
+		errorStatus(w, r, http.StatusConflict, err)
+	case errors.Is(err, jobs.ErrQueueFull):
+		errorStatus(w, r, http.StatusServiceUnavailable, err)
+	default:
+		handleFilterError(w, r, err)
+	}
+}
+
+// submitJob queues a job, and returns it with its location to poll
+func (s *Server) submitJob(w http.ResponseWriter, r *http.Request, jobType string, params json.RawMessage) {
+	job, err := s.jobManager().Submit(jobType, params)
+	if err != nil {
+		handleJobError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	render.Status(r, http.StatusAccepted)
+	if err := render.Render(w, r, job); err != nil {
+		apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+	}
+}
+
+// postJob submits a job of any registered type
+func (s *Server) postJob() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request JobRequest
+		if err := render.DecodeJSON(r.Body, &request); err != nil {
+			badRequest(w, r, fmt.Errorf("invalid job request: %v", err))
+			return
+		}
+		if len(request.Params) == 0 {
+			request.Params = json.RawMessage("{}")
+		}
+		s.submitJob(w, r, request.Type, request.Params)
+	}
+}
+
+// getJobs lists the jobs by submission time, optionally filtered by type and status
+func (s *Server) getJobs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.renderJobs(w, r, r.URL.Query().Get("type"))
+	}
+}
+
+// renderJobs renders the jobs of the type, or of all types if empty, filtered by the status query parameter
+func (s *Server) renderJobs(w http.ResponseWriter, r *http.Request, jobType string) {
+	status := jobs.Status(r.URL.Query().Get("status"))
+
+	renderList := make([]render.Renderer, 0)
+	for _, job := range s.jobManager().List() {
+		if (jobType != "" && job.Type != jobType) || (status != "" && job.Status != status) {
+			continue
+		}
+		renderList = append(renderList, job)
+	}
+	if err := render.RenderList(w, r, renderList); err != nil {
+		apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+	}
+}
+
+// getJob returns the status of a job
+func (s *Server) getJob() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := s.jobManager().Get(chi.URLParam(r, "id"))
+		if err != nil {
+			handleJobError(w, r, err)
+			return
+		}
+
+		if err := render.Render(w, r, job); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
+
+// getJobResult returns the result of a succeeded job, 409 while it is not completed
+func (s *Server) getJobResult() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		result, err := s.jobManager().Result(chi.URLParam(r, "id"))
+		if err != nil {
+			handleJobError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(result); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
+
+// postJobCancel cancels a queued or running job
+func (s *Server) postJobCancel() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, err := s.jobManager().Cancel(chi.URLParam(r, "id"))
+		if err != nil {
+			handleJobError(w, r, err)
+			return
+		}
+
+		if err := render.Render(w, r, job); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
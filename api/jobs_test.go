@@ -0,0 +1,149 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/geo"
+	"github.com/Funkit/tle-provider/jobs"
+)
+
+// waitJob polls the job until it is completed
+func waitJob(t *testing.T, s *Server, id string) jobs.Job {
+	t.Helper()
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		req, _ := http.NewRequest(http.MethodGet, "/jobs/"+id, nil)
+		response := executeRequest(req, s)
+		if response.Code != http.StatusOK {
+			t.Fatalf("Expected response code %d. Got %d", http.StatusOK, response.Code)
+		}
+
+		var job jobs.Job
+		if err := json.Unmarshal(response.Body.Bytes(), &job); err != nil {
+			t.Fatalf("invalid job %s", response.Body.String())
+		}
+		if job.Status != jobs.StatusQueued && job.Status != jobs.StatusRunning {
+			return job
+		}
+	}
+	t.Fatalf("job %s not completed", id)
+	return jobs.Job{}
+}
+
+// jobResult returns the result of a succeeded job
+func jobResult(t *testing.T, s *Server, id string) []byte {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, "/jobs/"+id+"/result", nil)
+	response := executeRequest(req, s)
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected response code %d. Got %d, body %s", http.StatusOK, response.Code, response.Body.String())
+	}
+	return response.Body.Bytes()
+}
+
+func TestPostJob(t *testing.T) {
+	s := newSampleServer(t)
+	if err := s.EnableJobs(data.JobsConfiguration{Workers: 1, StoreDirectory: t.TempDir()}); err != nil {
+		t.Fatalf("EnableJobs() error = %v", err)
+	}
+	t.Cleanup(s.jobManager().Stop)
+
+	paris := `"observer":{"latitude":48.8566,"longitude":2.3522}`
+	tests := []struct {
+		name         string
+		body         string
+		wantRespCode int
+		wantPasses   bool
+	}{
+		{"passes", `{"type":"passes","params":{"satellite":"ONEWEB-0012",` + paris + `,"start":"2022-07-26T00:00:00Z","end":"2022-07-27T00:00:00Z"}}`, http.StatusAccepted, true},
+		{"visible passes", `{"type":"passes","params":{"satellite":"ONEWEB-0012",` + paris + `,"start":"2022-07-26T00:00:00Z","visible":true,"sun_elevation":-90}}`, http.StatusAccepted, false},
+		{"screening", `{"type":"conjunction_screening","params":{"satellites":["ONEWEB-0012"],"start":"2022-07-26T00:00:00Z","end":"2022-07-26T01:00:00Z"}}`, http.StatusAccepted, false},
+		{"unknown type", `{"type":"unknown"}`, http.StatusBadRequest, false},
+		{"invalid body", `{"type":1}`, http.StatusBadRequest, false},
+		{"invalid observer", `{"type":"passes","params":{"satellite":"ONEWEB-0012","observer":{"latitude":100}}}`, http.StatusBadRequest, false},
+		{"invalid step", `{"type":"passes","params":{"satellite":"ONEWEB-0012",` + paris + `,"step_seconds":-1}}`, http.StatusBadRequest, false},
+		{"unknown satellite", `{"type":"passes","params":{"satellite":"UNKNOWN",` + paris + `}}`, http.StatusNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodPost, "/jobs", strings.NewReader(tt.body))
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+			if response.Code != http.StatusAccepted {
+				return
+			}
+
+			var job jobs.Job
+			if err := json.Unmarshal(response.Body.Bytes(), &job); err != nil || job.ID == "" || response.Header().Get("Location") != "/jobs/"+job.ID {
+				t.Fatalf("invalid job %s", response.Body.String())
+			}
+			if job = waitJob(t, s, job.ID); job.Status != jobs.StatusSucceeded {
+				t.Fatalf("expected a successful job, got %+v", job)
+			}
+
+			if job.Type != JobPasses {
+				return
+			}
+			var passes geo.Passes
+			if err := json.Unmarshal(jobResult(t, s, job.ID), &passes); err != nil {
+				t.Fatalf("invalid result: %v", err)
+			}
+			if tt.wantPasses && (len(passes.Passes) == 0 || passes.SunElevation != geo.DefaultSunElevation) {
+				t.Errorf("expected passes with the default sun elevation, got %+v", passes)
+			}
+			for _, pass := range passes.Passes {
+				if !tt.wantPasses && !pass.Visible {
+					t.Errorf("expected visible passes only, got %+v", pass)
+				}
+			}
+		})
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "/jobs?type=passes&status=succeeded", nil)
+	response := executeRequest(req, s)
+	var list []jobs.Job
+	if err := json.Unmarshal(response.Body.Bytes(), &list); err != nil || len(list) != 2 {
+		t.Errorf("expected 2 passes jobs, got %s", response.Body.String())
+	}
+}
+
+func TestJobErrors(t *testing.T) {
+	s := newSampleServer(t)
+
+	req, _ := http.NewRequest(http.MethodPost, "/jobs", strings.NewReader(`{"type":"passes","params":{"satellite":"ONEWEB-0012","observer":{"latitude":48.8566,"longitude":2.3522},"start":"2022-07-26T00:00:00Z","end":"2022-07-27T00:00:00Z"}}`))
+	response := executeRequest(req, s)
+	var job jobs.Job
+	if err := json.Unmarshal(response.Body.Bytes(), &job); err != nil {
+		t.Fatalf("invalid job %s", response.Body.String())
+	}
+	waitJob(t, s, job.ID)
+
+	tests := []struct {
+		name         string
+		method       string
+		url          string
+		wantRespCode int
+	}{
+		{"unknown job", http.MethodGet, "/jobs/unknown", http.StatusNotFound},
+		{"unknown result", http.MethodGet, "/jobs/unknown/result", http.StatusNotFound},
+		{"cancel unknown", http.MethodPost, "/jobs/unknown/cancel", http.StatusNotFound},
+		{"cancel completed", http.MethodPost, "/jobs/" + job.ID + "/cancel", http.StatusConflict},
+		{"list", http.MethodGet, "/jobs", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.url, nil)
+			if response := executeRequest(req, s); response.Code != tt.wantRespCode {
+				t.Errorf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+		})
+	}
+}
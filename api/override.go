@@ -0,0 +1,403 @@
+package api
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/data"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// formatSatelliteJSON format of the override bodies using the JSON representation of the satellites returned by /tle
+const formatSatelliteJSON = "json"
+
+// maxOverrideBodySize maximum size of the override request bodies
+const maxOverrideBodySize = 10 << 20
+
+// EnableOverrides serves the overrides of the store instead of the source data. The override modifications require one of the API tokens.
+func (s *Server) EnableOverrides(store *data.OverrideStore, apiTokens []string) {
+	s.mu.Lock()
+	s.overrides = store
+	s.mu.Unlock()
+	s.AddAPITokens(apiTokens...)
+}
+
+// AddAPITokens adds bearer tokens accepted by the endpoints modifying the server state: overrides and webhooks
+func (s *Server) AddAPITokens(tokens ...string) {
+	s.mu.Lock()
+	s.apiTokens = append(s.apiTokens, tokens...)
+	s.mu.Unlock()
+}
+
+// applyOverrides stores the validated source data, and updates the served values with the overrides applied
+func (s *Server) applyOverrides(sats []data.Satellite) {
+	s.ingestMu.Lock()
+	defer s.ingestMu.Unlock()
+
+	s.mu.Lock()
+	s.upstream = sats
+	s.mu.Unlock()
+
+	if store := s.overridesStore(); store != nil {
+		var err error
+		if sats, err = store.Apply(sats, time.Now()); err != nil {
+			log.Printf("failed to save the overrides: %v\n", err)
+		}
+	}
+
+	s.UpdateAllValues(sats)
+}
+
+// refreshOverrides updates the served values after an override modification, or the expiry of overrides
+func (s *Server) refreshOverrides() {
+	s.mu.RLock()
+	upstream := s.upstream
+	s.mu.RUnlock()
+
+	s.applyOverrides(upstream)
+}
+
+// authorized returns true if the request carries one of the API tokens as bearer token
+func (s *Server) authorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" || token == r.Header.Get("Authorization") {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, apiToken := range s.apiTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(apiToken)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// overridesStore returns the override store, nil if the overrides are not enabled
+func (s *Server) overridesStore() *data.OverrideStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.overrides
+}
+
+// requireOverrides refuses the requests when the overrides are not enabled
+func (s *Server) requireOverrides(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.overridesStore() == nil {
+			apierror.Handle(w, r, apierror.Wrap(fmt.Errorf("overrides are not enabled"), apierror.ErrNotFound))
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requireToken refuses the requests without a valid API token
+func (s *Server) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="tle-provider"`)
+			unauthorized(w, r)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// parseOverrideBody reads the satellites of the request body, in the format given by the format query parameter, or detected from the content.
+// In addition to the formats supported by the file source, the JSON representation of the satellites returned by /tle is accepted.
+func parseOverrideBody(r *http.Request) ([]data.Satellite, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxOverrideBodySize))
+	if err != nil {
+		return nil, err
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, fmt.Errorf("empty body")
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = data.FormatAuto
+	}
+	if format != formatSatelliteJSON && !data.IsValidFormat(format) {
+		return nil, fmt.Errorf("unsupported format %v", format)
+	}
+	if format == data.FormatAuto {
+		format = data.DetectFormat("", body)
+		if format == data.FormatOMMJSON && isSatelliteJSON(body) {
+			format = formatSatelliteJSON
+		}
+	}
+
+	if format == formatSatelliteJSON {
+		return parseSatelliteJSON(body)
+	}
+
+	sats, rejected, err := data.Parse(format, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if len(rejected) != 0 {
+		return nil, rejected[0]
+	}
+
+	return sats, nil
+}
+
+// isSatelliteJSON returns true if the JSON content holds TLE lines rather than OMM fields
+func isSatelliteJSON(body []byte) bool {
+	return bytes.Contains(body, []byte(`"tle_line_1"`))
+}
+
+// parseSatelliteJSON reads a satellite or a list of satellites in the /tle representation
+func parseSatelliteJSON(body []byte) ([]data.Satellite, error) {
+	var sats []data.Satellite
+	if body[0] == '{' {
+		var sat data.Satellite
+		if err := json.Unmarshal(body, &sat); err != nil {
+			return nil, err
+		}
+		sats = append(sats, sat)
+	} else if err := json.Unmarshal(body, &sats); err != nil {
+		return nil, err
+	}
+
+	for i := range sats {
+		if sats[i].SatelliteName == "" {
+			sats[i].SatelliteName = strconv.Itoa(sats[i].NORADID)
+		}
+	}
+
+	return sats, nil
+}
+
+// newOverrides validates the satellites, and builds their overrides from the expires_at, ttl and reason query parameters
+func newOverrides(r *http.Request, sats []data.Satellite) ([]data.Override, error) {
+	if len(sats) == 0 {
+		return nil, fmt.Errorf("no satellite in the body")
+	}
+
+	now := time.Now().UTC()
+	query := r.URL.Query()
+
+	var expiresAt *time.Time
+	if expiresParam := query.Get("expires_at"); expiresParam != "" {
+		expiry, err := time.Parse(time.RFC3339, expiresParam)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expires_at %v: %w", expiresParam, err)
+		}
+		expiresAt = &expiry
+	}
+	if ttlParam := query.Get("ttl"); ttlParam != "" {
+		if expiresAt != nil {
+			return nil, fmt.Errorf("expires_at and ttl cannot be used together")
+		}
+		ttl, err := time.ParseDuration(ttlParam)
+		if err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid ttl %v", ttlParam)
+		}
+		expiry := now.Add(ttl)
+		expiresAt = &expiry
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, fmt.Errorf("expiry date %s is in the past", expiresAt.Format(time.RFC3339))
+	}
+
+	seen := make(map[int]struct{}, len(sats))
+	overrides := make([]data.Override, 0, len(sats))
+	for _, sat := range sats {
+		if err := data.ValidateOverride(sat, now); err != nil {
+			return nil, data.SatelliteErr{Err: err, Sat: sat}
+		}
+		if _, ok := seen[sat.NORADID]; ok {
+			return nil, data.SatelliteErr{Err: data.ErrDuplicate, Sat: sat}
+		}
+		seen[sat.NORADID] = struct{}{}
+
+		overrides = append(overrides, data.Override{
+			Satellite: sat,
+			CreatedAt: now,
+			ExpiresAt: expiresAt,
+			Reason:    query.Get("reason"),
+		})
+	}
+
+	return overrides, nil
+}
+
+// checkSuperseded returns an error if the source data has a more recent element set than one of the overrides,
+// as the override would be dropped at once
+func (s *Server) checkSuperseded(overrides []data.Override) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	upstream := make(map[int]data.Satellite, len(s.upstream))
+	for _, sat := range s.upstream {
+		upstream[sat.NORADID] = sat
+	}
+	for _, o := range overrides {
+		if sat, ok := upstream[o.Satellite.NORADID]; ok && data.Supersedes(sat, o.Satellite) {
+			return fmt.Errorf("the source element set of satellite %v is more recent than the override", o.Satellite.NORADID)
+		}
+	}
+	return nil
+}
+
+// errOverridesRemoved the overrides were superseded or expired by a concurrent data pull before being stored
+var errOverridesRemoved = errors.New("overrides superseded or expired before being stored")
+
+// storeOverrides saves the overrides and updates the served values, returning the stored overrides. errOverridesRemoved is returned
+// when none of them remains after the update.
+func (s *Server) storeOverrides(overrides []data.Override) ([]data.Override, error) {
+	if err := s.overridesStore().Set(overrides); err != nil {
+		return nil, err
+	}
+	s.refreshOverrides()
+
+	ids := make(map[int]struct{}, len(overrides))
+	for _, o := range overrides {
+		ids[o.Satellite.NORADID] = struct{}{}
+	}
+	var stored []data.Override
+	for _, o := range s.overridesStore().List() {
+		if _, ok := ids[o.Satellite.NORADID]; ok {
+			stored = append(stored, o)
+		}
+	}
+	if len(stored) == 0 {
+		return nil, errOverridesRemoved
+	}
+
+	return stored, nil
+}
+
+// handleStoreError renders the error of storeOverrides with the matching status code
+func handleStoreError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, errOverridesRemoved) {
+		errorStatus(w, r, http.StatusConflict, err)
+		return
+	}
+	apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrInternal))
+}
+
+func (s *Server) getOverrides() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		overrides := s.overridesStore().List()
+
+		renderList := make([]render.Renderer, 0, len(overrides))
+		for _, o := range overrides {
+			renderList = append(renderList, o)
+		}
+		if err := render.RenderList(w, r, renderList); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
+
+func (s *Server) postOverrides() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sats, err := parseOverrideBody(r)
+		if err != nil {
+			badRequest(w, r, fmt.Errorf("invalid overrides: %v", err))
+			return
+		}
+		overrides, err := newOverrides(r, sats)
+		if err != nil {
+			badRequest(w, r, fmt.Errorf("invalid overrides: %v", err))
+			return
+		}
+		if err := s.checkSuperseded(overrides); err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		stored, err := s.storeOverrides(overrides)
+		if err != nil {
+			handleStoreError(w, r, err)
+			return
+		}
+
+		renderList := make([]render.Renderer, 0, len(stored))
+		for _, o := range stored {
+			renderList = append(renderList, o)
+		}
+		render.Status(r, http.StatusCreated)
+		if err := render.RenderList(w, r, renderList); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
+
+func (s *Server) putOverride() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		noradID, err := strconv.Atoi(chi.URLParam(r, "norad_id"))
+		if err != nil {
+			badRequest(w, r, fmt.Errorf("invalid NORAD ID %v", chi.URLParam(r, "norad_id")))
+			return
+		}
+
+		sats, err := parseOverrideBody(r)
+		if err != nil {
+			badRequest(w, r, fmt.Errorf("invalid override: %v", err))
+			return
+		}
+		if len(sats) != 1 || sats[0].NORADID != noradID {
+			badRequest(w, r, fmt.Errorf("the body must contain the element set of satellite %v only", noradID))
+			return
+		}
+		overrides, err := newOverrides(r, sats)
+		if err != nil {
+			badRequest(w, r, fmt.Errorf("invalid override: %v", err))
+			return
+		}
+		if err := s.checkSuperseded(overrides); err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		stored, err := s.storeOverrides(overrides)
+		if err != nil {
+			handleStoreError(w, r, err)
+			return
+		}
+
+		if err := render.Render(w, r, stored[0]); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
+
+func (s *Server) deleteOverride() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		noradID, err := strconv.Atoi(chi.URLParam(r, "norad_id"))
+		if err != nil {
+			badRequest(w, r, fmt.Errorf("invalid NORAD ID %v", chi.URLParam(r, "norad_id")))
+			return
+		}
+
+		deleted, err := s.overridesStore().Delete(noradID)
+		if err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrInternal))
+			return
+		}
+		if !deleted {
+			apierror.Handle(w, r, apierror.Wrap(fmt.Errorf("no override for satellite %v", noradID), apierror.ErrNotFound))
+			return
+		}
+		s.refreshOverrides()
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/go-chi/render"
+)
+
+const (
+	lageosNewerTLE = `LAGEOS 1
+1 08820U 76039A   22210.50000000  .00000028  00000-0  00000-0 0  9995
+2 08820 109.8533  52.0899 0045094 246.5947 308.4924  6.38664901822309`
+	lageosOlderTLE = `LAGEOS 1
+1 08820U 76039A   22200.50000000  .00000028  00000-0  00000-0 0  9994
+2 08820 109.8533  52.0899 0045094 246.5947 308.4924  6.38664901822309`
+	prelaunchJSON = `{"satellite_name":"PRELAUNCH-1","norad_id":99901,` +
+		`"tle_line_1":"1 99901U 22999A   22206.50000000  .00000000  00000-0  00000-0 0  9996",` +
+		`"tle_line_2":"2 99901  97.5000 100.0000 0001000  90.0000 270.0000 15.20000000    10"}`
+)
+
+// newOverrideServer starts a server on the test file source, with overrides stored in the directory
+func newOverrideServer(t *testing.T, dir string) *Server {
+	t.Helper()
+
+	store, err := data.NewOverrideStore(filepath.Join(dir, "overrides.json"))
+	if err != nil {
+		t.Fatalf("NewOverrideStore() error = %v", err)
+	}
+
+	s := NewServer(80, data.NewFileSource("../samples/tle_server_testing.txt"), time.Duration(30)*time.Second)
+	s.AddMiddlewares(render.SetContentType(render.ContentTypeJSON))
+	s.InitializeRoutes()
+	s.EnableOverrides(store, []string{"token"})
+	if err := s.pull(); err != nil {
+		t.Fatalf("pull() error = %v", err)
+	}
+
+	return s
+}
+
+func overrideRequest(method, target, token, body string) *http.Request {
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestOverrides(t *testing.T) {
+	dir := t.TempDir()
+	s := newOverrideServer(t, dir)
+
+	tests := []struct {
+		name         string
+		req          *http.Request
+		wantRespCode int
+	}{
+		{"missing token", overrideRequest(http.MethodPost, "/overrides", "", lageosNewerTLE), http.StatusUnauthorized},
+		{"invalid token", overrideRequest(http.MethodPost, "/overrides", "other", lageosNewerTLE), http.StatusUnauthorized},
+		{"3LE text", overrideRequest(http.MethodPost, "/overrides?reason=correction", "token", lageosNewerTLE), http.StatusCreated},
+		{"older than the source", overrideRequest(http.MethodPost, "/overrides", "token", lageosOlderTLE), http.StatusBadRequest},
+		{"JSON", overrideRequest(http.MethodPut, "/overrides/99901?ttl=24h", "token", prelaunchJSON), http.StatusOK},
+		{"NORAD ID mismatch", overrideRequest(http.MethodPut, "/overrides/8820", "token", prelaunchJSON), http.StatusBadRequest},
+		{"invalid checksum", overrideRequest(http.MethodPost, "/overrides", "token", strings.Replace(lageosNewerTLE, "9995", "9990", 1)), http.StatusBadRequest},
+		{"expiry in the past", overrideRequest(http.MethodPost, "/overrides?expires_at=2020-01-01T00:00:00Z", "token", lageosNewerTLE), http.StatusBadRequest},
+		{"unknown format", overrideRequest(http.MethodPost, "/overrides?format=sp3", "token", lageosNewerTLE), http.StatusBadRequest},
+		{"list", overrideRequest(http.MethodGet, "/overrides", "", ""), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := executeRequest(tt.req, s)
+			if response.Code != tt.wantRespCode {
+				t.Errorf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+		})
+	}
+
+	lageos := executeRequest(overrideRequest(http.MethodGet, "/tle/LAGEOS%201", "", ""), s)
+	var sat data.Satellite
+	if err := json.Unmarshal(lageos.Body.Bytes(), &sat); err != nil {
+		t.Fatalf("invalid /tle response %s", lageos.Body.String())
+	}
+	if !strings.Contains(sat.TLELine1, "22210.50000000") || sat.Origin != data.OriginOverride {
+		t.Errorf("LAGEOS 1 not overridden: %v", sat)
+	}
+	if response := executeRequest(overrideRequest(http.MethodGet, "/tle/PRELAUNCH-1", "", ""), s); response.Code != http.StatusOK {
+		t.Errorf("PRELAUNCH-1 not served: %d", response.Code)
+	}
+
+	// The overrides are kept after a restart
+	restarted := newOverrideServer(t, dir)
+	var overrides []data.Override
+	if err := json.Unmarshal(executeRequest(overrideRequest(http.MethodGet, "/overrides", "", ""), restarted).Body.Bytes(), &overrides); err != nil {
+		t.Fatalf("invalid /overrides response: %v", err)
+	}
+	if len(overrides) != 2 || overrides[0].Reason != "correction" || overrides[1].ExpiresAt == nil {
+		t.Errorf("GET /overrides = %+v", overrides)
+	}
+
+	tests = []struct {
+		name         string
+		req          *http.Request
+		wantRespCode int
+	}{
+		{"delete", overrideRequest(http.MethodDelete, "/overrides/99901", "token", ""), http.StatusNoContent},
+		{"delete unknown override", overrideRequest(http.MethodDelete, "/overrides/99901", "token", ""), http.StatusNotFound},
+		{"deleted satellite", overrideRequest(http.MethodGet, "/tle/PRELAUNCH-1", "", ""), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := executeRequest(tt.req, restarted)
+			if response.Code != tt.wantRespCode {
+				t.Errorf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+		})
+	}
+}
+
+// futureTLE generates the element set of a planned satellite, with an epoch after the given duration
+func futureTLE(t *testing.T, noradID int, after time.Duration) string {
+	t.Helper()
+	sat, err := data.GenerateTLE(data.KeplerianElements{SatelliteName: "PLANNED", NORADID: noradID, Epoch: time.Now().UTC().Add(after),
+		ApogeeKm: 550, PerigeeKm: 540, Inclination: 97.5})
+	if err != nil {
+		t.Fatalf("GenerateTLE() error = %v", err)
+	}
+	return sat.SatelliteName + "\n" + sat.TLELine1 + "\n" + sat.TLELine2
+}
+
+func TestOverridesFutureEpoch(t *testing.T) {
+	s := newOverrideServer(t, t.TempDir())
+
+	tests := []struct {
+		name         string
+		req          *http.Request
+		wantRespCode int
+	}{
+		{"planned launch", overrideRequest(http.MethodPut, "/overrides/99902", "token", futureTLE(t, 99902, 30*24*time.Hour)), http.StatusOK},
+		{"predicted epoch", overrideRequest(http.MethodPost, "/overrides", "token", futureTLE(t, 99903, 10*24*time.Hour)), http.StatusCreated},
+		{"too far in the future", overrideRequest(http.MethodPost, "/overrides", "token", futureTLE(t, 99904, 3*365*24*time.Hour)), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := executeRequest(tt.req, s)
+			if response.Code != tt.wantRespCode {
+				t.Errorf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+		})
+	}
+}
+
+func TestOverridesDisabled(t *testing.T) {
+	s := NewServer(80, data.NewFileSource("../samples/tle_server_testing.txt"), time.Duration(30)*time.Second)
+	s.InitializeRoutes()
+
+	response := executeRequest(overrideRequest(http.MethodGet, "/overrides", "", ""), s)
+	if response.Code != http.StatusNotFound {
+		t.Errorf("Expected response code %d. Got %d", http.StatusNotFound, response.Code)
+	}
+}
+
+func TestStoreOverridesRemoved(t *testing.T) {
+	s := newOverrideServer(t, t.TempDir())
+
+	var sat data.Satellite
+	if err := json.Unmarshal([]byte(prelaunchJSON), &sat); err != nil {
+		t.Fatal(err)
+	}
+	// expired between the request validation and the storage
+	expired := time.Now().UTC().Add(-time.Minute)
+	_, err := s.storeOverrides([]data.Override{{Satellite: sat, CreatedAt: time.Now().UTC(), ExpiresAt: &expired}})
+	if !errors.Is(err, errOverridesRemoved) {
+		t.Fatalf("storeOverrides() error = %v, want %v", err, errOverridesRemoved)
+	}
+
+	response := httptest.NewRecorder()
+	handleStoreError(response, httptest.NewRequest(http.MethodPut, "/overrides/99901", nil), err)
+	if response.Code != http.StatusConflict {
+		t.Errorf("handleStoreError() code = %d, want %d", response.Code, http.StatusConflict)
+	}
+}
@@ -10,6 +10,8 @@ import (
 
 	"github.com/Funkit/go-utils/apierror"
 	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/jobs"
+	"github.com/Funkit/tle-provider/sgp4"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 )
@@ -21,6 +23,22 @@ var (
 	}
 )
 
+// ChangeType nature of the change detected on a satellite between two data pulls
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeUpdated ChangeType = "updated"
+	ChangeRemoved ChangeType = "removed"
+)
+
+// SatelliteChange change detected on a satellite between two data pulls
+type SatelliteChange struct {
+	Type      ChangeType      `json:"type"`
+	Satellite data.Satellite  `json:"satellite"`
+	Previous  *data.Satellite `json:"previous,omitempty"`
+}
+
 type Server struct {
 	source                 data.Source
 	router                 chi.Router
@@ -28,24 +46,49 @@ type Server struct {
 	DataRefreshRate        time.Duration
 	CelestrakRefreshRate   time.Duration
 	FileRefreshRateSeconds time.Duration
+	DetectionThresholds    data.DetectionThresholds
+	IngestHistory          int
 	mu                     sync.RWMutex
 	satellitesTLEs         []data.Satellite
 	satellitesTLEsMap      map[string]data.Satellite
 	constellationsTLEs     map[string][]data.Satellite
+	propagators            map[string]*sgp4.Propagator
 	lastPull               time.Time
+	updateHooks            []func([]SatelliteChange)
+	orbitEvents            []data.OrbitEvent
+	quarantine             []data.SatelliteErr
+	ingestReports          []IngestReport
+	stream                 *broker
+	webhooks               *webhookDispatcher
+	jobs                   *jobs.Manager
+	eop                    *data.EOPSource
+	overrides              *data.OverrideStore
+	apiTokens              []string
+	upstream               []data.Satellite
+	ingestMu               sync.Mutex
 	done                   chan struct{}
 }
 
 func NewServer(port int, source data.Source, refreshRate time.Duration) *Server {
 	done := make(chan struct{})
-	return &Server{
+	s := &Server{
 		source:          source,
 		router:          chi.NewRouter(),
 		Port:            port,
 		DataRefreshRate: refreshRate,
+		stream:          newBroker(),
+		webhooks:        newWebhookDispatcher(),
 		done:            done,
 		lastPull:        time.Date(1970, 01, 01, 0, 0, 0, 1, time.UTC),
 	}
+	s.AddUpdateHook(s.stream.publish)
+	s.AddUpdateHook(s.webhooks.publish)
+	s.AddUpdateHook(s.detectOrbitEvents)
+
+	// without store directory, the manager cannot fail to load
+	s.jobs, _ = s.newJobManager(data.JobsConfiguration{})
+
+	return s
 }
 
 func (s *Server) AddMiddlewares(middlewares ...func(handler http.Handler) http.Handler) {
@@ -56,28 +99,55 @@ func (s *Server) SubRoutes(baseURL string, r chi.Router) {
 	s.router.Mount(baseURL, r)
 }
 
+// AddUpdateHook registers a function called with the detected changes each time new data is ingested.
+// Hooks are called sequentially outside the server lock, and must not block.
+func (s *Server) AddUpdateHook(hook func(changes []SatelliteChange)) {
+	s.mu.Lock()
+	s.updateHooks = append(s.updateHooks, hook)
+	s.mu.Unlock()
+}
+
+// update pulls the data from the source at each refresh period, and when the source signals a change
 func (s *Server) update() {
+	var changes <-chan struct{}
+	if notifier, ok := s.source.(data.Notifier); ok {
+		changes = notifier.Changes()
+	}
+
 	for {
+		var refresh <-chan time.Time
+		if s.DataRefreshRate >= time.Second {
+			refresh = time.After(s.DataRefreshRate)
+		}
+
 		select {
 		case <-s.done:
 			log.Println("END")
-			break
-		case <-time.After(s.DataRefreshRate):
-			sats, err := s.source.GetData()
-			if err != nil {
-				log.Println(err.Error())
-			} else {
-				s.UpdateAllValues(sats)
-			}
+			return
+		case <-refresh:
+		case <-changes:
+			log.Printf("change detected on %s source\n", s.source.GetDataSource())
 		}
+
+		if err := s.pull(); err != nil {
+			log.Println(err.Error())
+		}
+		s.refreshEOP()
 	}
 }
 
 func (s *Server) UpdateAllValues(sats []data.Satellite) {
+	s.mu.RLock()
+	previous := s.propagators
+	s.mu.RUnlock()
+	propagators := newPropagators(sats, previous)
+
 	s.mu.Lock()
+	changes := computeChanges(s.satellitesTLEs, sats)
 	s.satellitesTLEs = sats
 	s.satellitesTLEsMap = make(map[string]data.Satellite)
 	s.constellationsTLEs = make(map[string][]data.Satellite)
+	s.propagators = propagators
 
 	for _, element := range sats {
 		s.satellitesTLEsMap[element.SatelliteName] = element
@@ -91,19 +161,58 @@ func (s *Server) UpdateAllValues(sats []data.Satellite) {
 
 	s.lastPull = time.Now()
 	log.Printf("data successfully pulled from %s at %s\n", s.source.GetDataSource(), time.Now().Format("2006-01-02T15:04:05Z"))
+	hooks := s.updateHooks
 	s.mu.Unlock()
+
+	if len(changes) == 0 {
+		return
+	}
+	for _, hook := range hooks {
+		hook(changes)
+	}
+}
+
+// computeChanges lists the satellites added, updated or removed between two data pulls, using the NORAD ID as key
+func computeChanges(previous, current []data.Satellite) []SatelliteChange {
+	previousMap := make(map[int]data.Satellite, len(previous))
+	for _, sat := range previous {
+		previousMap[sat.NORADID] = sat
+	}
+
+	var changes []SatelliteChange
+	currentIDs := make(map[int]struct{}, len(current))
+	for _, sat := range current {
+		currentIDs[sat.NORADID] = struct{}{}
+
+		old, ok := previousMap[sat.NORADID]
+		if !ok {
+			changes = append(changes, SatelliteChange{Type: ChangeAdded, Satellite: sat})
+			continue
+		}
+		if old.TLELine1 != sat.TLELine1 || old.TLELine2 != sat.TLELine2 {
+			prev := old
+			changes = append(changes, SatelliteChange{Type: ChangeUpdated, Satellite: sat, Previous: &prev})
+		}
+	}
+
+	for _, sat := range previous {
+		if _, ok := currentIDs[sat.NORADID]; !ok {
+			changes = append(changes, SatelliteChange{Type: ChangeRemoved, Satellite: sat})
+		}
+	}
+
+	return changes
 }
 
 func (s *Server) Run() error {
 
-	sats, err := s.source.GetData()
-	if err != nil {
+	s.refreshEOP()
+	if err := s.pull(); err != nil {
 		return err
-	} else {
-		s.UpdateAllValues(sats)
 	}
 
-	if s.DataRefreshRate >= time.Second {
+	notifier, watched := s.source.(data.Notifier)
+	if s.DataRefreshRate >= time.Second || (watched && notifier.Changes() != nil) {
 		go s.update()
 	}
 
@@ -111,16 +220,48 @@ func (s *Server) Run() error {
 
 	if err := http.ListenAndServe(fmt.Sprintf(":%v", s.Port), s.router); err != nil {
 		s.done <- struct{}{}
+		s.jobManager().Stop()
 		panic(err)
 	}
 
 	s.done <- struct{}{}
+	s.jobManager().Stop()
 	return nil
 }
 
 func (s *Server) InitializeRoutes() {
 	s.router.Get("/tle", s.getTLEList())
 	s.router.Get("/tle/{satellite}", s.getTLE())
+	s.router.Get("/tle/{satellite}/groundtrack", s.getGroundTrack())
+	s.router.Get("/tle/{satellite}/footprint", s.getFootprint())
+	s.router.Get("/tle/{satellite}/illumination", s.getIllumination())
+	s.router.Get("/tle/{satellite}/eclipses", s.getEclipses())
+	s.router.Get("/tle/{satellite}/passes", s.getPasses())
+	s.router.Get("/tle/{satellite}/ephemeris", s.getEphemeris())
+	s.router.Get("/export", s.getExport())
+	s.router.Get("/snapshot", s.getSnapshot())
+	s.router.Get("/visibility", s.getVisibility())
+	s.router.Get("/eop", s.getEOP())
+	s.router.Get("/conjunctions", s.getScreenings())
+	s.router.Post("/conjunctions", s.postScreening())
+	s.router.Get("/conjunctions/{id}", s.getScreening())
+	s.router.Get("/jobs", s.getJobs())
+	s.router.Post("/jobs", s.postJob())
+	s.router.Get("/jobs/{id}", s.getJob())
+	s.router.Get("/jobs/{id}/result", s.getJobResult())
+	s.router.Post("/jobs/{id}/cancel", s.postJobCancel())
+	s.router.Get("/stream", s.getStream())
+	s.router.Get("/events", s.getOrbitEvents())
+	s.router.Get("/quarantine", s.getQuarantine())
+	s.router.Get("/ingest", s.getIngestReports())
+	s.router.Get("/webhooks", s.requireToken(s.getWebhooks()))
+	s.router.Post("/webhooks", s.requireToken(s.postWebhook()))
+	s.router.Delete("/webhooks/{id}", s.requireToken(s.deleteWebhook()))
+	s.router.Get("/overrides", s.requireOverrides(s.getOverrides()))
+	s.router.Post("/overrides", s.requireOverrides(s.requireToken(s.postOverrides())))
+	s.router.Put("/overrides/{norad_id}", s.requireOverrides(s.requireToken(s.putOverride())))
+	s.router.Delete("/overrides/{norad_id}", s.requireOverrides(s.requireToken(s.deleteOverride())))
+	s.router.Post("/generate", s.postGenerate())
 }
 
 func (s *Server) getTLEList() http.HandlerFunc {
@@ -176,3 +317,19 @@ func (s *Server) getTLE() http.HandlerFunc {
 		}
 	}
 }
+
+// getQuarantine returns the records rejected during the last data pull, with the reason of the rejection
+func (s *Server) getQuarantine() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+
+		renderList := make([]render.Renderer, 0, len(s.quarantine))
+		for _, record := range s.quarantine {
+			renderList = append(renderList, record)
+		}
+		if err := render.RenderList(w, r, renderList); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
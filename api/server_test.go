@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -170,3 +171,37 @@ func TestGetTLEList(t *testing.T) {
 		})
 	}
 }
+
+func TestGetQuarantine(t *testing.T) {
+	source := data.NewFileSource(
+		"../samples/tle_validation_testing.txt")
+
+	s := NewServer(80, source, time.Duration(30)*time.Second)
+	s.AddMiddlewares(middleware.Logger, render.SetContentType(render.ContentTypeJSON), middleware.Recoverer)
+	s.InitializeRoutes()
+
+	if err := s.pull(); err != nil {
+		t.Fatalf("data from source %s not working", s.source.GetDataSource())
+	}
+
+	req, _ := http.NewRequest("GET", "/tle", nil)
+	response := executeRequest(req, s)
+	var sats []data.Satellite
+	if err := json.Unmarshal(response.Body.Bytes(), &sats); err != nil || len(sats) != 2 {
+		t.Errorf("Expected 2 valid satellites. Got %s\n", response.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/quarantine", nil)
+	response = executeRequest(req, s)
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, response.Code)
+	}
+
+	wantBody := "[" +
+		"{\"satellite\":{\"satellite_name\":\"CALSPHERE 1 OLD\",\"norad_id\":900,\"tle_line_1\":\"1 00900U 64063C   22205.83199285  .00000371  00000-0  38562-3 0  9992\",\"tle_line_2\":\"2 00900  90.1732  41.6116 0024844 266.8448 104.5887 13.73849434875933\"},\"reason\":\"duplicate NORAD ID 900, a more recent element set is available\"}," +
+		"{\"satellite\":{\"satellite_name\":\"LAGEOS 1\",\"norad_id\":8820,\"tle_line_1\":\"1 08820U 76039A   22206.68532073  .00000028  00000-0  00000-0 0  9999\",\"tle_line_2\":\"2 08820 109.8533  52.0899 0045094 246.5947 308.4924  6.38664901822298\"},\"reason\":\"TLE line 2 has wrong checksum, expected 7, got 8\"}" +
+		"]\n"
+	if response.Body.String() != wantBody {
+		t.Errorf("Expected response body %s. Got %s\n", wantBody, response.Body.String())
+	}
+}
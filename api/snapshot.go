@@ -0,0 +1,92 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/geo"
+	"github.com/Funkit/tle-provider/sgp4"
+	"github.com/go-chi/render"
+)
+
+// filteredPropagators returns the propagators of the stored satellites matching the filter, in the order of the catalog,
+// and the number of matching satellites which cannot be propagated
+func (s *Server) filteredPropagators(filter satelliteFilter) ([]*sgp4.Propagator, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	propagators := make([]*sgp4.Propagator, 0, len(s.satellitesTLEs))
+	missing := 0
+	for _, sat := range s.satellitesTLEs {
+		if !filter.matches(sat) {
+			continue
+		}
+		p, ok := s.propagators[sat.SatelliteName]
+		if !ok || p.Satellite != sat {
+			missing++
+			continue
+		}
+		propagators = append(propagators, p)
+	}
+	return propagators, missing
+}
+
+// parseBool reads a boolean query parameter, false when absent
+func parseBool(r *http.Request, name string) (bool, error) {
+	switch value := r.URL.Query().Get(name); value {
+	case "", "false":
+		return false, nil
+	case "true":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid %v parameter %v, expected true or false", name, value)
+	}
+}
+
+// getSnapshot propagates the filtered satellites to the same time, now by default, and returns their positions as flat arrays
+func (s *Server) getSnapshot() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseSatelliteFilter(r)
+		if err != nil {
+			handleFilterError(w, r, err)
+			return
+		}
+
+		at, err := parseTime(r, "time", time.Now().UTC())
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		options := geo.SnapshotOptions{Frame: r.URL.Query().Get("frame"), EOP: s.eopAt(at)}
+		if options.Frame == "" {
+			options.Frame = geo.FrameTEME
+		}
+		if options.Velocity, err = parseBool(r, "velocity"); err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		if options.Names, err = parseBool(r, "names"); err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		propagators, missing := s.filteredPropagators(filter)
+		if len(propagators) == 0 && missing == 0 {
+			apierror.Handle(w, r, apierror.Wrap(fmt.Errorf("no satellite found"), apierror.ErrNotFound))
+			return
+		}
+
+		snapshot, err := geo.NewSnapshot(propagators, at, options)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		if err := render.Render(w, r, snapshot); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
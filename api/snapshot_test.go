@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/geo"
+)
+
+func TestGetSnapshot(t *testing.T) {
+	s := newSampleServer(t)
+
+	tests := []struct {
+		name          string
+		target        string
+		wantRespCode  int
+		wantPositions int
+	}{
+		{"all satellites", "/snapshot?time=2022-07-26T00:00:00Z", http.StatusOK, 8},
+		{"constellation", "/snapshot?constellation=oneweb&frame=geodetic&names=true", http.StatusOK, 3},
+		{"velocities", "/snapshot?norad_id=8820&norad_id=900&frame=ecef&velocity=true", http.StatusOK, 2},
+		{"unknown constellation", "/snapshot?constellation=unknown", http.StatusNotFound, 0},
+		{"no satellite", "/snapshot?satellite=UNKNOWN", http.StatusNotFound, 0},
+		{"J2000", "/snapshot?constellation=oneweb&frame=j2000&velocity=true", http.StatusOK, 3},
+		{"invalid frame", "/snapshot?frame=icrf", http.StatusBadRequest, 0},
+		{"geodetic velocities", "/snapshot?frame=geodetic&velocity=true", http.StatusBadRequest, 0},
+		{"invalid velocity", "/snapshot?velocity=yes", http.StatusBadRequest, 0},
+		{"invalid time", "/snapshot?time=now", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.target, nil)
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+			if response.Code != http.StatusOK {
+				return
+			}
+
+			var snapshot geo.Snapshot
+			if err := json.Unmarshal(response.Body.Bytes(), &snapshot); err != nil {
+				t.Fatalf("invalid snapshot %s", response.Body.String())
+			}
+			if len(snapshot.NORADIDs)+len(snapshot.Failed) != tt.wantPositions || len(snapshot.Positions) != 3*len(snapshot.NORADIDs) {
+				t.Errorf("expected %d satellites, got %s", tt.wantPositions, response.Body.String())
+			}
+		})
+	}
+}
+
+func BenchmarkGetSnapshot(b *testing.B) {
+	s := NewServer(80, data.NewFileSource("../samples/active_satellites_tle.txt"), time.Duration(30)*time.Second)
+	s.InitializeRoutes()
+	sats, err := s.source.GetData()
+	if err != nil {
+		b.Fatalf("data from source %s not working", s.source.GetDataSource())
+	}
+	s.UpdateAllValues(sats)
+
+	benchmarks := []struct {
+		name   string
+		target string
+	}{
+		{"full catalog", "/snapshot?time=2022-07-27T00:00:00Z"},
+		{"full catalog geodetic with names", "/snapshot?time=2022-07-27T00:00:00Z&frame=geodetic&names=true"},
+		{"starlink", "/snapshot?time=2022-07-27T00:00:00Z&constellation=starlink"},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			req, _ := http.NewRequest(http.MethodGet, bm.target, nil)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if response := executeRequest(req, s); response.Code != http.StatusOK {
+					b.Fatalf("Expected response code %d. Got %d", http.StatusOK, response.Code)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/data"
+)
+
+const (
+	// streamBufferSize number of changes kept for a subscriber before dropping new ones
+	streamBufferSize = 1024
+	// streamHeartbeat period at which a comment is sent to keep idle connections open
+	streamHeartbeat = 30 * time.Second
+)
+
+// satelliteFilter selects the satellites a stream subscriber or a webhook is interested in. An empty filter matches all satellites.
+type satelliteFilter struct {
+	names          map[string]struct{}
+	noradIDs       map[int]struct{}
+	constellations []string
+}
+
+func (f satelliteFilter) isEmpty() bool {
+	return len(f.names) == 0 && len(f.noradIDs) == 0 && len(f.constellations) == 0
+}
+
+func (f satelliteFilter) matches(sat data.Satellite) bool {
+	if f.isEmpty() {
+		return true
+	}
+	if _, ok := f.names[sat.SatelliteName]; ok {
+		return true
+	}
+	if _, ok := f.noradIDs[sat.NORADID]; ok {
+		return true
+	}
+	for _, constName := range f.constellations {
+		if Constellations[constName].MatchString(sat.SatelliteName) {
+			return true
+		}
+	}
+	return false
+}
+
+// newSatelliteFilter builds a filter from satellite names, NORAD IDs and constellation names
+func newSatelliteFilter(names []string, noradIDs []int, constellations []string) (satelliteFilter, error) {
+	filter := satelliteFilter{
+		names:    make(map[string]struct{}),
+		noradIDs: make(map[int]struct{}),
+	}
+
+	for _, name := range names {
+		if name != "" {
+			filter.names[name] = struct{}{}
+		}
+	}
+
+	for _, id := range noradIDs {
+		filter.noradIDs[id] = struct{}{}
+	}
+
+	for _, constName := range constellations {
+		if _, ok := Constellations[constName]; !ok {
+			return satelliteFilter{}, apierror.Wrap(fmt.Errorf("constellation %v not found", constName), apierror.ErrNotFound)
+		}
+		filter.constellations = append(filter.constellations, constName)
+	}
+
+	return filter, nil
+}
+
+// parseSatelliteFilter builds the filter from the satellite, norad_id and constellation query parameters
+func parseSatelliteFilter(r *http.Request) (satelliteFilter, error) {
+	query := r.URL.Query()
+
+	var noradIDs []int
+	for _, idString := range query["norad_id"] {
+		id, err := strconv.Atoi(idString)
+		if err != nil {
+			return satelliteFilter{}, fmt.Errorf("invalid norad_id %v", idString)
+		}
+		noradIDs = append(noradIDs, id)
+	}
+
+	return newSatelliteFilter(query["satellite"], noradIDs, query["constellation"])
+}
+
+type subscriber struct {
+	filter  satelliteFilter
+	changes chan SatelliteChange
+}
+
+// broker dispatches the changes detected by the server to the stream subscribers
+type broker struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+func newBroker() *broker {
+	return &broker{
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+func (b *broker) subscribe(filter satelliteFilter) *subscriber {
+	sub := &subscriber{
+		filter:  filter,
+		changes: make(chan SatelliteChange, streamBufferSize),
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub
+}
+
+func (b *broker) unsubscribe(sub *subscriber) {
+	b.mu.Lock()
+	delete(b.subscribers, sub)
+	b.mu.Unlock()
+}
+
+// publish sends the changes to the matching subscribers. Changes are dropped for subscribers too slow to consume them,
+// a single summary being logged once the subscribers are released.
+func (b *broker) publish(changes []SatelliteChange) {
+	if dropped, slow := b.dispatch(changes); dropped > 0 {
+		log.Printf("stream: dropped %v changes for %v subscribers too slow to consume them\n", dropped, slow)
+	}
+}
+
+// dispatch sends the changes to the matching subscribers, returning the number of dropped changes and of subscribers which missed some
+func (b *broker) dispatch(changes []SatelliteChange) (int, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	dropped, slow := 0, 0
+	for sub := range b.subscribers {
+		subDropped := 0
+		for _, change := range changes {
+			if !sub.filter.matches(change.Satellite) {
+				continue
+			}
+			select {
+			case sub.changes <- change:
+			default:
+				subDropped++
+			}
+		}
+		if subDropped > 0 {
+			dropped += subDropped
+			slow++
+		}
+	}
+	return dropped, slow
+}
+
+// getStream streams the TLE changes as Server-Sent Events, one event per satellite change
+func (s *Server) getStream() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseSatelliteFilter(r)
+		if err != nil {
+			handleFilterError(w, r, err)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			apierror.Handle(w, r, apierror.Wrap(fmt.Errorf("streaming not supported"), apierror.ErrInternal))
+			return
+		}
+
+		sub := s.stream.subscribe(filter)
+		defer s.stream.unsubscribe(sub)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, ": connected\n\n")
+		flusher.Flush()
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+				flusher.Flush()
+			case change := <-sub.changes:
+				payload, err := json.Marshal(change)
+				if err != nil {
+					log.Println(err.Error())
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", change.Type, payload)
+				flusher.Flush()
+			}
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+)
+
+func TestComputeChanges(t *testing.T) {
+	sat1 := data.Satellite{SatelliteName: "SAT 1", NORADID: 1, TLELine1: "line 1", TLELine2: "line 2"}
+	sat2 := data.Satellite{SatelliteName: "SAT 2", NORADID: 2, TLELine1: "line 1", TLELine2: "line 2"}
+	sat2Updated := data.Satellite{SatelliteName: "SAT 2", NORADID: 2, TLELine1: "new line 1", TLELine2: "new line 2"}
+
+	tests := []struct {
+		name     string
+		previous []data.Satellite
+		current  []data.Satellite
+		want     []ChangeType
+	}{
+		{
+			name:     "first pull",
+			previous: nil,
+			current:  []data.Satellite{sat1, sat2},
+			want:     []ChangeType{ChangeAdded, ChangeAdded},
+		},
+		{
+			name:     "no change",
+			previous: []data.Satellite{sat1, sat2},
+			current:  []data.Satellite{sat1, sat2},
+			want:     nil,
+		},
+		{
+			name:     "update and removal",
+			previous: []data.Satellite{sat1, sat2},
+			current:  []data.Satellite{sat2Updated},
+			want:     []ChangeType{ChangeUpdated, ChangeRemoved},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeChanges(tt.previous, tt.current)
+			if len(got) != len(tt.want) {
+				t.Fatalf("computeChanges() got %v changes, want %v", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i].Type != tt.want[i] {
+					t.Errorf("computeChanges() change %v got type %v, want %v", i, got[i].Type, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGetStream(t *testing.T) {
+	source := data.NewFileSource(
+		"../samples/tle_server_testing.txt")
+
+	s := NewServer(80, source, time.Duration(30)*time.Second)
+	s.InitializeRoutes()
+
+	sats, err := s.source.GetData()
+	if err != nil {
+		t.Fatalf("data from source %s not working", s.source.GetDataSource())
+	}
+	s.UpdateAllValues(sats)
+
+	ts := httptest.NewServer(s.router)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/stream?constellation=oneweb", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("stream request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected response code %d. Got %d\n", http.StatusOK, resp.StatusCode)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); line != ": connected\n" {
+		t.Fatalf("Expected connection comment. Got %q\n", line)
+	}
+
+	// Update one Starlink and one OneWeb satellite, only the OneWeb one must be streamed
+	updated := make([]data.Satellite, len(sats))
+	copy(updated, sats)
+	for i := range updated {
+		if updated[i].SatelliteName == "STARLINK-61" || updated[i].SatelliteName == "ONEWEB-0012" {
+			updated[i].TLELine1 = strings.Replace(updated[i].TLELine1, "22", "23", 1)
+		}
+	}
+	s.UpdateAllValues(updated)
+
+	var event, payload string
+	for payload == "" {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream failed: %v", err)
+		}
+		if strings.HasPrefix(line, "event: ") {
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event: "))
+		}
+		if strings.HasPrefix(line, "data: ") {
+			payload = strings.TrimPrefix(line, "data: ")
+		}
+	}
+
+	if event != string(ChangeUpdated) {
+		t.Errorf("Expected event %s. Got %s\n", ChangeUpdated, event)
+	}
+
+	var change SatelliteChange
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		t.Fatalf("could not parse event payload: %v", err)
+	}
+	if change.Satellite.SatelliteName != "ONEWEB-0012" || change.Previous == nil {
+		t.Errorf("Unexpected change received: %s\n", payload)
+	}
+}
+
+func TestGetStreamInvalidFilter(t *testing.T) {
+	s := NewServer(80, data.NewFileSource("../samples/tle_server_testing.txt"), time.Duration(30)*time.Second)
+	s.InitializeRoutes()
+
+	tests := []struct {
+		name         string
+		path         string
+		wantRespCode int
+	}{
+		{
+			name:         "invalid NORAD ID",
+			path:         "/stream?norad_id=abc",
+			wantRespCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown constellation",
+			path:         "/stream?constellation=DOESNOTEXIST",
+			wantRespCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Errorf("Expected response code %d. Got %d\n", tt.wantRespCode, response.Code)
+			}
+		})
+	}
+}
+
+func TestBrokerDispatch(t *testing.T) {
+	b := newBroker()
+	all, err := newSatelliteFilter(nil, nil, nil)
+	if err != nil {
+		t.Fatalf("newSatelliteFilter() error = %v", err)
+	}
+	other, err := newSatelliteFilter(nil, []int{2}, nil)
+	if err != nil {
+		t.Fatalf("newSatelliteFilter() error = %v", err)
+	}
+	slow := b.subscribe(all)
+	filtered := b.subscribe(other)
+
+	changes := make([]SatelliteChange, streamBufferSize+3)
+	for i := range changes {
+		changes[i] = SatelliteChange{Type: ChangeUpdated, Satellite: data.Satellite{SatelliteName: "SAT", NORADID: 1}}
+	}
+	dropped, slowSubscribers := b.dispatch(changes)
+	if dropped != 3 || slowSubscribers != 1 {
+		t.Errorf("dispatch() = %v, %v, want 3, 1", dropped, slowSubscribers)
+	}
+	if len(slow.changes) != streamBufferSize || len(filtered.changes) != 0 {
+		t.Errorf("got %v and %v queued changes, want %v and 0", len(slow.changes), len(filtered.changes), streamBufferSize)
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/geo"
+	"github.com/go-chi/render"
+)
+
+// parseObserver reads the latitude and longitude in degrees, both required, and the altitude in km of an observer
+func parseObserver(r *http.Request) (frames.Geodetic, error) {
+	var observer frames.Geodetic
+	for _, name := range []string{"latitude", "longitude"} {
+		if r.URL.Query().Get(name) == "" {
+			return frames.Geodetic{}, fmt.Errorf("missing %v parameter", name)
+		}
+	}
+
+	var err error
+	if observer.Latitude, err = parseFloat(r, "latitude", 0); err != nil {
+		return frames.Geodetic{}, err
+	}
+	if observer.Longitude, err = parseFloat(r, "longitude", 0); err != nil {
+		return frames.Geodetic{}, err
+	}
+	if observer.Altitude, err = parseFloat(r, "altitude", 0); err != nil {
+		return frames.Geodetic{}, err
+	}
+	return observer, geo.ValidateObserver(observer)
+}
+
+// getVisibility returns the filtered satellites above the minimum elevation of an observer, now by default,
+// with their azimuth, elevation and range
+func (s *Server) getVisibility() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		observer, err := parseObserver(r)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		filter, err := parseSatelliteFilter(r)
+		if err != nil {
+			handleFilterError(w, r, err)
+			return
+		}
+
+		at, err := parseTime(r, "time", time.Now().UTC())
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+		minElevation, err := parseFloat(r, "min_elevation", 0)
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		propagators, missing := s.filteredPropagators(filter)
+		if len(propagators) == 0 && missing == 0 {
+			apierror.Handle(w, r, apierror.Wrap(fmt.Errorf("no satellite found"), apierror.ErrNotFound))
+			return
+		}
+
+		visibility, err := geo.NewVisibility(propagators, observer, at, minElevation, 0, s.eopAt(at))
+		if err != nil {
+			badRequest(w, r, err)
+			return
+		}
+
+		if err := render.Render(w, r, visibility); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Funkit/tle-provider/geo"
+)
+
+func TestGetVisibility(t *testing.T) {
+	s := newSampleServer(t)
+
+	tests := []struct {
+		name         string
+		target       string
+		wantRespCode int
+		wantVisible  int
+	}{
+		{"whole sky", "/visibility?latitude=48.85&longitude=2.35&altitude=0.035&min_elevation=-90&time=2022-07-26T00:00:00Z", http.StatusOK, 8},
+		{"constellation whole sky", "/visibility?latitude=48.85&longitude=2.35&min_elevation=-90&constellation=oneweb", http.StatusOK, 3},
+		{"above the horizon", "/visibility?latitude=-33.9&longitude=18.4&time=2022-07-26T00:00:00Z", http.StatusOK, -1},
+		{"missing latitude", "/visibility?longitude=2.35", http.StatusBadRequest, 0},
+		{"invalid longitude", "/visibility?latitude=48.85&longitude=200", http.StatusBadRequest, 0},
+		{"invalid elevation", "/visibility?latitude=48.85&longitude=2.35&min_elevation=high", http.StatusBadRequest, 0},
+		{"elevation out of range", "/visibility?latitude=48.85&longitude=2.35&min_elevation=91", http.StatusBadRequest, 0},
+		{"unknown constellation", "/visibility?latitude=48.85&longitude=2.35&constellation=unknown", http.StatusNotFound, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.target, nil)
+			response := executeRequest(req, s)
+			if response.Code != tt.wantRespCode {
+				t.Fatalf("Expected response code %d. Got %d, body %s", tt.wantRespCode, response.Code, response.Body.String())
+			}
+			if response.Code != http.StatusOK {
+				return
+			}
+
+			var visibility geo.Visibility
+			if err := json.Unmarshal(response.Body.Bytes(), &visibility); err != nil {
+				t.Fatalf("invalid visibility %s", response.Body.String())
+			}
+			if tt.wantVisible >= 0 && len(visibility.Satellites)+len(visibility.Failed) != tt.wantVisible {
+				t.Errorf("expected %d satellites, got %s", tt.wantVisible, response.Body.String())
+			}
+			for i, sat := range visibility.Satellites {
+				if sat.Elevation < visibility.MinElevation || (i > 0 && sat.Elevation > visibility.Satellites[i-1].Elevation) {
+					t.Errorf("satellites not above the minimum elevation or not sorted %s", response.Body.String())
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,452 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Funkit/go-utils/apierror"
+	"github.com/Funkit/tle-provider/data"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// ChangeOrbit webhook event sent when the orbit of a satellite changed more than the webhook thresholds between two pulls
+const ChangeOrbit ChangeType = "orbit_change"
+
+const webhookTimeout = 10 * time.Second
+
+// webhookQueueSize number of payloads waiting for delivery to a webhook, the next ones being dropped until the queue drains
+const webhookQueueSize = 100
+
+// Webhook registered webhook
+type Webhook struct {
+	ID string `json:"id"`
+	data.WebhookConfiguration
+	filter satelliteFilter
+}
+
+func (wh Webhook) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// redacted copy of the webhook without its secret
+func (wh Webhook) redacted() Webhook {
+	wh.Secret = ""
+	return wh
+}
+
+// wants returns true if the webhook subscribed to the event
+func (wh Webhook) wants(event ChangeType) bool {
+	if len(wh.Events) == 0 {
+		return true
+	}
+	for _, e := range wh.Events {
+		if ChangeType(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+// orbitChanged returns true if the semi-major axis or inclination residual of the update exceeds the webhook threshold.
+// The other orbit events of the maneuver detection, on the RAAN or mean motion, are not sent to the webhooks.
+func (wh Webhook) orbitChanged(change SatelliteChange) bool {
+	if change.Type != ChangeUpdated || change.Previous == nil {
+		return false
+	}
+
+	residuals, err := data.OrbitResiduals(*change.Previous, change.Satellite)
+	if err != nil || residuals == nil {
+		return false
+	}
+
+	semiMajorAxisThreshold, inclinationThreshold := wh.SemiMajorAxisThresholdKm, wh.InclinationThresholdDeg
+	if semiMajorAxisThreshold <= 0 {
+		semiMajorAxisThreshold = data.DefaultDetectionThresholds.SemiMajorAxisKm
+	}
+	if inclinationThreshold <= 0 {
+		inclinationThreshold = data.DefaultDetectionThresholds.InclinationDeg
+	}
+	return math.Abs(residuals.SemiMajorAxisResidual) > semiMajorAxisThreshold || math.Abs(residuals.InclinationResidual) > inclinationThreshold
+}
+
+// webhookPayload body of the POST request sent to the webhooks
+type webhookPayload struct {
+	Event     ChangeType        `json:"event"`
+	Timestamp time.Time         `json:"timestamp"`
+	Changes   []SatelliteChange `json:"changes"`
+}
+
+// webhookQueue payloads waiting for delivery to a webhook, sent one at a time by its worker to keep their order
+type webhookQueue struct {
+	payloads chan webhookPayload
+	done     chan struct{}
+}
+
+// webhookDispatcher sends the detected changes to the registered webhooks, retrying with exponential backoff on failure.
+// The webhooks cannot target internal services: the loopback, link-local, private and unspecified addresses are refused,
+// unless they belong to one of the allowed networks.
+type webhookDispatcher struct {
+	mu          sync.RWMutex
+	webhooks    map[string]Webhook
+	queues      map[string]*webhookQueue
+	allowed     []*net.IPNet
+	httpClient  *http.Client
+	queueSize   int
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+func newWebhookDispatcher() *webhookDispatcher {
+	wd := &webhookDispatcher{
+		webhooks:    make(map[string]Webhook),
+		queues:      make(map[string]*webhookQueue),
+		queueSize:   webhookQueueSize,
+		maxAttempts: 5,
+		backoff:     time.Second,
+		maxBackoff:  time.Minute,
+	}
+
+	// the destination is checked on the address actually dialed, after the name resolution, and without proxy
+	dialer := &net.Dialer{Timeout: webhookTimeout, Control: wd.controlDial}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	wd.httpClient = &http.Client{Timeout: webhookTimeout, Transport: transport}
+
+	return wd
+}
+
+// allow adds networks, in the CIDR notation or as single IP addresses, to the internal destinations allowed to the webhooks
+func (wd *webhookDispatcher) allow(networks []string) error {
+	parsed := make([]*net.IPNet, 0, len(networks))
+	for _, network := range networks {
+		if ip := net.ParseIP(network); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			parsed = append(parsed, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(network)
+		if err != nil {
+			return fmt.Errorf("invalid webhook network %v, expected an IP address or a CIDR", network)
+		}
+		parsed = append(parsed, ipNet)
+	}
+
+	wd.mu.Lock()
+	wd.allowed = append(wd.allowed, parsed...)
+	wd.mu.Unlock()
+	return nil
+}
+
+// checkIP refuses the internal addresses which are not explicitly allowed
+func (wd *webhookDispatcher) checkIP(ip net.IP) error {
+	if !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsPrivate() && !ip.IsUnspecified() {
+		return nil
+	}
+
+	wd.mu.RLock()
+	defer wd.mu.RUnlock()
+	for _, network := range wd.allowed {
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+	return fmt.Errorf("webhook destination %v is an internal address", ip)
+}
+
+// checkHost refuses the webhook hosts which are internal addresses or localhost, the host names being checked when dialed
+func (wd *webhookDispatcher) checkHost(host string) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return wd.checkIP(net.IPv4(127, 0, 0, 1))
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return wd.checkIP(ip)
+	}
+	return nil
+}
+
+// controlDial refuses the connections to the internal addresses, whatever the host name resolved to
+func (wd *webhookDispatcher) controlDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("invalid webhook destination %v", address)
+	}
+	return wd.checkIP(ip)
+}
+
+func (wd *webhookDispatcher) add(config data.WebhookConfiguration) (Webhook, error) {
+	target, err := url.Parse(config.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return Webhook{}, fmt.Errorf("invalid webhook URL %v", config.URL)
+	}
+	if err := wd.checkHost(target.Hostname()); err != nil {
+		return Webhook{}, err
+	}
+
+	for _, event := range config.Events {
+		switch ChangeType(event) {
+		case ChangeAdded, ChangeUpdated, ChangeRemoved, ChangeOrbit:
+		default:
+			return Webhook{}, fmt.Errorf("unknown webhook event %v", event)
+		}
+	}
+
+	filter, err := newSatelliteFilter(nil, config.NORADIDs, config.Constellations)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	id, err := generateID()
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	wh := Webhook{
+		ID:                   id,
+		WebhookConfiguration: config,
+		filter:               filter,
+	}
+
+	queue := &webhookQueue{payloads: make(chan webhookPayload, wd.queueSize), done: make(chan struct{})}
+	go wd.work(wh, queue)
+
+	wd.mu.Lock()
+	wd.webhooks[id] = wh
+	wd.queues[id] = queue
+	wd.mu.Unlock()
+
+	return wh, nil
+}
+
+// remove unregisters the webhook and stops its worker, the queued payloads being discarded
+func (wd *webhookDispatcher) remove(id string) bool {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+
+	if _, ok := wd.webhooks[id]; !ok {
+		return false
+	}
+	close(wd.queues[id].done)
+	delete(wd.webhooks, id)
+	delete(wd.queues, id)
+	return true
+}
+
+func (wd *webhookDispatcher) list() []Webhook {
+	wd.mu.RLock()
+	defer wd.mu.RUnlock()
+
+	output := make([]Webhook, 0, len(wd.webhooks))
+	for _, wh := range wd.webhooks {
+		output = append(output, wh.redacted())
+	}
+	sort.Slice(output, func(i, j int) bool {
+		return output[i].ID < output[j].ID
+	})
+	return output
+}
+
+// publish groups the changes by event for each webhook, and queues them for delivery in the background. The payloads are dropped
+// when the queue of a webhook is full, its destination being too slow or unavailable.
+func (wd *webhookDispatcher) publish(changes []SatelliteChange) {
+	wd.mu.RLock()
+	defer wd.mu.RUnlock()
+
+	for id, wh := range wd.webhooks {
+		events := make(map[ChangeType][]SatelliteChange)
+		for _, change := range changes {
+			if !wh.filter.matches(change.Satellite) {
+				continue
+			}
+			if wh.wants(change.Type) {
+				events[change.Type] = append(events[change.Type], change)
+			}
+			if wh.wants(ChangeOrbit) && wh.orbitChanged(change) {
+				orbitChange := change
+				orbitChange.Type = ChangeOrbit
+				events[ChangeOrbit] = append(events[ChangeOrbit], orbitChange)
+			}
+		}
+
+		for _, event := range []ChangeType{ChangeAdded, ChangeUpdated, ChangeRemoved, ChangeOrbit} {
+			if len(events[event]) == 0 {
+				continue
+			}
+			payload := webhookPayload{
+				Event:     event,
+				Timestamp: time.Now().UTC(),
+				Changes:   events[event],
+			}
+			select {
+			case wd.queues[id].payloads <- payload:
+			default:
+				log.Printf("webhook %s: delivery queue full, dropping %s event with %v changes\n", wh.ID, event, len(payload.Changes))
+			}
+		}
+	}
+}
+
+// work delivers the queued payloads of the webhook in order, until the webhook is removed
+func (wd *webhookDispatcher) work(wh Webhook, queue *webhookQueue) {
+	for {
+		select {
+		case payload := <-queue.payloads:
+			wd.deliver(wh, payload, queue.done)
+		case <-queue.done:
+			return
+		}
+	}
+}
+
+// deliver posts the payload to the webhook, retrying with exponential backoff and jitter until it succeeds, the maximum number
+// of attempts is reached or the webhook is removed
+func (wd *webhookDispatcher) deliver(wh Webhook, payload webhookPayload, done <-chan struct{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Println(err.Error())
+		return
+	}
+
+	for attempt := 1; attempt <= wd.maxAttempts; attempt++ {
+		err = wd.post(wh, payload.Event, body)
+		if err == nil {
+			return
+		}
+		log.Printf("webhook %s delivery attempt %v/%v failed: %v\n", wh.ID, attempt, wd.maxAttempts, err)
+
+		if attempt < wd.maxAttempts {
+			select {
+			case <-time.After(data.BackoffDelay(wd.backoff, wd.maxBackoff, attempt)):
+			case <-done:
+				return
+			}
+		}
+	}
+
+	log.Printf("webhook %s: giving up delivery of %s event to %s\n", wh.ID, payload.Event, wh.URL)
+}
+
+func (wd *webhookDispatcher) post(wh Webhook, event ChangeType, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-TLE-Provider-Event", string(event))
+	if wh.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set("X-TLE-Provider-Timestamp", timestamp)
+		req.Header.Set("X-TLE-Provider-Signature", "sha256="+Sign(wh.Secret, timestamp, body))
+	}
+
+	resp, err := wd.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("response status code %v", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign computes the hex encoded HMAC-SHA256 signature of the timestamp, a dot and the webhook body, sent in the
+// X-TLE-Provider-Signature header. Signing the timestamp of the X-TLE-Provider-Timestamp header lets the receivers reject replayed requests.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// AllowWebhookNetworks allows the webhooks to target the given internal networks, in the CIDR notation or as single IP addresses.
+// The loopback, link-local and private addresses are refused otherwise.
+func (s *Server) AllowWebhookNetworks(networks []string) error {
+	return s.webhooks.allow(networks)
+}
+
+// AddWebhook registers a webhook called when changes are detected on the satellites it is interested in
+func (s *Server) AddWebhook(config data.WebhookConfiguration) (Webhook, error) {
+	return s.webhooks.add(config)
+}
+
+func (s *Server) getWebhooks() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhooks := s.webhooks.list()
+
+		renderList := make([]render.Renderer, 0, len(webhooks))
+		for _, wh := range webhooks {
+			renderList = append(renderList, wh)
+		}
+		if err := render.RenderList(w, r, renderList); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
+
+func (s *Server) postWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var config data.WebhookConfiguration
+		if err := render.DecodeJSON(r.Body, &config); err != nil {
+			badRequest(w, r, fmt.Errorf("invalid webhook: %v", err))
+			return
+		}
+
+		wh, err := s.webhooks.add(config)
+		if err != nil {
+			handleFilterError(w, r, err)
+			return
+		}
+
+		render.Status(r, http.StatusCreated)
+		if err := render.Render(w, r, wh.redacted()); err != nil {
+			apierror.Handle(w, r, apierror.Wrap(err, apierror.ErrRender))
+		}
+	}
+}
+
+func (s *Server) deleteWebhook() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		if !s.webhooks.remove(id) {
+			apierror.Handle(w, r, apierror.Wrap(fmt.Errorf("webhook %v not found", id), apierror.ErrNotFound))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
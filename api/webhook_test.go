@@ -0,0 +1,374 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+)
+
+type receivedWebhook struct {
+	event     string
+	timestamp string
+	signature string
+	payload   webhookPayload
+	body      []byte
+}
+
+// newWebhookReceiver starts a local server failing the first failures requests, and recording the others
+func newWebhookReceiver(t *testing.T, failures int) (*httptest.Server, chan receivedWebhook) {
+	var mu sync.Mutex
+	received := make(chan receivedWebhook, 10)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var payload webhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("invalid webhook payload: %v", err)
+		}
+		received <- receivedWebhook{
+			event:     r.Header.Get("X-TLE-Provider-Event"),
+			timestamp: r.Header.Get("X-TLE-Provider-Timestamp"),
+			signature: r.Header.Get("X-TLE-Provider-Signature"),
+			payload:   payload,
+			body:      body,
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	return ts, received
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	receiver, received := newWebhookReceiver(t, 2)
+	defer receiver.Close()
+
+	source := data.NewFileSource("../samples/tle_server_testing.txt")
+	s := NewServer(80, source, time.Duration(30)*time.Second)
+	s.webhooks.backoff = time.Millisecond
+	s.webhooks.maxBackoff = 5 * time.Millisecond
+	if err := s.AllowWebhookNetworks([]string{"127.0.0.1"}); err != nil {
+		t.Fatalf("AllowWebhookNetworks() error = %v", err)
+	}
+
+	sats, err := s.source.GetData()
+	if err != nil {
+		t.Fatalf("data from source %s not working", s.source.GetDataSource())
+	}
+	s.UpdateAllValues(sats)
+
+	if _, err := s.AddWebhook(data.WebhookConfiguration{
+		URL:            receiver.URL,
+		Secret:         "secret",
+		Constellations: []string{"starlink"},
+		Events:         []string{string(ChangeUpdated), string(ChangeOrbit)},
+	}); err != nil {
+		t.Fatalf("AddWebhook() error = %v", err)
+	}
+
+	// STARLINK-61 mean motion lowered from 15.997 to 15.897 rev/day one day later: orbit change
+	// ONEWEB-0012 updated, but filtered out by the constellation
+	updated := make([]data.Satellite, len(sats))
+	copy(updated, sats)
+	for i := range updated {
+		switch updated[i].SatelliteName {
+		case "STARLINK-61":
+			updated[i].TLELine1 = strings.Replace(updated[i].TLELine1, "22207.", "22208.", 1)
+			updated[i].TLELine2 = strings.Replace(updated[i].TLELine2, "15.99740001", "15.89740001", 1)
+		case "ONEWEB-0012":
+			updated[i].TLELine1 = strings.Replace(updated[i].TLELine1, "22206", "22207", 1)
+		}
+	}
+	s.UpdateAllValues(updated)
+
+	events := make(map[string]receivedWebhook)
+	for len(events) < 2 {
+		select {
+		case wh := <-received:
+			events[wh.event] = wh
+		case <-time.After(5 * time.Second):
+			t.Fatalf("webhooks not received, got %v events", len(events))
+		}
+	}
+
+	for _, event := range []ChangeType{ChangeUpdated, ChangeOrbit} {
+		wh, ok := events[string(event)]
+		if !ok {
+			t.Errorf("Expected %s event", event)
+			continue
+		}
+		if wh.timestamp == "" || wh.signature != "sha256="+Sign("secret", wh.timestamp, wh.body) {
+			t.Errorf("Invalid signature %s for %s event", wh.signature, event)
+		}
+		if len(wh.payload.Changes) != 1 || wh.payload.Changes[0].Satellite.SatelliteName != "STARLINK-61" {
+			t.Errorf("Unexpected changes for %s event: %s", event, wh.body)
+		}
+	}
+}
+
+func TestWebhookQueue(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var names []string
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("invalid webhook payload: %v", err)
+		}
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+
+		mu.Lock()
+		names = append(names, payload.Changes[0].Satellite.SatelliteName)
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	wd := newWebhookDispatcher()
+	wd.queueSize = 3
+	if err := wd.allow([]string{"127.0.0.1"}); err != nil {
+		t.Fatalf("allow() error = %v", err)
+	}
+	wh, err := wd.add(data.WebhookConfiguration{URL: receiver.URL})
+	if err != nil {
+		t.Fatalf("add() error = %v", err)
+	}
+	defer wd.remove(wh.ID)
+
+	publish := func(name string) {
+		wd.publish([]SatelliteChange{{Type: ChangeAdded, Satellite: data.Satellite{SatelliteName: name}}})
+	}
+
+	// the first payload is being delivered, the next ones fill the queue and the last ones are dropped
+	publish("SAT-0")
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not called")
+	}
+	for _, name := range []string{"SAT-1", "SAT-2", "SAT-3", "SAT-4", "SAT-5"} {
+		publish(name)
+	}
+	close(release)
+
+	want := []string{"SAT-0", "SAT-1", "SAT-2", "SAT-3"}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		got := append([]string(nil), names...)
+		mu.Unlock()
+		if len(got) >= len(want) || time.Now().After(deadline) {
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("delivered %v, want %v", got, want)
+			}
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// no late delivery of the dropped payloads
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(names) != len(want) {
+		t.Errorf("delivered %v, want %v", names, want)
+	}
+}
+
+func TestWebhookRoutes(t *testing.T) {
+	s := NewServer(80, data.NewFileSource("../samples/tle_server_testing.txt"), time.Duration(30)*time.Second)
+	s.InitializeRoutes()
+	s.AddAPITokens("token")
+
+	tests := []struct {
+		name         string
+		token        string
+		body         string
+		wantRespCode int
+	}{
+		{
+			name:         "valid webhook",
+			token:        "token",
+			body:         `{"url":"https://example.com/hook","secret":"s","norad_ids":[25544],"events":["added"]}`,
+			wantRespCode: http.StatusCreated,
+		},
+		{
+			name:         "missing token",
+			body:         `{"url":"https://example.com/hook"}`,
+			wantRespCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "invalid token",
+			token:        "wrong",
+			body:         `{"url":"https://example.com/hook"}`,
+			wantRespCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "invalid URL",
+			token:        "token",
+			body:         `{"url":"localhost"}`,
+			wantRespCode: http.StatusBadRequest,
+		},
+		{
+			name:         "localhost",
+			token:        "token",
+			body:         `{"url":"http://localhost:8080/hook"}`,
+			wantRespCode: http.StatusBadRequest,
+		},
+		{
+			name:         "link-local",
+			token:        "token",
+			body:         `{"url":"http://169.254.169.254/latest/meta-data"}`,
+			wantRespCode: http.StatusBadRequest,
+		},
+		{
+			name:         "private",
+			token:        "token",
+			body:         `{"url":"https://10.0.0.1/hook"}`,
+			wantRespCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown event",
+			token:        "token",
+			body:         `{"url":"https://example.com/hook","events":["launched"]}`,
+			wantRespCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown constellation",
+			token:        "token",
+			body:         `{"url":"https://example.com/hook","constellations":["DOESNOTEXIST"]}`,
+			wantRespCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := executeRequest(overrideRequest("POST", "/webhooks", tt.token, tt.body), s)
+			if response.Code != tt.wantRespCode {
+				t.Errorf("Expected response code %d. Got %d\n", tt.wantRespCode, response.Code)
+			}
+		})
+	}
+
+	if response := executeRequest(overrideRequest("GET", "/webhooks", "", ""), s); response.Code != http.StatusUnauthorized {
+		t.Errorf("Expected response code %d. Got %d\n", http.StatusUnauthorized, response.Code)
+	}
+	response := executeRequest(overrideRequest("GET", "/webhooks", "token", ""), s)
+	var webhooks []Webhook
+	if err := json.Unmarshal(response.Body.Bytes(), &webhooks); err != nil || len(webhooks) != 1 {
+		t.Fatalf("Expected one registered webhook. Got %s\n", response.Body.String())
+	}
+	if webhooks[0].Secret != "" {
+		t.Errorf("Webhook secret must not be returned")
+	}
+
+	if response = executeRequest(overrideRequest("DELETE", "/webhooks/"+webhooks[0].ID, "", ""), s); response.Code != http.StatusUnauthorized {
+		t.Errorf("Expected response code %d. Got %d\n", http.StatusUnauthorized, response.Code)
+	}
+	if response = executeRequest(overrideRequest("DELETE", "/webhooks/"+webhooks[0].ID, "token", ""), s); response.Code != http.StatusNoContent {
+		t.Errorf("Expected response code %d. Got %d\n", http.StatusNoContent, response.Code)
+	}
+	if response = executeRequest(overrideRequest("DELETE", "/webhooks/"+webhooks[0].ID, "token", ""), s); response.Code != http.StatusNotFound {
+		t.Errorf("Expected response code %d. Got %d\n", http.StatusNotFound, response.Code)
+	}
+}
+
+func TestWebhookDestinations(t *testing.T) {
+	wd := newWebhookDispatcher()
+	if err := wd.allow([]string{"10.1.0.0/16", "fd00::1"}); err != nil {
+		t.Fatalf("allow() error = %v", err)
+	}
+	if err := wd.allow([]string{"internal"}); err == nil {
+		t.Errorf("Expected an error for an invalid network")
+	}
+
+	tests := []struct {
+		name    string
+		address string
+		wantErr bool
+	}{
+		{"public", "93.184.216.34:443", false},
+		{"public IPv6", "[2606:2800:220:1::1]:443", false},
+		{"loopback", "127.0.0.1:80", true},
+		{"loopback IPv6", "[::1]:80", true},
+		{"unspecified", "0.0.0.0:80", true},
+		{"link-local", "169.254.169.254:80", true},
+		{"private", "192.168.1.10:80", true},
+		{"allowed network", "10.1.2.3:80", false},
+		{"private outside the allowed network", "10.2.0.1:80", true},
+		{"allowed address", "[fd00::1]:80", false},
+		{"private IPv6", "[fd00::2]:80", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := wd.controlDial("tcp", tt.address, nil); (err != nil) != tt.wantErr {
+				t.Errorf("controlDial() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// the names are resolved when dialing, except localhost
+	for host, wantErr := range map[string]bool{"example.com": false, "localhost": true, "api.localhost": true, "10.1.0.1": false} {
+		if err := wd.checkHost(host); (err != nil) != wantErr {
+			t.Errorf("checkHost(%s) error = %v, wantErr %v", host, err, wantErr)
+		}
+	}
+}
+
+func TestWebhookOrbitChanged(t *testing.T) {
+	epoch := time.Date(2022, 7, 26, 0, 0, 0, 0, time.UTC)
+	elements := data.KeplerianElements{SatelliteName: "SAT", NORADID: 90001, Epoch: epoch, SemiMajorAxisKm: 6928, Eccentricity: 0.001, Inclination: 53, RAAN: 100}
+	previous, err := data.GenerateTLE(elements)
+	if err != nil {
+		t.Fatalf("GenerateTLE() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		config data.WebhookConfiguration
+		modify func(ke *data.KeplerianElements)
+		want   bool
+	}{
+		{"unchanged", data.WebhookConfiguration{}, func(ke *data.KeplerianElements) {}, false},
+		{"semi-major axis", data.WebhookConfiguration{}, func(ke *data.KeplerianElements) { ke.SemiMajorAxisKm += 10 }, true},
+		{"semi-major axis below threshold", data.WebhookConfiguration{SemiMajorAxisThresholdKm: 20}, func(ke *data.KeplerianElements) { ke.SemiMajorAxisKm += 10 }, false},
+		{"inclination", data.WebhookConfiguration{}, func(ke *data.KeplerianElements) { ke.Inclination += 0.2 }, true},
+		{"inclination below threshold", data.WebhookConfiguration{InclinationThresholdDeg: 0.5}, func(ke *data.KeplerianElements) { ke.Inclination += 0.2 }, false},
+		{"RAAN only", data.WebhookConfiguration{}, func(ke *data.KeplerianElements) { ke.RAAN += 2 }, false},
+		{"older epoch", data.WebhookConfiguration{}, func(ke *data.KeplerianElements) { ke.Epoch = epoch.Add(-time.Hour) }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := elements
+			updated.Epoch = epoch.Add(time.Minute)
+			tt.modify(&updated)
+			current, err := data.GenerateTLE(updated)
+			if err != nil {
+				t.Fatalf("GenerateTLE() error = %v", err)
+			}
+
+			wh := Webhook{WebhookConfiguration: tt.config}
+			if got := wh.orbitChanged(SatelliteChange{Type: ChangeUpdated, Satellite: current, Previous: &previous}); got != tt.want {
+				t.Errorf("orbitChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
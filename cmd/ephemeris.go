@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/ephemeris"
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/sgp4"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ephemerisTLEFile   string
+	ephemerisSatellite string
+	ephemerisNORADID   int
+	ephemerisFormat    string
+	ephemerisFrame     string
+	ephemerisStart     string
+	ephemerisEnd       string
+	ephemerisStep      float64
+	ephemerisOutput    string
+	ephemerisEOPFile   string
+	ephemerisLeapFile  string
+)
+
+var ephemerisCmd = &cobra.Command{
+	Use:   "ephemeris",
+	Short: "Generates an ephemeris file from a TLE",
+	Long: `Generates the ephemeris file of a satellite over a time window, by propagating its TLE with SGP4.
+
+The TLE file can be in any format supported by the file source (TLE, 3LE or OMM), and the satellite
+is selected with --satellite or --norad-id. The supported formats are CCSDS OEM (oem), STK ephemeris (stk)
+and SP3 (sp3), in the TEME, GCRF, EME2000 or ITRF frame. SP3 files are always in the ITRF frame, which
+takes the polar motion and UT1 into account when an IERS finals file is given with --eop-file.
+The leap seconds known at build time can be replaced with --leap-seconds-file.`,
+	Example: `  tle-provider ephemeris --tle-file ./samples/active_satellites_tle.txt --norad-id 44057 --format oem \
+    --start 2026-11-01T00:00:00Z --end 2026-11-02T00:00:00Z --step 60 --output 44057.oem`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if (ephemerisSatellite == "") == (ephemerisNORADID == 0) {
+			return fmt.Errorf("exactly one of --satellite and --norad-id is required")
+		}
+		options := ephemeris.Options{
+			Step:  time.Duration(ephemerisStep * float64(time.Second)),
+			Frame: ephemerisFrame,
+		}
+		var err error
+		if options.Start, err = time.Parse(time.RFC3339, ephemerisStart); err != nil {
+			return fmt.Errorf("invalid start %v: %w", ephemerisStart, err)
+		}
+		if options.End, err = time.Parse(time.RFC3339, ephemerisEnd); err != nil {
+			return fmt.Errorf("invalid end %v: %w", ephemerisEnd, err)
+		}
+
+		if ephemerisEOPFile != "" {
+			if options.EOP, err = frames.LoadFinals(ephemerisEOPFile); err != nil {
+				return err
+			}
+		}
+		if ephemerisLeapFile != "" {
+			leapSeconds, err := frames.LoadLeapSeconds(ephemerisLeapFile)
+			if err != nil {
+				return err
+			}
+			frames.SetLeapSeconds(leapSeconds)
+		}
+
+		sats, err := data.NewFileSource(ephemerisTLEFile).GetData()
+		if err != nil {
+			return err
+		}
+		var sat data.Satellite
+		for _, candidate := range sats {
+			if (ephemerisSatellite != "" && candidate.SatelliteName == ephemerisSatellite) ||
+				(ephemerisNORADID != 0 && candidate.NORADID == ephemerisNORADID) {
+				sat = candidate
+				break
+			}
+		}
+		if sat.IsNull() {
+			return fmt.Errorf("satellite not found in %v", ephemerisTLEFile)
+		}
+
+		p, err := sgp4.New(sat)
+		if err != nil {
+			return err
+		}
+		eph, err := ephemeris.New(p, ephemerisFormat, options)
+		if err != nil {
+			return err
+		}
+
+		var w io.Writer = cmd.OutOrStdout()
+		if ephemerisOutput != "" {
+			file, err := os.Create(ephemerisOutput)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			w = file
+		}
+		if err := ephemeris.Write(w, ephemerisFormat, eph); err != nil {
+			return err
+		}
+		if ephemerisOutput != "" {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%d states of %s written to %s\n", len(eph.States), sat.SatelliteName, ephemerisOutput)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ephemerisCmd)
+
+	flags := ephemerisCmd.Flags()
+	flags.StringVar(&ephemerisTLEFile, "tle-file", "", "file containing the TLE of the satellite")
+	flags.StringVar(&ephemerisSatellite, "satellite", "", "name of the satellite")
+	flags.IntVar(&ephemerisNORADID, "norad-id", 0, "NORAD catalog number of the satellite")
+	flags.StringVar(&ephemerisFormat, "format", ephemeris.FormatOEM, "ephemeris format, oem, stk or sp3")
+	flags.StringVar(&ephemerisFrame, "frame", "", "reference frame, TEME, GCRF, EME2000 or ITRF, TEME by default except for SP3")
+	flags.StringVar(&ephemerisStart, "start", "", "start of the window, RFC3339 format")
+	flags.StringVar(&ephemerisEnd, "end", "", "end of the window, RFC3339 format")
+	flags.Float64Var(&ephemerisStep, "step", 60, "step between the states in seconds")
+	flags.StringVar(&ephemerisOutput, "output", "", "output file, the standard output if empty")
+	flags.StringVar(&ephemerisEOPFile, "eop-file", "", "IERS finals file of the Earth orientation parameters used in the ITRF frame")
+	flags.StringVar(&ephemerisLeapFile, "leap-seconds-file", "", "IERS Leap_Second.dat or leap-seconds.list file")
+
+	ephemerisCmd.MarkFlagRequired("tle-file")
+	ephemerisCmd.MarkFlagRequired("start")
+	ephemerisCmd.MarkFlagRequired("end")
+}
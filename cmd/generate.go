@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/spf13/cobra"
+)
+
+var (
+	elements data.KeplerianElements
+	epoch    string
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generates a TLE from orbital elements",
+	Long: `Generates a TLE from orbital elements, for example a placeholder TLE for a planned launch.
+
+The orbit size is given by exactly one of --semi-major-axis, --apogee and --perigee, or --mean-motion.
+The TLE is printed in the 3LE format, and can be uploaded to a running server with the /overrides API.`,
+	Example: `  tle-provider generate --name PLANNED-1 --norad-id 99901 --epoch 2026-11-01T12:00:00Z \
+    --apogee 550 --perigee 540 --inclination 97.5 --raan 120`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		if elements.Epoch, err = time.Parse(time.RFC3339, epoch); err != nil {
+			return fmt.Errorf("invalid epoch %v: %w", epoch, err)
+		}
+
+		sat, err := data.GenerateTLE(elements)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), sat.SatelliteName)
+		fmt.Fprintln(cmd.OutOrStdout(), sat.TLELine1)
+		fmt.Fprintln(cmd.OutOrStdout(), sat.TLELine2)
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+
+	flags := generateCmd.Flags()
+	flags.StringVar(&elements.SatelliteName, "name", "", "satellite name, the NORAD ID if empty")
+	flags.IntVar(&elements.NORADID, "norad-id", 0, "NORAD catalog number")
+	flags.StringVar(&elements.ObjectID, "object-id", "", "international designator, for example 2026-001A")
+	flags.StringVar(&elements.Classification, "classification", "U", "classification (U, C or S)")
+	flags.StringVar(&epoch, "epoch", "", "epoch of the elements, RFC3339 format")
+	flags.Float64Var(&elements.SemiMajorAxisKm, "semi-major-axis", 0, "semi-major axis in km")
+	flags.Float64Var(&elements.ApogeeKm, "apogee", 0, "apogee altitude in km")
+	flags.Float64Var(&elements.PerigeeKm, "perigee", 0, "perigee altitude in km")
+	flags.Float64Var(&elements.MeanMotion, "mean-motion", 0, "mean motion in revolutions per day")
+	flags.Float64Var(&elements.Eccentricity, "eccentricity", 0, "eccentricity, not used with --apogee and --perigee")
+	flags.Float64Var(&elements.Inclination, "inclination", 0, "inclination in degrees")
+	flags.Float64Var(&elements.RAAN, "raan", 0, "right ascension of the ascending node in degrees")
+	flags.Float64Var(&elements.ArgOfPericenter, "arg-of-pericenter", 0, "argument of pericenter in degrees")
+	flags.Float64Var(&elements.MeanAnomaly, "mean-anomaly", 0, "mean anomaly in degrees")
+	flags.Float64Var(&elements.BStar, "bstar", 0, "B* drag term in 1/earth radii")
+	flags.IntVar(&elements.ElementSetNo, "element-set-no", 999, "element set number")
+	flags.IntVar(&elements.RevAtEpoch, "rev-at-epoch", 0, "revolution number at epoch")
+
+	generateCmd.MarkFlagRequired("norad-id")
+	generateCmd.MarkFlagRequired("epoch")
+}
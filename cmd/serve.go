@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"log"
+	"path/filepath"
 	"time"
 
 	"github.com/Funkit/go-utils/utils"
@@ -21,7 +22,8 @@ from various sources, and expose it through a single REST API.
 
 The following sources are currently supported:
 - Celestrak
-- File source (following the Celestrak file formatting)`,
+- File source (following the Celestrak file formatting)
+- URL source (TLE or OMM data served over HTTP(S))`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		config, err := utils.GenericYAMLParsing[data.Info](cfgFile)
 		if err != nil {
@@ -39,19 +41,91 @@ The following sources are currently supported:
 
 		switch config.DataSource {
 		case "celestrak":
-			source = data.NewCelestrakClient(
+			celestrakClient, err := data.NewCelestrakClient(
 				config.CelestrakConfiguration.AllSatellitesURL,
-				config.CelestrakConfiguration.GeoSatellitesURL)
+				config.CelestrakConfiguration.GeoSatellitesURL,
+				config.CelestrakConfiguration.HTTP)
+			if err != nil {
+				return err
+			}
+			source = celestrakClient
 			refreshRate = time.Duration(config.CelestrakConfiguration.RefreshRateHours) * time.Hour
 		case "file":
-			source = data.NewFileSource(
-				config.FileSourceConfiguration.SourceFilePath)
+			fileConfig := config.FileSourceConfiguration
+			var fileSource *data.FileSource
+			switch {
+			case fileConfig.SourceGlob != "":
+				fileSource = data.NewGlobFileSource(fileConfig.SourceGlob)
+			case fileConfig.SourceDirectory != "":
+				fileSource = data.NewGlobFileSource(filepath.Join(fileConfig.SourceDirectory, "*"))
+			default:
+				fileSource = data.NewFileSource(fileConfig.SourceFilePath)
+			}
+			if fileConfig.Format != "" {
+				if err := fileSource.SetFormat(fileConfig.Format); err != nil {
+					return err
+				}
+			}
+			if fileConfig.Watch {
+				debounce := time.Duration(fileConfig.WatchDebounceMilliseconds) * time.Millisecond
+				if debounce <= 0 {
+					debounce = 500 * time.Millisecond
+				}
+				if err := fileSource.Watch(debounce); err != nil {
+					return err
+				}
+				defer fileSource.Close()
+			}
+			source = fileSource
 			refreshRate = time.Duration(config.FileSourceConfiguration.RefreshRateSeconds) * time.Second
+		case "url":
+			urlSource, err := data.NewURLSource(config.URLSourceConfiguration)
+			if err != nil {
+				return err
+			}
+			source = urlSource
+			refreshRate = time.Duration(config.URLSourceConfiguration.RefreshRateSeconds) * time.Second
 		}
 
 		server := api.NewServer(config.ServerPort, source, refreshRate)
+		server.DetectionThresholds = config.ManeuverDetection
+		server.IngestHistory = config.IngestReportHistory
 		server.AddMiddlewares(middleware.Logger, render.SetContentType(render.ContentTypeJSON), middleware.Recoverer)
 		server.InitializeRoutes()
+		server.AddAPITokens(config.APITokens...)
+
+		if config.Overrides.Enabled {
+			store, err := data.NewOverrideStore(config.Overrides.StorePath)
+			if err != nil {
+				return err
+			}
+			if len(config.APITokens) == 0 && len(config.Overrides.APITokens) == 0 {
+				log.Println("Overrides enabled without API token: the overrides cannot be modified")
+			}
+			server.EnableOverrides(store, config.Overrides.APITokens)
+			log.Printf("%v overrides loaded\n", len(store.List()))
+		}
+
+		eopSource, err := data.NewEOPSource(config.EOP)
+		if err != nil {
+			return err
+		}
+		server.EnableEOP(eopSource)
+
+		if err := server.EnableJobs(config.Jobs); err != nil {
+			return err
+		}
+
+		if err := server.AllowWebhookNetworks(config.WebhookAllowedNetworks); err != nil {
+			return err
+		}
+		for _, webhookConfig := range config.Webhooks {
+			wh, err := server.AddWebhook(webhookConfig)
+			if err != nil {
+				return err
+			}
+			log.Printf("Webhook %s registered for %s\n", wh.ID, wh.URL)
+		}
 
 		if err := server.Run(); err != nil {
 			return err
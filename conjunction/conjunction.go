@@ -0,0 +1,370 @@
+// Package conjunction screens the close approaches between satellites propagated with SGP4
+package conjunction
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+const (
+	// DefaultThreshold miss distance in km below which close approaches are reported
+	DefaultThreshold = 10.0
+	// DefaultStep sampling step of the relative distances
+	DefaultStep = time.Minute
+	// MaxPrimarySamples maximum number of stored positions of the primary satellites, primaries times samples
+	MaxPrimarySamples = 2000000
+
+	// maxRelativeSpeed upper bound in km/s of the relative speed of two Earth orbiting satellites
+	maxRelativeSpeed = 20.0
+	// prefilterPadding margin in km of the apogee/perigee prefilter, covering the short period variations of the radius
+	// which are not included in the mean elements, and the decay over the window
+	prefilterPadding = 50.0
+	// tcaPrecision precision of the time of closest approach
+	tcaPrecision = 10 * time.Millisecond
+)
+
+// Object satellite involved in a conjunction
+type Object struct {
+	SatelliteName string `json:"satellite_name"`
+	NORADID       int    `json:"norad_id"`
+}
+
+// Conjunction close approach between two satellites at the time of closest approach
+type Conjunction struct {
+	Primary       Object    `json:"primary"`
+	Secondary     Object    `json:"secondary"`
+	TCA           time.Time `json:"tca"`
+	MissDistance  float64   `json:"miss_distance_km"`
+	RelativeSpeed float64   `json:"relative_speed_km_s"`
+}
+
+// Options parameters of a screening
+type Options struct {
+	Start time.Time
+	End   time.Time
+	// Threshold miss distance in km, DefaultThreshold when 0
+	Threshold float64
+	// Step sampling step of the relative distances, DefaultStep when 0
+	Step time.Duration
+	// Workers number of concurrent propagations, the number of CPUs when 0
+	Workers int
+}
+
+// Result close approaches found by a screening, sorted by time of closest approach
+type Result struct {
+	Start        time.Time     `json:"start"`
+	End          time.Time     `json:"end"`
+	Threshold    float64       `json:"threshold_km"`
+	StepSeconds  float64       `json:"step_seconds"`
+	Primaries    int           `json:"primaries"`
+	Secondaries  int           `json:"secondaries"`
+	Candidates   int           `json:"candidates"`
+	Failed       []int         `json:"failed"`
+	Conjunctions []Conjunction `json:"conjunctions"`
+}
+
+// withDefaults options with the default values of the unset fields
+func (o Options) withDefaults() Options {
+	if o.Threshold == 0 {
+		o.Threshold = DefaultThreshold
+	}
+	if o.Step == 0 {
+		o.Step = DefaultStep
+	}
+	if o.Workers <= 0 {
+		o.Workers = runtime.NumCPU()
+	}
+	return o
+}
+
+// Validate checks the options of a screening of the given number of primary satellites, the unset fields taking their default values
+func (o Options) Validate(primaries int) error {
+	o = o.withDefaults()
+	if o.Threshold < 0 {
+		return fmt.Errorf("threshold must be positive")
+	}
+	if o.Step < 0 {
+		return fmt.Errorf("step must be positive")
+	}
+	if !o.End.After(o.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+	if primaries == 0 {
+		return fmt.Errorf("no primary satellite")
+	}
+	if samples := int64(primaries) * int64(o.End.Sub(o.Start)/o.Step+1); samples > MaxPrimarySamples {
+		return fmt.Errorf("screening too large (%d primary samples, the maximum is %d), reduce the window or the number of primaries, or increase the step",
+			samples, MaxPrimarySamples)
+	}
+	return nil
+}
+
+// radii perigee and apogee radii in km of an orbit, from its mean elements
+type radii struct {
+	perigee, apogee float64
+}
+
+// Screen finds the close approaches between the primary satellites and the catalog over the window of the options.
+// The pairs whose perigee/apogee ranges do not overlap are discarded, then the distances of the remaining pairs are
+// sampled with the step of the options, and the local minima are refined to get the time of closest approach.
+// Pairs of primaries are screened once, and the satellites which cannot be propagated over the window are listed in Failed.
+func Screen(ctx context.Context, primaries, catalog []*sgp4.Propagator, options Options) (Result, error) {
+	options = options.withDefaults()
+	if err := options.Validate(len(primaries)); err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		Start:        options.Start,
+		End:          options.End,
+		Threshold:    options.Threshold,
+		StepSeconds:  options.Step.Seconds(),
+		Primaries:    len(primaries),
+		Secondaries:  len(catalog),
+		Failed:       []int{},
+		Conjunctions: []Conjunction{},
+	}
+	times := sampleTimes(options.Start, options.End, options.Step)
+	failed := make(map[int]struct{})
+
+	// primary positions at the sample times, shared by all the secondaries
+	type primary struct {
+		p         *sgp4.Propagator
+		radii     radii
+		positions [][3]float64
+	}
+	screened := make([]primary, 0, len(primaries))
+	isPrimary := make(map[int]struct{}, len(primaries))
+	for _, p := range primaries {
+		isPrimary[p.Satellite.NORADID] = struct{}{}
+		r, err := orbitRadii(p)
+		if err != nil {
+			failed[p.Satellite.NORADID] = struct{}{}
+			continue
+		}
+		positions, err := propagateAll(p, times, nil)
+		if err != nil {
+			failed[p.Satellite.NORADID] = struct{}{}
+			continue
+		}
+		screened = append(screened, primary{p: p, radii: r, positions: positions})
+	}
+
+	var mu sync.Mutex
+	jobs := make(chan *sgp4.Propagator)
+	var wg sync.WaitGroup
+	for w := 0; w < options.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var positions [][3]float64
+			distances := make([]float64, len(times))
+			for secondary := range jobs {
+				r, err := orbitRadii(secondary)
+				if err != nil {
+					mu.Lock()
+					failed[secondary.Satellite.NORADID] = struct{}{}
+					mu.Unlock()
+					continue
+				}
+
+				var candidates []primary
+				for _, prim := range screened {
+					if prim.p.Satellite.NORADID == secondary.Satellite.NORADID {
+						continue
+					}
+					// pairs of primaries are screened from the primary with the lowest NORAD ID
+					if _, ok := isPrimary[secondary.Satellite.NORADID]; ok && secondary.Satellite.NORADID < prim.p.Satellite.NORADID {
+						continue
+					}
+					if overlap(prim.radii, r, options.Threshold+prefilterPadding) {
+						candidates = append(candidates, prim)
+					}
+				}
+				if len(candidates) == 0 {
+					continue
+				}
+
+				if positions, err = propagateAll(secondary, times, positions); err != nil {
+					mu.Lock()
+					failed[secondary.Satellite.NORADID] = struct{}{}
+					mu.Unlock()
+					continue
+				}
+
+				var found []Conjunction
+				for _, prim := range candidates {
+					for i := range times {
+						distances[i] = distance(prim.positions[i], positions[i])
+					}
+					found = append(found, closeApproaches(prim.p, secondary, times, distances, options)...)
+				}
+
+				mu.Lock()
+				result.Candidates += len(candidates)
+				result.Conjunctions = append(result.Conjunctions, found...)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, secondary := range catalog {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- secondary
+	}
+	close(jobs)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	for id := range failed {
+		result.Failed = append(result.Failed, id)
+	}
+	sort.Ints(result.Failed)
+	sort.Slice(result.Conjunctions, func(i, j int) bool {
+		if !result.Conjunctions[i].TCA.Equal(result.Conjunctions[j].TCA) {
+			return result.Conjunctions[i].TCA.Before(result.Conjunctions[j].TCA)
+		}
+		return result.Conjunctions[i].MissDistance < result.Conjunctions[j].MissDistance
+	})
+
+	return result, nil
+}
+
+// closeApproaches refines the local minima of the sampled distances which may be below the threshold.
+// Minima at the window edges give the closest approach within the window.
+func closeApproaches(primary, secondary *sgp4.Propagator, times []time.Time, distances []float64, options Options) []Conjunction {
+	// the distance cannot decrease faster than the relative speed between the samples
+	gate := options.Threshold + maxRelativeSpeed*options.Step.Seconds()
+
+	var conjunctions []Conjunction
+	for i, d := range distances {
+		if d > gate {
+			continue
+		}
+		if (i > 0 && distances[i-1] < d) || (i < len(distances)-1 && distances[i+1] < d) {
+			continue
+		}
+
+		low, high := times[i], times[i]
+		if i > 0 {
+			low = times[i-1]
+		}
+		if i < len(times)-1 {
+			high = times[i+1]
+		}
+
+		conjunction, err := refine(primary, secondary, low, high)
+		if err != nil || conjunction.MissDistance > options.Threshold {
+			continue
+		}
+		conjunctions = append(conjunctions, conjunction)
+	}
+	return conjunctions
+}
+
+// refine searches the time of closest approach between low and high with a golden section search
+func refine(primary, secondary *sgp4.Propagator, low, high time.Time) (Conjunction, error) {
+	var propagationErr error
+	distanceAt := func(t time.Time) float64 {
+		s1, err := primary.Propagate(t)
+		if err != nil {
+			propagationErr = err
+			return math.Inf(1)
+		}
+		s2, err := secondary.Propagate(t)
+		if err != nil {
+			propagationErr = err
+			return math.Inf(1)
+		}
+		return distance(s1.Position, s2.Position)
+	}
+
+	const ratio = 0.6180339887498949
+	for high.Sub(low) > tcaPrecision {
+		left := high.Add(-time.Duration(ratio * float64(high.Sub(low))))
+		right := low.Add(time.Duration(ratio * float64(high.Sub(low))))
+		if distanceAt(left) < distanceAt(right) {
+			high = right
+		} else {
+			low = left
+		}
+	}
+	if propagationErr != nil {
+		return Conjunction{}, propagationErr
+	}
+
+	tca := low.Add(high.Sub(low) / 2).Round(tcaPrecision)
+	s1, err := primary.Propagate(tca)
+	if err != nil {
+		return Conjunction{}, err
+	}
+	s2, err := secondary.Propagate(tca)
+	if err != nil {
+		return Conjunction{}, err
+	}
+
+	return Conjunction{
+		Primary:       Object{SatelliteName: primary.Satellite.SatelliteName, NORADID: primary.Satellite.NORADID},
+		Secondary:     Object{SatelliteName: secondary.Satellite.SatelliteName, NORADID: secondary.Satellite.NORADID},
+		TCA:           tca,
+		MissDistance:  distance(s1.Position, s2.Position),
+		RelativeSpeed: distance(s1.Velocity, s2.Velocity),
+	}, nil
+}
+
+// orbitRadii perigee and apogee radii of the satellite from the mean elements of its TLE
+func orbitRadii(p *sgp4.Propagator) (radii, error) {
+	el, err := data.ParseElements(p.Satellite)
+	if err != nil {
+		return radii{}, err
+	}
+	a := el.SemiMajorAxis()
+	return radii{perigee: a * (1 - el.Eccentricity), apogee: a * (1 + el.Eccentricity)}, nil
+}
+
+// overlap true if the radius ranges of the two orbits are closer than the margin
+func overlap(r1, r2 radii, margin float64) bool {
+	return math.Max(r1.perigee, r2.perigee)-math.Min(r1.apogee, r2.apogee) <= margin
+}
+
+// sampleTimes times from start to end with the given step, the end being always included
+func sampleTimes(start, end time.Time, step time.Duration) []time.Time {
+	times := make([]time.Time, 0, end.Sub(start)/step+2)
+	for t := start; t.Before(end); t = t.Add(step) {
+		times = append(times, t)
+	}
+	return append(times, end)
+}
+
+// propagateAll propagates the satellite at the given times, reusing the buffer when large enough
+func propagateAll(p *sgp4.Propagator, times []time.Time, buffer [][3]float64) ([][3]float64, error) {
+	if cap(buffer) < len(times) {
+		buffer = make([][3]float64, len(times))
+	}
+	buffer = buffer[:len(times)]
+	for i, t := range times {
+		state, err := p.Propagate(t)
+		if err != nil {
+			return buffer, err
+		}
+		buffer[i] = state.Position
+	}
+	return buffer, nil
+}
+
+func distance(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
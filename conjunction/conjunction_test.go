@@ -0,0 +1,116 @@
+package conjunction
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+var epoch = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func newPropagator(tb testing.TB, ke data.KeplerianElements) *sgp4.Propagator {
+	tb.Helper()
+	ke.Epoch = epoch
+	sat, err := data.GenerateTLE(ke)
+	if err != nil {
+		tb.Fatalf("GenerateTLE() error = %v", err)
+	}
+	p, err := sgp4.New(sat)
+	if err != nil {
+		tb.Fatalf("sgp4.New() error = %v", err)
+	}
+	return p
+}
+
+func TestScreen(t *testing.T) {
+	// both satellites are at the ascending node of crossing orbits at the epoch
+	polar := newPropagator(t, data.KeplerianElements{SatelliteName: "POLAR", NORADID: 99911, ApogeeKm: 700, PerigeeKm: 700, Inclination: 98})
+	inclined := newPropagator(t, data.KeplerianElements{SatelliteName: "INCLINED", NORADID: 99912, ApogeeKm: 700, PerigeeKm: 700, Inclination: 60})
+	higher := newPropagator(t, data.KeplerianElements{SatelliteName: "HIGHER", NORADID: 99913, ApogeeKm: 1500, PerigeeKm: 1500, Inclination: 98})
+	decayed := newPropagator(t, data.KeplerianElements{SatelliteName: "DECAYED", NORADID: 99914, ApogeeKm: 720, PerigeeKm: 150, BStar: 0.5})
+	catalog := []*sgp4.Propagator{polar, inclined, higher, decayed}
+
+	tests := []struct {
+		name             string
+		primaries        []*sgp4.Propagator
+		options          Options
+		wantConjunctions int
+		wantCandidates   int
+		wantFailed       int
+		wantErr          bool
+	}{
+		{"crossing at the epoch", []*sgp4.Propagator{polar}, Options{Start: epoch.Add(-10 * time.Minute), End: epoch.Add(10 * time.Minute)}, 1, 2, 0, false},
+		{"coarse step", []*sgp4.Propagator{polar}, Options{Start: epoch.Add(-10 * time.Minute), End: epoch.Add(10 * time.Minute), Step: 5 * time.Minute, Workers: 1}, 1, 2, 0, false},
+		{"pairs of primaries screened once", []*sgp4.Propagator{polar, inclined}, Options{Start: epoch.Add(-10 * time.Minute), End: epoch.Add(10 * time.Minute)}, 1, 3, 0, false},
+		{"decayed secondary", []*sgp4.Propagator{polar}, Options{Start: epoch.Add(30 * 24 * time.Hour), End: epoch.Add(30*24*time.Hour + 10*time.Minute), Threshold: 0.001}, 0, 1, 1, false},
+		{"tight threshold", []*sgp4.Propagator{polar}, Options{Start: epoch.Add(-10 * time.Minute), End: epoch.Add(10 * time.Minute), Threshold: 0.001}, 0, 2, 0, false},
+		{"no primary", nil, Options{Start: epoch, End: epoch.Add(time.Hour)}, 0, 0, 0, true},
+		{"invalid window", []*sgp4.Propagator{polar}, Options{Start: epoch, End: epoch}, 0, 0, 0, true},
+		{"invalid threshold", []*sgp4.Propagator{polar}, Options{Start: epoch, End: epoch.Add(time.Hour), Threshold: -1}, 0, 0, 0, true},
+		{"too many samples", []*sgp4.Propagator{polar}, Options{Start: epoch, End: epoch.Add(time.Hour), Step: time.Millisecond}, 0, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Screen(context.Background(), tt.primaries, catalog, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Screen() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(got.Conjunctions) != tt.wantConjunctions || got.Candidates != tt.wantCandidates {
+				t.Fatalf("expected %d conjunctions and %d candidates, got %+v", tt.wantConjunctions, tt.wantCandidates, got)
+			}
+			if len(got.Failed) != tt.wantFailed || (tt.wantFailed > 0 && got.Failed[0] != 99914) {
+				t.Errorf("expected %d failures, got %v", tt.wantFailed, got.Failed)
+			}
+			for _, c := range got.Conjunctions {
+				if c.Primary.NORADID != 99911 || c.Secondary.NORADID != 99912 || c.TCA.Before(epoch.Add(-10*time.Second)) || c.TCA.After(epoch.Add(10*time.Second)) || c.MissDistance > DefaultThreshold || c.RelativeSpeed < 4 {
+					t.Errorf("invalid conjunction %+v", c)
+				}
+			}
+		})
+	}
+}
+
+func TestScreenCanceled(t *testing.T) {
+	polar := newPropagator(t, data.KeplerianElements{NORADID: 99911, ApogeeKm: 700, PerigeeKm: 700, Inclination: 98})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Screen(ctx, []*sgp4.Propagator{polar}, []*sgp4.Propagator{polar}, Options{Start: epoch, End: epoch.Add(time.Hour)}); err != context.Canceled {
+		t.Errorf("Screen() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func BenchmarkScreen(b *testing.B) {
+	sats, err := data.NewFileSource("../samples/active_satellites_tle.txt").GetData()
+	if err != nil {
+		b.Fatalf("GetData() error = %v", err)
+	}
+	catalog := make([]*sgp4.Propagator, 0, len(sats))
+	var primary *sgp4.Propagator
+	for _, sat := range sats {
+		p, err := sgp4.New(sat)
+		if err != nil {
+			continue
+		}
+		if sat.SatelliteName == "ONEWEB-0012" {
+			primary = p
+		}
+		catalog = append(catalog, p)
+	}
+	start := time.Date(2022, 7, 27, 0, 0, 0, 0, time.UTC)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Screen(context.Background(), []*sgp4.Propagator{primary}, catalog, Options{Start: start, End: start.Add(24 * time.Hour)}); err != nil {
+			b.Fatalf("Screen() error = %v", err)
+		}
+	}
+}
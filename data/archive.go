@@ -0,0 +1,222 @@
+package data
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Limits of the expansion of compressed files and archives, to protect against decompression bombs
+const (
+	// maxDecompressedSize total size of the decompressed content and archive entries of a file
+	maxDecompressedSize = 1 << 30
+	// maxArchiveEntries total number of entries of the archives of a file, nested archives included
+	maxArchiveEntries = 10000
+	// maxArchiveDepth number of nested archives, 2 allowing an archive within an archive
+	maxArchiveDepth = 2
+	// maxCompressionDepth number of nested compressed layers, a compressed entry of a compressed archive having 2
+	maxCompressionDepth = 2
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// fileEntry file content to parse, either a plain file or an entry of an archive
+type fileEntry struct {
+	name    string
+	content []byte
+}
+
+// expandContent decompresses gzip and zstd content and extracts the regular files of zip and tar archives. Other content is returned as is.
+// The names of archive entries are prefixed with the archive name, so that the format of each entry can be detected from its extension.
+func expandContent(name string, content []byte) ([]fileEntry, error) {
+	e := &expansion{maxSize: maxDecompressedSize, maxEntries: maxArchiveEntries, maxArchives: maxArchiveDepth, maxCompressions: maxCompressionDepth}
+	return e.expand(name, content, 0, 0)
+}
+
+// expansion limits shared by all the layers of the expansion of a file, so that nested archives cannot multiply them
+type expansion struct {
+	maxSize         int64
+	maxEntries      int
+	maxArchives     int
+	maxCompressions int
+	size            int64
+	entries         int
+}
+
+// expand expands the content found at the given number of nested archives and compressed layers
+func (e *expansion) expand(name string, content []byte, archives, compressions int) ([]fileEntry, error) {
+	switch {
+	case bytes.HasPrefix(content, gzipMagic), bytes.HasPrefix(content, zstdMagic):
+		if compressions == e.maxCompressions {
+			return nil, fmt.Errorf("%s: more than %v nested compressed layers", name, e.maxCompressions)
+		}
+		decompress := e.gunzip
+		if bytes.HasPrefix(content, zstdMagic) {
+			decompress = e.unzstd
+		}
+		decompressed, err := decompress(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		return e.expand(trimCompressionExtension(name), decompressed, archives, compressions+1)
+	case bytes.HasPrefix(content, zipMagic), isTar(name, content):
+		if archives == e.maxArchives {
+			return nil, fmt.Errorf("%s: more than %v nested archives", name, e.maxArchives)
+		}
+		if bytes.HasPrefix(content, zipMagic) {
+			return e.unzip(name, content, archives+1, compressions)
+		}
+		return e.untar(name, content, archives+1, compressions)
+	}
+
+	return []fileEntry{{name: name, content: content}}, nil
+}
+
+// trimCompressionExtension removes the .gz and .zst extensions, and converts .tgz and .tzst to .tar
+func trimCompressionExtension(name string) string {
+	lower := strings.ToLower(name)
+	for _, extension := range []string{".gz", ".zst"} {
+		if strings.HasSuffix(lower, extension) {
+			return name[:len(name)-len(extension)]
+		}
+	}
+	for _, extension := range []string{".tgz", ".tzst"} {
+		if strings.HasSuffix(lower, extension) {
+			return name[:len(name)-len(extension)] + ".tar"
+		}
+	}
+	return name
+}
+
+// isTar returns true for files with the tar extension, or with the ustar magic in the header
+func isTar(name string, content []byte) bool {
+	if strings.HasSuffix(strings.ToLower(name), ".tar") {
+		return true
+	}
+	return len(content) > 262 && bytes.Equal(content[257:262], []byte("ustar"))
+}
+
+func (e *expansion) gunzip(content []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return e.read(reader)
+}
+
+func (e *expansion) unzstd(content []byte) ([]byte, error) {
+	// the window is limited as well, so that a crafted frame header cannot allocate more than maxDecompressedSize
+	reader, err := zstd.NewReader(bytes.NewReader(content), zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(maxDecompressedSize))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return e.read(reader)
+}
+
+func (e *expansion) unzip(name string, content []byte, archives, compressions int) ([]fileEntry, error) {
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	var entries []fileEntry
+	for _, file := range reader.File {
+		if err := e.countEntry(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", name, file.Name, err)
+		}
+		entryContent, err := e.read(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", name, file.Name, err)
+		}
+
+		expanded, err := e.expand(path.Join(name, file.Name), entryContent, archives, compressions)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, expanded...)
+	}
+
+	return entries, nil
+}
+
+func (e *expansion) untar(name string, content []byte, archives, compressions int) ([]fileEntry, error) {
+	reader := tar.NewReader(bytes.NewReader(content))
+
+	var entries []fileEntry
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if err := e.countEntry(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		entryContent, err := e.read(reader)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", name, header.Name, err)
+		}
+
+		expanded, err := e.expand(path.Join(name, header.Name), entryContent, archives, compressions)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, expanded...)
+	}
+
+	return entries, nil
+}
+
+// countEntry fails once the archives of the file have more than the allowed number of entries
+func (e *expansion) countEntry() error {
+	if e.entries == e.maxEntries {
+		return fmt.Errorf("more than %v archive entries", e.maxEntries)
+	}
+	e.entries++
+	return nil
+}
+
+// read reads the whole reader, failing if the total decompressed content of the file becomes bigger than the maximum size
+func (e *expansion) read(r io.Reader) ([]byte, error) {
+	remaining := e.maxSize - e.size
+	content, err := io.ReadAll(io.LimitReader(r, remaining+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > remaining {
+		return nil, fmt.Errorf("decompressed content bigger than %v bytes", e.maxSize)
+	}
+	e.size += int64(len(content))
+	return content, nil
+}
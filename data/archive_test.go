@@ -0,0 +1,256 @@
+package data
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func gzipBytes(t *testing.T, content []byte) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+	return buf.Bytes()
+}
+
+func zstdBytes(t *testing.T, content []byte) []byte {
+	var buf bytes.Buffer
+	writer, err := zstd.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(content)
+	}
+	writer.Close()
+	return buf.Bytes()
+}
+
+func tarBytes(t *testing.T, files map[string][]byte) []byte {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for name, content := range files {
+		if err := writer.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		writer.Write(content)
+	}
+	writer.Close()
+	return buf.Bytes()
+}
+
+func TestFileSource_compressed(t *testing.T) {
+	tleContent, err := os.ReadFile("../samples/tle_filesource_testing.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonContent, err := os.ReadFile("../samples/directory/oneweb.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		fileName  string
+		content   []byte
+		wantCount int
+		wantErr   string
+	}{
+		{
+			name:      "gzip TLE",
+			fileName:  "catalog.txt.gz",
+			content:   gzipBytes(t, tleContent),
+			wantCount: 3,
+		},
+		{
+			name:      "gzip OMM JSON",
+			fileName:  "catalog.json.gz",
+			content:   gzipBytes(t, jsonContent),
+			wantCount: 2,
+		},
+		{
+			name:      "zip archive",
+			fileName:  "catalog.zip",
+			content:   zipBytes(t, map[string][]byte{"a/tle.txt": tleContent, "oneweb.json": jsonContent}),
+			wantCount: 5,
+		},
+		{
+			name:      "gzip tar archive",
+			fileName:  "catalog.tgz",
+			content:   gzipBytes(t, tarBytes(t, map[string][]byte{"tle.3le": tleContent, "oneweb.json": jsonContent})),
+			wantCount: 5,
+		},
+		{
+			name:      "tar archive with compressed entry",
+			fileName:  "catalog.tar",
+			content:   tarBytes(t, map[string][]byte{"tle.txt.gz": gzipBytes(t, tleContent)}),
+			wantCount: 3,
+		},
+		{
+			name:      "zstd TLE",
+			fileName:  "catalog.txt.zst",
+			content:   zstdBytes(t, tleContent),
+			wantCount: 3,
+		},
+		{
+			name:      "zstd tar archive",
+			fileName:  "catalog.tzst",
+			content:   zstdBytes(t, tarBytes(t, map[string][]byte{"tle.3le": tleContent, "oneweb.json": jsonContent})),
+			wantCount: 5,
+		},
+		{
+			name:     "corrupted zstd",
+			fileName: "catalog.txt.zst",
+			content:  append([]byte{0x28, 0xb5, 0x2f, 0xfd}, tleContent...),
+			wantErr:  "catalog.txt.zst",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.fileName)
+			if err := os.WriteFile(path, tt.content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := NewFileSource(path).GetData()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("GetData() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetData() error = %v", err)
+			}
+			if len(got) != tt.wantCount {
+				t.Errorf("GetData() got %v satellites, want %v", len(got), tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestGlobFileSource_archiveOrigin(t *testing.T) {
+	tleContent, err := os.ReadFile("../samples/tle_filesource_testing.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "history.zip")
+	if err := os.WriteFile(archive, zipBytes(t, map[string][]byte{"2022/tle.txt": tleContent, "broken.json": []byte("[{")}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := NewGlobFileSource(filepath.Join(dir, "*"))
+	got, err := fs.GetData()
+	if err != nil {
+		t.Fatalf("GetData() error = %v", err)
+	}
+
+	var origins []string
+	for _, sat := range got {
+		origins = append(origins, sat.Origin)
+	}
+	sort.Strings(origins)
+	wantOrigin := filepath.ToSlash(archive) + "/2022/tle.txt"
+	if len(origins) != 3 || filepath.ToSlash(origins[0]) != wantOrigin {
+		t.Errorf("GetData() origins = %v, want 3 times %v", origins, wantOrigin)
+	}
+
+	if len(fs.Rejected()) != 1 || !strings.Contains(fs.Rejected()[0].Err.Error(), "broken.json") {
+		t.Errorf("GetData() rejected = %v, want broken.json", fs.Rejected())
+	}
+}
+
+func TestExpansion_limits(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 600)
+	limits := expansion{maxSize: 1000, maxEntries: 3, maxArchives: maxArchiveDepth, maxCompressions: maxCompressionDepth}
+
+	tests := []struct {
+		name        string
+		content     []byte
+		wantEntries int
+		wantErr     string
+	}{
+		{
+			name:        "within the limits",
+			content:     zipBytes(t, map[string][]byte{"a.txt": content, "b.zip": zipBytes(t, map[string][]byte{"c.txt": []byte("c")})}),
+			wantEntries: 2,
+		},
+		{
+			name:    "total size of the entries",
+			content: zipBytes(t, map[string][]byte{"a.txt": content, "b.txt": content}),
+			wantErr: "bigger than 1000 bytes",
+		},
+		{
+			name:    "total size of the nested archives",
+			content: gzipBytes(t, zipBytes(t, map[string][]byte{"a.txt.gz": gzipBytes(t, bytes.Repeat([]byte("x"), 900))})),
+			wantErr: "bigger than 1000 bytes",
+		},
+		{
+			name:    "number of entries",
+			content: tarBytes(t, map[string][]byte{"a.txt": nil, "b.txt": nil, "c.txt": nil, "d.txt": nil}),
+			wantErr: "more than 3 archive entries",
+		},
+		{
+			name:    "number of entries of the nested archives",
+			content: zipBytes(t, map[string][]byte{"a.zip": zipBytes(t, map[string][]byte{"b.txt": nil, "c.txt": nil}), "d.zip": zipBytes(t, nil)}),
+			wantErr: "more than 3 archive entries",
+		},
+		{
+			name: "nested archives",
+			content: zipBytes(t, map[string][]byte{
+				"a.zip": zipBytes(t, map[string][]byte{"b.zip": zipBytes(t, map[string][]byte{"c.txt": nil})}),
+			}),
+			wantErr: "more than 2 nested archives",
+		},
+		{
+			name:    "nested compressed layers",
+			content: gzipBytes(t, zstdBytes(t, gzipBytes(t, []byte("a")))),
+			wantErr: "more than 2 nested compressed layers",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := limits
+			got, err := e.expand("catalog.zip", tt.content, 0, 0)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expand() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expand() error = %v", err)
+			}
+			if len(got) != tt.wantEntries {
+				t.Errorf("expand() got %v entries, want %v", len(got), tt.wantEntries)
+			}
+		})
+	}
+}
@@ -4,8 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"regexp"
 	"strings"
 	"sync"
@@ -37,21 +35,43 @@ type CelestrakData struct {
 	MeanMotionDDOT     float64 `json:"MEAN_MOTION_DDOT"`
 }
 
+const (
+	// celestrakMinRequestIntervalSeconds default minimum delay between two requests to Celestrak
+	celestrakMinRequestIntervalSeconds = 10
+	// celestrakRateLimitBackoffSeconds default suspension of the requests after a 403 response of Celestrak,
+	// the GP data being updated every two hours
+	celestrakRateLimitBackoffSeconds = 2 * 60 * 60
+)
+
 // CelestrakClient implementation of the Source interface for Celestrak
 type CelestrakClient struct {
-	httpClient       *http.Client
+	fetcher          *httpFetcher
 	AllSatellitesURL string
 	GeoSatellitesURL string
+	mu               sync.Mutex
+	rejected         []SatelliteErr
 }
 
-// NewCelestrakClient Generates a new CelestrakClient from the information in the configuration file
-func NewCelestrakClient(allSatellitesURL, geoSatellitesURL string) *CelestrakClient {
+// NewCelestrakClient Generates a new CelestrakClient from the information in the configuration file.
+// Celestrak answers with 403 to the clients downloading the same data too often, so the requests are spaced and suspended
+// after such a response by default.
+func NewCelestrakClient(allSatellitesURL, geoSatellitesURL string, httpConfig HTTPConfiguration) (*CelestrakClient, error) {
+	if httpConfig.MinRequestIntervalSeconds == 0 {
+		httpConfig.MinRequestIntervalSeconds = celestrakMinRequestIntervalSeconds
+	}
+	if httpConfig.RateLimitBackoffSeconds == 0 {
+		httpConfig.RateLimitBackoffSeconds = celestrakRateLimitBackoffSeconds
+	}
+	fetcher, err := newHTTPFetcher(httpConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	return &CelestrakClient{
-		httpClient:       &http.Client{},
+		fetcher:          fetcher,
 		AllSatellitesURL: allSatellitesURL,
 		GeoSatellitesURL: geoSatellitesURL,
-	}
+	}, nil
 }
 
 // GetData Implementation of the Source interface for Celestrak
@@ -61,28 +81,22 @@ func (cc *CelestrakClient) GetData() ([]Satellite, error) {
 		return nil, err
 	}
 
-	var wg sync.WaitGroup
-	output := make(chan Satellite, len(satData))
-
-	for i := 0; i < len(satData); i++ {
-		wg.Add(1)
-		go func(data CelestrakData) {
-			defer wg.Done()
-			sat, _ := convertToTLE(data)
-			output <- sat
-		}(satData[i])
-	}
+	tleList, rejected := convertAll(satData)
 
-	wg.Wait()
-	close(output)
-	var tleList []Satellite
-	for element := range output {
-		tleList = append(tleList, element)
-	}
+	cc.mu.Lock()
+	cc.rejected = rejected
+	cc.mu.Unlock()
 
 	return tleList, nil
 }
 
+// Rejected return the records which could not be converted to TLE during the last GetData call
+func (cc *CelestrakClient) Rejected() []SatelliteErr {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.rejected
+}
+
 // GetDataSource return server data source
 func (cc *CelestrakClient) GetDataSource() string {
 	return "celestrak"
@@ -93,35 +107,57 @@ func (cc *CelestrakClient) GetConfig() (map[string]interface{}, error) {
 	return nil, nil
 }
 
-// getCelestrakData Get data from celestrak
+// getCelestrakData Get data from celestrak. ErrNotModified is returned if the data did not change since the last call.
 func (cc *CelestrakClient) getCelestrakData() ([]CelestrakData, error) {
-	req, err := http.NewRequest(http.MethodGet, cc.AllSatellitesURL, nil)
-	if err != nil {
+	respBody, err := cc.fetcher.fetch(cc.AllSatellitesURL)
+	if errors.Is(err, ErrNotModified) {
 		return nil, err
 	}
-
-	response, err := cc.httpClient.Do(req)
 	if err != nil {
 		return nil, apierror.Wrap(err, apierror.ErrInternal)
 	}
-	defer response.Body.Close()
 
-	respBody, err := ioutil.ReadAll(response.Body)
-	if err != nil {
+	var output []CelestrakData
+
+	if err := json.Unmarshal(respBody, &output); err != nil {
+		// Download the data again next time, instead of getting a not modified response
+		cc.fetcher.forget(cc.AllSatellitesURL)
 		return nil, apierror.Wrap(err, apierror.ErrInternal)
 	}
 
-	if response.StatusCode >= 400 {
-		return nil, apierror.Wrap(fmt.Errorf("failed to query data from celestrak, response error code = %v", response.StatusCode), apierror.ErrNotFound)
+	return output, nil
+}
+
+// convertAll converts the Celestrak data to TLEs concurrently, keeping the input order, and returns the records which could not be converted separately
+func convertAll(satData []CelestrakData) ([]Satellite, []SatelliteErr) {
+	var wg sync.WaitGroup
+	sats := make([]Satellite, len(satData))
+	errs := make([]error, len(satData))
+
+	for i := 0; i < len(satData); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sats[i], errs[i] = convertToTLE(satData[i])
+		}(i)
 	}
 
-	var output []CelestrakData
+	wg.Wait()
 
-	if err := json.Unmarshal(respBody, &output); err != nil {
-		return nil, apierror.Wrap(err, apierror.ErrInternal)
+	var tleList []Satellite
+	var rejected []SatelliteErr
+	for i := range satData {
+		if errs[i] != nil {
+			rejected = append(rejected, SatelliteErr{
+				Err: errs[i],
+				Sat: Satellite{SatelliteName: satData[i].ObjectName, NORADID: satData[i].NORADCatID},
+			})
+			continue
+		}
+		tleList = append(tleList, sats[i])
 	}
 
-	return output, nil
+	return tleList, rejected
 }
 
 // convertToTLE converts JSON from Celestrak GP prototype to TLE. See wikipedia page for two line elements for an explanation of the fields
@@ -206,6 +242,11 @@ func convertToTLE(data CelestrakData) (Satellite, error) {
 }
 
 func objectIDToCOSPARID(objectID string) (string, error) {
+	// Unknown international designator, for analyst and pre-launch objects
+	if objectID == "" {
+		return "        ", nil
+	}
+
 	re := regexp.MustCompile(`[0-9]{2}(.+)-(.+)`)
 	matchResults := re.FindAllSubmatch([]byte(objectID), -1)
 	if (len(matchResults) != 1) || (len(matchResults[0]) != 3) {
@@ -1,6 +1,9 @@
 package data
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/go-chi/render"
@@ -12,20 +15,79 @@ type Info struct {
 	DataSource              string                  `yaml:"data_source"`
 	CelestrakConfiguration  CelestrakConfiguration  `yaml:"celestrak_configuration"`
 	FileSourceConfiguration FileSourceConfiguration `yaml:"file_source_configuration"`
+	URLSourceConfiguration  URLSourceConfiguration  `yaml:"url_source_configuration"`
+	APITokens               []string                `yaml:"api_tokens"`
+	Webhooks                []WebhookConfiguration  `yaml:"webhooks"`
+	WebhookAllowedNetworks  []string                `yaml:"webhook_allowed_networks"`
+	ManeuverDetection       DetectionThresholds     `yaml:"maneuver_detection"`
+	IngestReportHistory     int                     `yaml:"ingest_report_history"`
+	Overrides               OverrideConfiguration   `yaml:"overrides"`
+	Jobs                    JobsConfiguration       `yaml:"jobs"`
+	EOP                     EOPConfiguration        `yaml:"eop"`
 }
 
 type FileSourceConfiguration struct {
-	SourceFilePath     string `yaml:"source_file_path"`
-	RefreshRateSeconds int    `yaml:"refresh_rate_seconds"`
+	SourceFilePath            string `yaml:"source_file_path"`
+	SourceDirectory           string `yaml:"source_directory"`
+	SourceGlob                string `yaml:"source_glob"`
+	Format                    string `yaml:"format"`
+	RefreshRateSeconds        int    `yaml:"refresh_rate_seconds"`
+	Watch                     bool   `yaml:"watch"`
+	WatchDebounceMilliseconds int    `yaml:"watch_debounce_milliseconds"`
+}
+
+// URLSourceConfiguration HTTP(S) endpoints serving TLE or OMM data
+type URLSourceConfiguration struct {
+	URLs               []string             `yaml:"urls"`
+	Format             string               `yaml:"format"`
+	RefreshRateSeconds int                  `yaml:"refresh_rate_seconds"`
+	Auth               URLAuthConfiguration `yaml:"auth"`
+	HTTP               HTTPConfiguration    `yaml:"http"`
 }
 
 type CelestrakConfiguration struct {
-	AllSatellitesURL string `yaml:"all_satellites_url"`
-	GeoSatellitesURL string `yaml:"geo_satellites_url"`
-	RefreshRateHours int    `yaml:"celestrak_refresh_rate_hours"`
+	AllSatellitesURL string            `yaml:"all_satellites_url"`
+	GeoSatellitesURL string            `yaml:"geo_satellites_url"`
+	RefreshRateHours int               `yaml:"celestrak_refresh_rate_hours"`
+	HTTP             HTTPConfiguration `yaml:"http"`
+}
+
+// WebhookConfiguration webhook called when TLE changes are detected for the satellites matching the filters
+type WebhookConfiguration struct {
+	URL                      string   `yaml:"url" json:"url"`
+	Secret                   string   `yaml:"secret" json:"secret,omitempty"`
+	NORADIDs                 []int    `yaml:"norad_ids" json:"norad_ids,omitempty"`
+	Constellations           []string `yaml:"constellations" json:"constellations,omitempty"`
+	Events                   []string `yaml:"events" json:"events,omitempty"`
+	SemiMajorAxisThresholdKm float64  `yaml:"semi_major_axis_threshold_km" json:"semi_major_axis_threshold_km,omitempty"`
+	InclinationThresholdDeg  float64  `yaml:"inclination_threshold_deg" json:"inclination_threshold_deg,omitempty"`
+}
+
+// JobsConfiguration background jobs of the long running computations. Without store directory, the jobs are only kept in memory.
+type JobsConfiguration struct {
+	Workers          int    `yaml:"workers"`
+	QueueSize        int    `yaml:"queue_size"`
+	RetentionMinutes int    `yaml:"retention_minutes"`
+	StoreDirectory   string `yaml:"store_directory"`
+}
+
+// EOPConfiguration sources of the Earth orientation parameters and leap seconds used by the frame conversions, either local files
+// or URLs downloaded at each data refresh. The downloaded files are saved in the cache directory, and loaded from there on startup.
+type EOPConfiguration struct {
+	FinalsFile      string            `yaml:"finals_file"`
+	FinalsURL       string            `yaml:"finals_url"`
+	LeapSecondsFile string            `yaml:"leap_seconds_file"`
+	LeapSecondsURL  string            `yaml:"leap_seconds_url"`
+	CacheDirectory  string            `yaml:"cache_directory"`
+	HTTP            HTTPConfiguration `yaml:"http"`
 }
 
 func (i Info) IsValid() bool {
+	if i.DataSource == "url" {
+		return i.ServerPort != 0 &&
+			len(i.URLSourceConfiguration.URLs) != 0
+	}
+
 	return i.ServerPort != 0 &&
 		i.DataSource != "" &&
 		i.CelestrakConfiguration.RefreshRateHours != 0 &&
@@ -39,13 +101,33 @@ type Satellite struct {
 	NORADID       int    `json:"norad_id"`
 	TLELine1      string `json:"tle_line_1"`
 	TLELine2      string `json:"tle_line_2"`
+	Origin        string `json:"origin,omitempty"`
 }
 
+// SatelliteErr record rejected during ingest, with the reason of the rejection
 type SatelliteErr struct {
 	Err error
 	Sat Satellite
 }
 
+func (se SatelliteErr) Error() string {
+	return fmt.Sprintf("%s (%v): %v", se.Sat.SatelliteName, se.Sat.NORADID, se.Err)
+}
+
+func (se SatelliteErr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Satellite Satellite `json:"satellite"`
+		Reason    string    `json:"reason"`
+	}{
+		Satellite: se.Sat,
+		Reason:    se.Err.Error(),
+	})
+}
+
+func (se SatelliteErr) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
 func (s Satellite) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
@@ -68,3 +150,17 @@ type Source interface {
 	GetDataSource() string
 	GetConfig() (map[string]interface{}, error)
 }
+
+// ErrNotModified returned by GetData when the source data did not change since the last call
+var ErrNotModified = errors.New("source data not modified")
+
+// Notifier optional interface for the sources able to signal that their data changed, so that it is pulled without waiting for the refresh period.
+// A nil channel is returned when change notifications are disabled.
+type Notifier interface {
+	Changes() <-chan struct{}
+}
+
+// RejectReporter optional interface for the sources able to report the records they could not convert during the last GetData call
+type RejectReporter interface {
+	Rejected() []SatelliteErr
+}
@@ -0,0 +1,180 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// EarthMu Earth gravitational parameter in km3/s2 (WGS72, as used by SGP4)
+	EarthMu = 398600.8
+	// EarthRadius Earth equatorial radius in km (WGS72, as used by SGP4)
+	EarthRadius = 6378.135
+)
+
+// Elements orbital elements and metadata read from the two lines of a TLE
+type Elements struct {
+	NORADID            int       `json:"norad_id"`
+	Classification     string    `json:"classification"`
+	InternationalDesig string    `json:"international_designator"`
+	Epoch              time.Time `json:"epoch"`
+	MeanMotionDot      float64   `json:"mean_motion_dot"`
+	MeanMotionDDot     float64   `json:"mean_motion_ddot"`
+	BStar              float64   `json:"bstar"`
+	ElementSetNo       int       `json:"element_set_no"`
+	Inclination        float64   `json:"inclination"`
+	RAAN               float64   `json:"raan"`
+	Eccentricity       float64   `json:"eccentricity"`
+	ArgOfPericenter    float64   `json:"arg_of_pericenter"`
+	MeanAnomaly        float64   `json:"mean_anomaly"`
+	MeanMotion         float64   `json:"mean_motion"`
+	RevolutionsAtEpoch int       `json:"rev_at_epoch"`
+}
+
+// ParseElements reads the orbital elements from the TLE lines of the satellite. Angles are in degrees, mean motion in revolutions per day.
+func ParseElements(sat Satellite) (Elements, error) {
+	line1, line2 := sat.TLELine1, sat.TLELine2
+	if len(line1) != 69 || len(line2) != 69 {
+		return Elements{}, fmt.Errorf("TLE lines for %v have wrong format, expected 69 characters", sat.SatelliteName)
+	}
+
+	var el Elements
+	var err error
+
+	if el.NORADID, err = strconv.Atoi(strings.TrimSpace(line1[2:7])); err != nil {
+		return Elements{}, fmt.Errorf("invalid NORAD ID on line 1: %v", line1[2:7])
+	}
+	el.Classification = line1[7:8]
+	el.InternationalDesig = strings.TrimSpace(line1[9:17])
+
+	if el.Epoch, err = parseEpoch(line1[18:32]); err != nil {
+		return Elements{}, err
+	}
+
+	if el.MeanMotionDot, err = strconv.ParseFloat(strings.TrimSpace(line1[33:43]), 64); err != nil {
+		return Elements{}, fmt.Errorf("invalid mean motion first derivative: %v", line1[33:43])
+	}
+	if el.MeanMotionDDot, err = parseImpliedDecimal(line1[44:52]); err != nil {
+		return Elements{}, fmt.Errorf("invalid mean motion second derivative: %v", line1[44:52])
+	}
+	if el.BStar, err = parseImpliedDecimal(line1[53:61]); err != nil {
+		return Elements{}, fmt.Errorf("invalid BSTAR drag term: %v", line1[53:61])
+	}
+	if el.ElementSetNo, err = strconv.Atoi(strings.TrimSpace(line1[64:68])); err != nil {
+		return Elements{}, fmt.Errorf("invalid element set number: %v", line1[64:68])
+	}
+
+	fields := []struct {
+		value *float64
+		raw   string
+		name  string
+	}{
+		{&el.Inclination, line2[8:16], "inclination"},
+		{&el.RAAN, line2[17:25], "right ascension of the ascending node"},
+		{&el.ArgOfPericenter, line2[34:42], "argument of pericenter"},
+		{&el.MeanAnomaly, line2[43:51], "mean anomaly"},
+		{&el.MeanMotion, line2[52:63], "mean motion"},
+	}
+	for _, field := range fields {
+		if *field.value, err = strconv.ParseFloat(strings.TrimSpace(field.raw), 64); err != nil {
+			return Elements{}, fmt.Errorf("invalid %s: %v", field.name, field.raw)
+		}
+	}
+
+	if el.Eccentricity, err = strconv.ParseFloat("0."+strings.TrimSpace(line2[26:33]), 64); err != nil {
+		return Elements{}, fmt.Errorf("invalid eccentricity: %v", line2[26:33])
+	}
+	if el.RevolutionsAtEpoch, err = strconv.Atoi(strings.TrimSpace(line2[63:68])); err != nil {
+		return Elements{}, fmt.Errorf("invalid revolution number: %v", line2[63:68])
+	}
+
+	return el, nil
+}
+
+// SemiMajorAxis semi-major axis in km, derived from the mean motion
+func (el Elements) SemiMajorAxis() float64 {
+	n := el.MeanMotion * 2 * math.Pi / 86400
+	if n <= 0 {
+		return 0
+	}
+	return math.Cbrt(EarthMu / (n * n))
+}
+
+// Apogee apogee altitude in km above the Earth equatorial radius
+func (el Elements) Apogee() float64 {
+	return el.SemiMajorAxis()*(1+el.Eccentricity) - EarthRadius
+}
+
+// Perigee perigee altitude in km above the Earth equatorial radius
+func (el Elements) Perigee() float64 {
+	return el.SemiMajorAxis()*(1-el.Eccentricity) - EarthRadius
+}
+
+// Period orbital period
+func (el Elements) Period() time.Duration {
+	if el.MeanMotion <= 0 {
+		return 0
+	}
+	return time.Duration(86400 / el.MeanMotion * float64(time.Second))
+}
+
+// parseEpoch converts the TLE epoch (YYDDD.DDDDDDDD) to a time, years 57 to 99 being in the 20th century
+func parseEpoch(epoch string) (time.Time, error) {
+	epoch = strings.TrimSpace(epoch)
+	if len(epoch) < 5 {
+		return time.Time{}, fmt.Errorf("invalid epoch: %v", epoch)
+	}
+
+	year, err := strconv.Atoi(epoch[0:2])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid epoch year: %v", epoch)
+	}
+	if year < 57 {
+		year += 2000
+	} else {
+		year += 1900
+	}
+
+	dayOfYear, err := strconv.ParseFloat(epoch[2:], 64)
+	if err != nil || dayOfYear < 1 || dayOfYear >= 367 {
+		return time.Time{}, fmt.Errorf("invalid epoch day of year: %v", epoch)
+	}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return start.Add(time.Duration((dayOfYear - 1) * 86400 * float64(time.Second))).Round(time.Microsecond), nil
+}
+
+// parseImpliedDecimal parses the TLE exponential notation with implied leading decimal point, for example "-11606-4" for -0.11606e-4
+func parseImpliedDecimal(field string) (float64, error) {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return 0, nil
+	}
+
+	sign := 1.0
+	if field[0] == '-' || field[0] == '+' {
+		if field[0] == '-' {
+			sign = -1
+		}
+		field = field[1:]
+	}
+
+	expIndex := strings.LastIndexAny(field, "+-")
+	if expIndex <= 0 {
+		return 0, fmt.Errorf("missing exponent in %v", field)
+	}
+
+	mantissa, err := strconv.ParseFloat("0."+field[:expIndex], 64)
+	if err != nil {
+		return 0, err
+	}
+	exponent, err := strconv.Atoi(field[expIndex:])
+	if err != nil {
+		return 0, err
+	}
+
+	return sign * mantissa * math.Pow10(exponent), nil
+}
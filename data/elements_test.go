@@ -0,0 +1,89 @@
+package data
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestParseElements(t *testing.T) {
+	tests := []struct {
+		name    string
+		sat     Satellite
+		want    Elements
+		wantErr bool
+	}{
+		{
+			name: "ONEWEB-0012",
+			sat: Satellite{
+				SatelliteName: "ONEWEB-0012",
+				NORADID:       44057,
+				TLELine1:      "1 44057U 19010A   22206.81764082 -.00000043  00000+0 -14585-3 0  9993",
+				TLELine2:      "2 44057  87.9150 151.8950 0002369 106.7932 253.3459 13.16592117164401",
+			},
+			want: Elements{
+				NORADID:            44057,
+				Classification:     "U",
+				InternationalDesig: "19010A",
+				Epoch:              time.Date(2022, time.July, 25, 19, 37, 24, 166848000, time.UTC),
+				MeanMotionDot:      -0.00000043,
+				MeanMotionDDot:     0,
+				BStar:              -0.14585e-3,
+				ElementSetNo:       999,
+				Inclination:        87.9150,
+				RAAN:               151.8950,
+				Eccentricity:       0.0002369,
+				ArgOfPericenter:    106.7932,
+				MeanAnomaly:        253.3459,
+				MeanMotion:         13.16592117,
+				RevolutionsAtEpoch: 16440,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid line length",
+			sat: Satellite{
+				TLELine1: "1 44057U 19010A",
+				TLELine2: "2 44057  87.9150 151.8950 0002369 106.7932 253.3459 13.16592117164401",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid inclination",
+			sat: Satellite{
+				TLELine1: "1 44057U 19010A   22206.81764082 -.00000043  00000+0 -14585-3 0  9993",
+				TLELine2: "2 44057  87.9X50 151.8950 0002369 106.7932 253.3459 13.16592117164401",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseElements(tt.sat)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseElements() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Epoch.Equal(tt.want.Epoch) {
+				t.Errorf("ParseElements() epoch = %v, want %v", got.Epoch, tt.want.Epoch)
+			}
+			got.Epoch, tt.want.Epoch = time.Time{}, time.Time{}
+			if math.Abs(got.BStar-tt.want.BStar) > 1e-12 {
+				t.Errorf("ParseElements() bstar = %v, want %v", got.BStar, tt.want.BStar)
+			}
+			got.BStar, tt.want.BStar = 0, 0
+			if got != tt.want {
+				t.Errorf("ParseElements() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestElements_SemiMajorAxis(t *testing.T) {
+	el := Elements{MeanMotion: 1.00273791}
+	if got := el.SemiMajorAxis(); math.Abs(got-42164.2) > 1 {
+		t.Errorf("SemiMajorAxis() = %v, want about 42164 km for a geostationary orbit", got)
+	}
+}
@@ -0,0 +1,323 @@
+package data
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Funkit/tle-provider/frames"
+)
+
+// EOP data origins
+const (
+	EOPSourceNone    = "none"
+	EOPSourceBuiltin = "builtin"
+	EOPSourceFile    = "file"
+	EOPSourceURL     = "url"
+)
+
+// Names of the cached files in the cache directory
+const (
+	finalsCacheFile      = "finals.txt"
+	leapSecondsCacheFile = "leap_seconds.txt"
+)
+
+// EOPStatus origin and validity of the Earth orientation parameters and leap seconds
+type EOPStatus struct {
+	Finals      FinalsStatus      `json:"finals"`
+	LeapSeconds LeapSecondsStatus `json:"leap_seconds"`
+}
+
+func (es EOPStatus) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// FinalsStatus dates of the Earth orientation parameters, valid when they cover the current date
+type FinalsStatus struct {
+	Source       string     `json:"source"`
+	Location     string     `json:"location,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+	Start        *time.Time `json:"start,omitempty"`
+	End          *time.Time `json:"end,omitempty"`
+	LastObserved *time.Time `json:"last_observed,omitempty"`
+	Valid        bool       `json:"valid"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// LeapSecondsStatus last leap second, the table being valid until the expiration date of its file
+type LeapSecondsStatus struct {
+	Source    string            `json:"source"`
+	Location  string            `json:"location,omitempty"`
+	UpdatedAt *time.Time        `json:"updated_at,omitempty"`
+	Latest    frames.LeapSecond `json:"latest"`
+	Expires   *time.Time        `json:"expires,omitempty"`
+	Valid     bool              `json:"valid"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// eopDataset local file or URL of Earth orientation data, with the result of its last refresh
+type eopDataset struct {
+	file      string
+	url       string
+	cache     string
+	updatedAt time.Time
+	err       error
+}
+
+func (d *eopDataset) configured() bool {
+	return d.file != "" || d.url != ""
+}
+
+// source origin and location of the dataset, without the query parameters of the URL which may contain API keys
+func (d *eopDataset) source() (string, string) {
+	switch {
+	case d.file != "":
+		return EOPSourceFile, d.file
+	case d.url != "":
+		return EOPSourceURL, origin(d.url)
+	default:
+		return EOPSourceNone, ""
+	}
+}
+
+func (d *eopDataset) updated() *time.Time {
+	if d.updatedAt.IsZero() {
+		return nil
+	}
+	updatedAt := d.updatedAt
+	return &updatedAt
+}
+
+func (d *eopDataset) errorMessage() string {
+	if d.err == nil {
+		return ""
+	}
+	return d.err.Error()
+}
+
+// EOPSource loads the Earth orientation parameters and the leap seconds from local files or URLs, and keeps the last valid data
+// when a refresh fails. The leap seconds replace the ones used by the frame conversions.
+type EOPSource struct {
+	fetcher     *httpFetcher
+	mu          sync.RWMutex
+	finals      eopDataset
+	leapSeconds eopDataset
+	table       *frames.EOPTable
+	leapTable   *frames.LeapSecondTable
+}
+
+// NewEOPSource validates the configuration, and loads the data previously downloaded to the cache directory so that the
+// conversions do not depend on the network
+func NewEOPSource(config EOPConfiguration) (*EOPSource, error) {
+	if config.FinalsFile != "" && config.FinalsURL != "" {
+		return nil, fmt.Errorf("only one of finals_file and finals_url can be set")
+	}
+	if config.LeapSecondsFile != "" && config.LeapSecondsURL != "" {
+		return nil, fmt.Errorf("only one of leap_seconds_file and leap_seconds_url can be set")
+	}
+	for _, u := range []string{config.FinalsURL, config.LeapSecondsURL} {
+		if u == "" {
+			continue
+		}
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return nil, redactURL(err)
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return nil, fmt.Errorf("invalid URL %v: only http and https are supported", origin(u))
+		}
+	}
+
+	fetcher, err := newHTTPFetcher(config.HTTP)
+	if err != nil {
+		return nil, err
+	}
+	es := &EOPSource{
+		fetcher:     fetcher,
+		finals:      eopDataset{file: config.FinalsFile, url: config.FinalsURL},
+		leapSeconds: eopDataset{file: config.LeapSecondsFile, url: config.LeapSecondsURL},
+	}
+
+	if config.CacheDirectory != "" {
+		if err := os.MkdirAll(config.CacheDirectory, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create the EOP cache directory: %w", err)
+		}
+		if es.finals.url != "" {
+			es.finals.cache = filepath.Join(config.CacheDirectory, finalsCacheFile)
+		}
+		if es.leapSeconds.url != "" {
+			es.leapSeconds.cache = filepath.Join(config.CacheDirectory, leapSecondsCacheFile)
+		}
+	}
+	es.loadCache(&es.finals, es.applyFinals)
+	es.loadCache(&es.leapSeconds, es.applyLeapSeconds)
+
+	return es, nil
+}
+
+// loadCache applies the cached content of a dataset, if any, dated with the modification time of the cache file
+func (es *EOPSource) loadCache(d *eopDataset, apply func([]byte) error) {
+	if d.cache == "" {
+		return
+	}
+	info, err := os.Stat(d.cache)
+	if err != nil {
+		return
+	}
+	content, err := os.ReadFile(d.cache)
+	if err == nil {
+		err = apply(content)
+	}
+	if err != nil {
+		log.Printf("invalid EOP cache file %s: %v\n", d.cache, err)
+		return
+	}
+	d.updatedAt = info.ModTime().UTC()
+}
+
+// Refresh reads the local files and downloads the URLs again. The data which cannot be loaded keeps its previous value,
+// and the errors are reported in the status.
+func (es *EOPSource) Refresh() error {
+	finalsErr := es.refresh(&es.finals, es.applyFinals)
+	leapSecondsErr := es.refresh(&es.leapSeconds, es.applyLeapSeconds)
+	if finalsErr != nil {
+		return fmt.Errorf("failed to load the EOPs: %w", finalsErr)
+	}
+	if leapSecondsErr != nil {
+		return fmt.Errorf("failed to load the leap seconds: %w", leapSecondsErr)
+	}
+	return nil
+}
+
+func (es *EOPSource) refresh(d *eopDataset, apply func([]byte) error) error {
+	if !d.configured() {
+		return nil
+	}
+
+	var content []byte
+	var err error
+	if d.file != "" {
+		content, err = os.ReadFile(d.file)
+	} else {
+		content, err = es.fetcher.fetch(d.url)
+		if errors.Is(err, ErrNotModified) {
+			es.mu.Lock()
+			d.err = nil
+			es.mu.Unlock()
+			return nil
+		}
+	}
+	if err == nil {
+		if err = apply(content); err != nil && d.url != "" {
+			// Download the data again next time, instead of getting a not modified response
+			es.fetcher.forget(d.url)
+		}
+	}
+	if err == nil && d.cache != "" {
+		err = writeCacheFile(d.cache, content)
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	d.err = err
+	if err == nil {
+		d.updatedAt = time.Now().UTC()
+	}
+	return err
+}
+
+// writeCacheFile replaces the cache file atomically, so that an interrupted write does not corrupt it
+func writeCacheFile(path string, content []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write the cache file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write the cache file: %w", err)
+	}
+	return nil
+}
+
+func (es *EOPSource) applyFinals(content []byte) error {
+	table, err := frames.ParseFinals(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	es.mu.Lock()
+	es.table = table
+	es.mu.Unlock()
+	return nil
+}
+
+func (es *EOPSource) applyLeapSeconds(content []byte) error {
+	table, err := frames.ParseLeapSeconds(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	es.mu.Lock()
+	es.leapTable = table
+	es.mu.Unlock()
+	frames.SetLeapSeconds(table)
+	return nil
+}
+
+// EOP returns the last loaded Earth orientation parameters, nil when none could be loaded
+func (es *EOPSource) EOP() *frames.EOPTable {
+	if es == nil {
+		return nil
+	}
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+	return es.table
+}
+
+// Status returns the origin and validity of the data at the given time. Without source, the EOPs are missing
+// and the built-in leap seconds are used.
+func (es *EOPSource) Status(now time.Time) EOPStatus {
+	status := EOPStatus{
+		Finals:      FinalsStatus{Source: EOPSourceNone},
+		LeapSeconds: LeapSecondsStatus{Source: EOPSourceBuiltin},
+	}
+	if es == nil {
+		status.LeapSeconds.Latest = frames.LeapSeconds().Latest()
+		return status
+	}
+
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+
+	status.Finals.Source, status.Finals.Location = es.finals.source()
+	status.Finals.UpdatedAt = es.finals.updated()
+	status.Finals.Error = es.finals.errorMessage()
+	if es.table != nil {
+		start, end, observed := es.table.Start(), es.table.End(), es.table.LastObserved()
+		status.Finals.Start, status.Finals.End = &start, &end
+		if !observed.IsZero() {
+			status.Finals.LastObserved = &observed
+		}
+		status.Finals.Valid = !now.Before(start) && !now.After(end)
+	}
+
+	leapTable := frames.LeapSeconds()
+	if es.leapTable != nil {
+		leapTable = es.leapTable
+		status.LeapSeconds.Source, status.LeapSeconds.Location = es.leapSeconds.source()
+		status.LeapSeconds.UpdatedAt = es.leapSeconds.updated()
+	}
+	status.LeapSeconds.Error = es.leapSeconds.errorMessage()
+	status.LeapSeconds.Latest = leapTable.Latest()
+	if !leapTable.Expires.IsZero() {
+		expires := leapTable.Expires
+		status.LeapSeconds.Expires = &expires
+		status.LeapSeconds.Valid = now.Before(expires)
+	}
+
+	return status
+}
@@ -1,22 +1,57 @@
 package data
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"fmt"
-	"strconv"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/Funkit/go-utils/utils"
+	"github.com/fsnotify/fsnotify"
 )
 
 type FileSource struct {
-	filePath string
+	filePath      string
+	pattern       string
+	format        string
+	mu            sync.Mutex
+	files         []string
+	rejected      []SatelliteErr
+	watcher       *fsnotify.Watcher
+	changes       chan struct{}
+	skipUnchanged bool
+	lastHash      []byte
 }
 
 func NewFileSource(filePath string) *FileSource {
 	return &FileSource{
 		filePath: filePath,
+		format:   FormatAuto,
 	}
 }
 
+// NewGlobFileSource generates a FileSource merging all the files matching the glob pattern, each satellite being tagged with its origin file.
+// The matching files are listed again at each GetData call, so that new and removed files are taken into account.
+func NewGlobFileSource(pattern string) *FileSource {
+	return &FileSource{
+		pattern: pattern,
+		format:  FormatAuto,
+	}
+}
+
+// SetFormat forces the format of the source files. With FormatAuto, the format of each file is detected from its extension or content.
+func (fs *FileSource) SetFormat(format string) error {
+	if !IsValidFormat(format) {
+		return fmt.Errorf("unsupported file format %v", format)
+	}
+	fs.format = format
+	return nil
+}
+
 func (fs *FileSource) GetData() ([]Satellite, error) {
 	tleList, err := fs.extractSatelliteData()
 	if err != nil {
@@ -33,56 +68,239 @@ func (fs *FileSource) GetConfig() (map[string]interface{}, error) {
 	return nil, nil
 }
 
-func (fs *FileSource) extractSatelliteData() ([]Satellite, error) {
-	fileLines, err := utils.GetFileAsLines(fs.filePath)
+// Rejected return the records which could not be parsed during the last GetData call
+func (fs *FileSource) Rejected() []SatelliteErr {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.rejected
+}
+
+// Changes return the channel signaling file changes, nil if Watch was not called
+func (fs *FileSource) Changes() <-chan struct{} {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return fs.changes
+}
+
+// Watch enables the change notifications on the source file(s). A notification is sent once no event was received for the debounce duration,
+// so that files being written are not read partially. In watch mode, GetData returns ErrNotModified when the content of the files did not change.
+func (fs *FileSource) Watch(debounce time.Duration) error {
+	dir := filepath.Dir(fs.filePath)
+	if fs.pattern != "" {
+		dir = filepath.Dir(fs.pattern)
+	}
+	if strings.ContainsAny(dir, `*?[`) {
+		return fmt.Errorf("cannot watch %s: the directory part of the pattern must not contain wildcards", fs.pattern)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		return err
 	}
 
-	var output []Satellite
+	fs.mu.Lock()
+	fs.watcher = watcher
+	fs.changes = make(chan struct{}, 1)
+	fs.skipUnchanged = true
+	fs.mu.Unlock()
+
+	go fs.watch(watcher, debounce)
+
+	return nil
+}
+
+// Close stops watching the source file(s)
+func (fs *FileSource) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.watcher == nil {
+		return nil
+	}
+	err := fs.watcher.Close()
+	fs.watcher = nil
+	return err
+}
 
-	i := 0
-	for i+2 < len(fileLines) {
-		if len(fileLines[i+1]) != 69 {
-			return nil, fmt.Errorf("TLE line 1 for item %v has wrong format, expected 69 characters, got %v", i, len(fileLines[i+1]))
+func (fs *FileSource) watch(watcher *fsnotify.Watcher, debounce time.Duration) {
+	var timer *time.Timer
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if !fs.isSourceFile(event.Name) {
+				continue
+			}
+			if timer == nil {
+				timer = time.AfterFunc(debounce, fs.notify)
+			} else {
+				timer.Reset(debounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("file source watcher: %v\n", err)
 		}
-		if len(fileLines[i+2]) != 69 {
-			return nil, fmt.Errorf("TLE line 2 for item %v has wrong format, expected 69 characters, got %v", i, len(fileLines[i+2]))
+	}
+}
+
+// notify signals a change, without blocking if a change is already pending
+func (fs *FileSource) notify() {
+	select {
+	case fs.changes <- struct{}{}:
+	default:
+	}
+}
+
+// isSourceFile returns true if the path is the source file or matches the source pattern
+func (fs *FileSource) isSourceFile(path string) bool {
+	path = filepath.Clean(path)
+	if fs.pattern == "" {
+		return path == filepath.Clean(fs.filePath)
+	}
+	matched, err := filepath.Match(filepath.Clean(fs.pattern), path)
+	return err == nil && matched
+}
+
+func (fs *FileSource) extractSatelliteData() ([]Satellite, error) {
+	files := []string{fs.filePath}
+	if fs.pattern != "" {
+		var err error
+		if files, err = fs.matchingFiles(); err != nil {
+			return nil, err
+		}
+	}
+
+	var rejected []SatelliteErr
+	var readFiles []string
+	var contents [][]byte
+	hash := sha256.New()
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			if fs.pattern == "" {
+				return nil, err
+			}
+			rejected = append(rejected, SatelliteErr{Err: err, Sat: Satellite{Origin: file}})
+			continue
 		}
+		readFiles = append(readFiles, file)
+		contents = append(contents, content)
+		hash.Write([]byte(file))
+		hash.Write(content)
+	}
 
-		noradID, err := strconv.Atoi(fileLines[i+2][2:7])
+	sum := hash.Sum(nil)
+	fs.mu.Lock()
+	unchanged := fs.skipUnchanged && bytes.Equal(sum, fs.lastHash)
+	fs.lastHash = sum
+	fs.mu.Unlock()
+	if unchanged {
+		return nil, ErrNotModified
+	}
+
+	var output []Satellite
+	for i, file := range readFiles {
+		entries, err := expandContent(file, contents[i])
 		if err != nil {
-			return nil, fmt.Errorf("error parsing the NORAD ID from the first TLE line: %v could not be cast as an int", fileLines[i+1][2:6])
+			if fs.pattern == "" {
+				return nil, err
+			}
+			rejected = append(rejected, SatelliteErr{Err: err, Sat: Satellite{Origin: file}})
+			continue
 		}
 
-		output = append(output, Satellite{
-			SatelliteName: removeTrailingSpaces(fileLines[i]),
-			NORADID:       noradID,
-			TLELine1:      fileLines[i+1],
-			TLELine2:      fileLines[i+2],
-		})
-		i = i + 3
+		for _, entry := range entries {
+			sats, entryRejected, err := parseContent(fs.format, entry.name, entry.content)
+			if err != nil {
+				if fs.pattern == "" && len(entries) == 1 {
+					return nil, err
+				}
+				rejected = append(rejected, SatelliteErr{Err: fmt.Errorf("%s: %w", entry.name, err), Sat: Satellite{Origin: entry.name}})
+				continue
+			}
+
+			if fs.pattern != "" {
+				for i := range sats {
+					sats[i].Origin = entry.name
+				}
+				for i := range entryRejected {
+					entryRejected[i].Sat.Origin = entry.name
+				}
+			}
+			output = append(output, sats...)
+			rejected = append(rejected, entryRejected...)
+		}
 	}
 
+	fs.mu.Lock()
+	fs.rejected = rejected
+	fs.mu.Unlock()
+
 	return output, nil
 }
 
-func removeTrailingSpaces(source string) string {
+// matchingFiles lists the regular files matching the pattern, logging the files added or removed since the last call
+func (fs *FileSource) matchingFiles() ([]string, error) {
+	matches, err := filepath.Glob(fs.pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, match)
+	}
 
-	j := len(source) - 1
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no file matching %s", fs.pattern)
+	}
 
-	for j >= 0 {
-		if source[j] != ' ' {
-			break
+	fs.mu.Lock()
+	previous := make(map[string]struct{}, len(fs.files))
+	for _, file := range fs.files {
+		previous[file] = struct{}{}
+	}
+	for _, file := range files {
+		if _, ok := previous[file]; !ok {
+			log.Printf("file source: loading %s\n", file)
 		}
-		j--
+		delete(previous, file)
 	}
+	for file := range previous {
+		log.Printf("file source: %s removed\n", file)
+	}
+	fs.files = files
+	fs.mu.Unlock()
 
-	if j == 0 {
-		return source
-	} else if j < 0 {
-		return ""
-	} else {
-		return source[0 : j+1]
+	return files, nil
+}
+
+// parseContent reads the satellites from the content of a file, in the given format or in the format detected for the file
+func parseContent(format, path string, content []byte) ([]Satellite, []SatelliteErr, error) {
+	if format == FormatAuto || format == "" {
+		format = DetectFormat(path, content)
+	}
+
+	output, rejected, err := Parse(format, bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, err
 	}
+
+	for i := range rejected {
+		rejected[i].Err = fmt.Errorf("%s: %w", path, rejected[i].Err)
+	}
+
+	return output, rejected, nil
 }
@@ -1,6 +1,9 @@
 package data
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"testing"
@@ -78,49 +81,144 @@ func TestFileSource_extractSatelliteData(t *testing.T) {
 	}
 }
 
-func Test_removeTrailingSpaces(t *testing.T) {
-	type args struct {
-		source string
+func TestGlobFileSource(t *testing.T) {
+	fs := NewGlobFileSource("../samples/directory/*")
+
+	got, err := fs.GetData()
+	if err != nil {
+		t.Fatalf("GetData() error = %v", err)
 	}
-	tests := []struct {
-		name string
-		args args
-		want string
+
+	wantOrigins := map[string]string{
+		"ONEWEB-0012":        filepath.Join("..", "samples", "directory", "oneweb.json"),
+		"ONEWEB-0010":        filepath.Join("..", "samples", "directory", "oneweb.json"),
+		"STARLINK-71":        filepath.Join("..", "samples", "directory", "starlink.xml"),
+		"OPS 5712 (P/L 153)": filepath.Join("..", "samples", "directory", "various.txt"),
+		"CALSPHERE 1":        filepath.Join("..", "samples", "directory", "various.txt"),
+		"LAGEOS 1":           filepath.Join("..", "samples", "directory", "various.txt"),
+	}
+	if len(got) != len(wantOrigins) {
+		t.Fatalf("GetData() got %v satellites, want %v", len(got), len(wantOrigins))
+	}
+	for _, sat := range got {
+		if sat.Origin != wantOrigins[sat.SatelliteName] {
+			t.Errorf("GetData() origin of %v = %v, want %v", sat.SatelliteName, sat.Origin, wantOrigins[sat.SatelliteName])
+		}
+	}
+	if len(fs.Rejected()) != 0 {
+		t.Errorf("GetData() rejected %v", fs.Rejected())
+	}
+}
+
+func TestGlobFileSource_refresh(t *testing.T) {
+	dir := t.TempDir()
+	fs := NewGlobFileSource(filepath.Join(dir, "*.txt"))
+
+	if _, err := fs.GetData(); err == nil {
+		t.Errorf("GetData() on empty directory must fail")
+	}
+
+	copyFile := func(source, destination string) {
+		content, err := os.ReadFile(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, destination), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	steps := []struct {
+		name   string
+		action func()
+		want   int
 	}{
 		{
-			name: "default case",
-			args: args{
-				source: "CALSPHERE 1             ",
-			},
-			want: "CALSPHERE 1",
+			name:   "new file",
+			action: func() { copyFile("../samples/tle_filesource_testing.txt", "first.txt") },
+			want:   3,
 		},
 		{
-			name: "empty string",
-			args: args{
-				source: "",
-			},
-			want: "",
+			name:   "second file",
+			action: func() { copyFile("../samples/tle_server_testing.txt", "second.txt") },
+			want:   11,
 		},
 		{
-			name: "only spaces",
-			args: args{
-				source: "         ",
-			},
-			want: "",
+			name:   "ignored extension",
+			action: func() { copyFile("../samples/tle_server_testing.txt", "third.bak") },
+			want:   11,
 		},
 		{
-			name: "one character",
-			args: args{
-				source: "a",
-			},
-			want: "a",
+			name:   "removed file",
+			action: func() { os.Remove(filepath.Join(dir, "first.txt")) },
+			want:   8,
 		},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := removeTrailingSpaces(tt.args.source); got != tt.want {
-				t.Errorf("removeTrailingSpaces() = %v, want %v", got, tt.want)
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			step.action()
+			got, err := fs.GetData()
+			if err != nil {
+				t.Fatalf("GetData() error = %v", err)
+			}
+			if len(got) != step.want {
+				t.Errorf("GetData() got %v satellites, want %v", len(got), step.want)
 			}
 		})
 	}
 }
+
+func TestFileSource_Watch(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tle.txt")
+
+	first, err := os.ReadFile("../samples/tle_filesource_testing.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := os.ReadFile("../samples/tle_server_testing.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, first, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := NewFileSource(path)
+	if err := fs.Watch(100 * time.Millisecond); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer fs.Close()
+
+	if got, err := fs.GetData(); err != nil || len(got) != 3 {
+		t.Fatalf("GetData() got %v satellites, error = %v", len(got), err)
+	}
+	if _, err := fs.GetData(); !errors.Is(err, ErrNotModified) {
+		t.Errorf("GetData() on unchanged file error = %v, want ErrNotModified", err)
+	}
+
+	// Write the new content in two steps, a single notification is expected once the writes are over
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write(second[:len(second)/2])
+	time.Sleep(20 * time.Millisecond)
+	file.Write(second[len(second)/2:])
+	file.Close()
+
+	select {
+	case <-fs.Changes():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no change notification received")
+	}
+	select {
+	case <-fs.Changes():
+		t.Errorf("writes not debounced, second notification received")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	if got, err := fs.GetData(); err != nil || len(got) != 8 {
+		t.Errorf("GetData() after change got %v satellites, error = %v", len(got), err)
+	}
+}
@@ -0,0 +1,81 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Formats supported by the file source
+const (
+	FormatAuto    = "auto"
+	FormatTLE     = "tle"
+	FormatOMMJSON = "omm_json"
+	FormatOMMXML  = "omm_xml"
+	FormatOMMCSV  = "omm_csv"
+)
+
+// parsers parsing function for each format
+var parsers = map[string]func(io.Reader) ([]Satellite, []SatelliteErr, error){
+	FormatTLE:     ParseTLE,
+	FormatOMMJSON: ParseOMMJSON,
+	FormatOMMXML:  ParseOMMXML,
+	FormatOMMCSV:  ParseOMMCSV,
+}
+
+// extensionFormats format associated with each known file extension
+var extensionFormats = map[string]string{
+	".txt":  FormatTLE,
+	".tle":  FormatTLE,
+	".2le":  FormatTLE,
+	".3le":  FormatTLE,
+	".json": FormatOMMJSON,
+	".xml":  FormatOMMXML,
+	".csv":  FormatOMMCSV,
+}
+
+// IsValidFormat returns true if the format is supported, or is FormatAuto
+func IsValidFormat(format string) bool {
+	_, ok := parsers[format]
+	return ok || format == FormatAuto
+}
+
+// DetectFormat selects the format of a file from its extension, or from its content for unknown extensions
+func DetectFormat(path string, content []byte) string {
+	if format, ok := extensionFormats[strings.ToLower(filepath.Ext(path))]; ok {
+		return format
+	}
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return FormatTLE
+	}
+
+	switch trimmed[0] {
+	case '[', '{':
+		return FormatOMMJSON
+	case '<':
+		return FormatOMMXML
+	}
+
+	firstLine := trimmed
+	if end := bytes.IndexByte(trimmed, '\n'); end >= 0 {
+		firstLine = trimmed[:end]
+	}
+	if bytes.Contains(bytes.ToUpper(firstLine), []byte("NORAD_CAT_ID")) && bytes.Contains(firstLine, []byte(",")) {
+		return FormatOMMCSV
+	}
+
+	return FormatTLE
+}
+
+// Parse reads the satellites from r in the given format
+func Parse(format string, r io.Reader) ([]Satellite, []SatelliteErr, error) {
+	parser, ok := parsers[format]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported format %v", format)
+	}
+	return parser(r)
+}
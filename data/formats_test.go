@@ -0,0 +1,116 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{name: "TLE extension", path: "catalog.tle", content: "[", want: FormatTLE},
+		{name: "JSON extension", path: "catalog.JSON", content: "", want: FormatOMMJSON},
+		{name: "CSV extension", path: "catalog.csv", content: "", want: FormatOMMCSV},
+		{name: "JSON array content", path: "catalog", content: "  [{\"OBJECT_NAME\":\"ISS\"}]", want: FormatOMMJSON},
+		{name: "JSON object content", path: "catalog.dat", content: "{\"OBJECT_NAME\":\"ISS\"}", want: FormatOMMJSON},
+		{name: "XML content", path: "catalog.dat", content: "<?xml version=\"1.0\"?><ndm></ndm>", want: FormatOMMXML},
+		{name: "CSV content", path: "catalog.dat", content: "\xef\xbb\xbfOBJECT_NAME,OBJECT_ID,NORAD_CAT_ID\nISS,1998-067A,25544", want: FormatOMMCSV},
+		{name: "TLE content", path: "catalog.dat", content: "ISS (ZARYA)\n1 25544U", want: FormatTLE},
+		{name: "empty content", path: "catalog.dat", content: "", want: FormatTLE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFormat(tt.path, []byte(tt.content)); got != tt.want {
+				t.Errorf("DetectFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileSource_formats(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		format       string
+		wantNames    []string
+		wantRejected int
+		wantErr      bool
+	}{
+		{
+			name:         "CSV with invalid row",
+			path:         "../samples/formats/starlink.csv",
+			format:       FormatAuto,
+			wantNames:    []string{"STARLINK-71"},
+			wantRejected: 1,
+		},
+		{
+			name:      "Space-Track JSON detected from content",
+			path:      "../samples/formats/spacetrack_gp.dat",
+			format:    FormatAuto,
+			wantNames: []string{"ONEWEB-0012"},
+		},
+		{
+			name:      "OMM XML detected from content",
+			path:      "../samples/formats/starlink_omm.dat",
+			format:    FormatAuto,
+			wantNames: []string{"STARLINK-71"},
+		},
+		{
+			name:      "forced format",
+			path:      "../samples/formats/starlink_omm.dat",
+			format:    FormatOMMXML,
+			wantNames: []string{"STARLINK-71"},
+		},
+		{
+			name:      "standalone OMM XML",
+			path:      "../samples/formats/starlink_omm_root.xml",
+			format:    FormatAuto,
+			wantNames: []string{"STARLINK-71"},
+		},
+		{
+			name:    "wrong forced format",
+			path:    "../samples/formats/starlink_omm.dat",
+			format:  FormatOMMJSON,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := NewFileSource(tt.path)
+			if err := fs.SetFormat(tt.format); err != nil {
+				t.Fatalf("SetFormat() error = %v", err)
+			}
+
+			got, err := fs.GetData()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var names []string
+			for _, sat := range got {
+				names = append(names, sat.SatelliteName)
+				if err := ValidateSatellite(sat, time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+					t.Errorf("invalid TLE generated for %v: %v", sat.SatelliteName, err)
+				}
+			}
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("GetData() got %v, want %v", names, tt.wantNames)
+			}
+			if len(fs.Rejected()) != tt.wantRejected {
+				t.Errorf("GetData() rejected %v, want %v records", fs.Rejected(), tt.wantRejected)
+			}
+		})
+	}
+
+	if err := NewFileSource("").SetFormat("pdf"); err == nil {
+		t.Errorf("SetFormat() with unsupported format must fail")
+	}
+}
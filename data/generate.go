@@ -0,0 +1,165 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// KeplerianElements orbital elements used to generate a TLE. The orbit size is given by exactly one of the semi-major axis,
+// the apogee and perigee altitudes, or the mean motion. Angles are in degrees, distances in km, and the elements are used as SGP4 mean elements.
+type KeplerianElements struct {
+	SatelliteName   string    `json:"satellite_name" yaml:"satellite_name"`
+	NORADID         int       `json:"norad_id" yaml:"norad_id"`
+	ObjectID        string    `json:"object_id,omitempty" yaml:"object_id"`
+	Classification  string    `json:"classification,omitempty" yaml:"classification"`
+	Epoch           time.Time `json:"epoch" yaml:"epoch"`
+	SemiMajorAxisKm float64   `json:"semi_major_axis_km,omitempty" yaml:"semi_major_axis_km"`
+	ApogeeKm        float64   `json:"apogee_km,omitempty" yaml:"apogee_km"`
+	PerigeeKm       float64   `json:"perigee_km,omitempty" yaml:"perigee_km"`
+	MeanMotion      float64   `json:"mean_motion,omitempty" yaml:"mean_motion"`
+	Eccentricity    float64   `json:"eccentricity,omitempty" yaml:"eccentricity"`
+	Inclination     float64   `json:"inclination" yaml:"inclination"`
+	RAAN            float64   `json:"raan" yaml:"raan"`
+	ArgOfPericenter float64   `json:"arg_of_pericenter" yaml:"arg_of_pericenter"`
+	MeanAnomaly     float64   `json:"mean_anomaly" yaml:"mean_anomaly"`
+	BStar           float64   `json:"bstar,omitempty" yaml:"bstar"`
+	MeanMotionDot   float64   `json:"mean_motion_dot,omitempty" yaml:"mean_motion_dot"`
+	MeanMotionDDot  float64   `json:"mean_motion_ddot,omitempty" yaml:"mean_motion_ddot"`
+	ElementSetNo    int       `json:"element_set_no,omitempty" yaml:"element_set_no"`
+	RevAtEpoch      int       `json:"rev_at_epoch,omitempty" yaml:"rev_at_epoch"`
+}
+
+func (ke KeplerianElements) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// objectIDPattern international designator format expected in the OBJECT_ID field, for example 1998-067A
+var objectIDPattern = regexp.MustCompile(`^[0-9]{4}-[0-9]{3}[A-Z]{1,3}$`)
+
+// GenerateTLE builds a TLE from the orbital elements, using the same formatting and checksum code as the Celestrak conversion
+func GenerateTLE(ke KeplerianElements) (Satellite, error) {
+	if ke.NORADID <= 0 || ke.NORADID > 99999 {
+		return Satellite{}, fmt.Errorf("NORAD ID %v out of range [1, 99999]", ke.NORADID)
+	}
+	if ke.Epoch.IsZero() {
+		return Satellite{}, fmt.Errorf("epoch is required")
+	}
+	epoch := ke.Epoch.UTC()
+	if epoch.Year() < 1957 || epoch.Year() > 2056 {
+		return Satellite{}, fmt.Errorf("epoch year %v cannot be represented in a TLE", epoch.Year())
+	}
+	if ke.ObjectID != "" && !objectIDPattern.MatchString(ke.ObjectID) {
+		return Satellite{}, fmt.Errorf("invalid object ID %v, expected format YYYY-NNNP", ke.ObjectID)
+	}
+	classification := ke.Classification
+	if classification == "" {
+		classification = "U"
+	}
+	if classification != "U" && classification != "C" && classification != "S" {
+		return Satellite{}, fmt.Errorf("invalid classification %v", classification)
+	}
+
+	meanMotion, eccentricity, err := ke.meanMotion()
+	if err != nil {
+		return Satellite{}, err
+	}
+
+	if ke.Inclination < 0 || ke.Inclination > 180 {
+		return Satellite{}, fmt.Errorf("inclination %v out of range [0, 180]", ke.Inclination)
+	}
+	if math.Abs(ke.BStar) >= 1 || math.Abs(ke.MeanMotionDDot) >= 1 || math.Abs(ke.MeanMotionDot) >= 1 {
+		return Satellite{}, fmt.Errorf("drag terms must be lower than 1 in absolute value")
+	}
+	if ke.ElementSetNo < 0 || ke.ElementSetNo > 9999 || ke.RevAtEpoch < 0 || ke.RevAtEpoch > 99999 {
+		return Satellite{}, fmt.Errorf("element set number or revolution number out of range")
+	}
+
+	name := ke.SatelliteName
+	if name == "" {
+		name = fmt.Sprintf("%d", ke.NORADID)
+	}
+
+	sat, err := convertToTLE(CelestrakData{
+		ObjectName:         name,
+		ObjectID:           ke.ObjectID,
+		Epoch:              epoch.Format("2006-01-02T15:04:05.000000"),
+		MeanMotion:         meanMotion,
+		Eccentricity:       eccentricity,
+		Inclination:        ke.Inclination,
+		RaOfASCMode:        normalizeDegrees(ke.RAAN),
+		ArgOfPericenter:    normalizeDegrees(ke.ArgOfPericenter),
+		MeanAnomaly:        normalizeDegrees(ke.MeanAnomaly),
+		ClassificationType: classification,
+		NORADCatID:         ke.NORADID,
+		ElementSetNo:       ke.ElementSetNo,
+		RevAtEpoch:         ke.RevAtEpoch,
+		BStar:              ke.BStar,
+		MeanMotionDOT:      ke.MeanMotionDot,
+		MeanMotionDDOT:     ke.MeanMotionDDot,
+	})
+	if err != nil {
+		return Satellite{}, err
+	}
+
+	return sat, nil
+}
+
+// meanMotion returns the mean motion in revolutions per day and the eccentricity, from the semi-major axis, the apogee and perigee, or the mean motion
+func (ke KeplerianElements) meanMotion() (float64, float64, error) {
+	sizes := 0
+	for _, given := range []bool{ke.SemiMajorAxisKm != 0, ke.ApogeeKm != 0 || ke.PerigeeKm != 0, ke.MeanMotion != 0} {
+		if given {
+			sizes++
+		}
+	}
+	if sizes != 1 {
+		return 0, 0, fmt.Errorf("exactly one of semi-major axis, apogee and perigee, or mean motion is required")
+	}
+
+	eccentricity := ke.Eccentricity
+	semiMajorAxis := ke.SemiMajorAxisKm
+	if ke.ApogeeKm != 0 || ke.PerigeeKm != 0 {
+		if ke.Eccentricity != 0 {
+			return 0, 0, fmt.Errorf("eccentricity cannot be given with apogee and perigee")
+		}
+		if ke.ApogeeKm < ke.PerigeeKm {
+			return 0, 0, fmt.Errorf("apogee %v km is below perigee %v km", ke.ApogeeKm, ke.PerigeeKm)
+		}
+		apogeeRadius := ke.ApogeeKm + EarthRadius
+		perigeeRadius := ke.PerigeeKm + EarthRadius
+		semiMajorAxis = (apogeeRadius + perigeeRadius) / 2
+		eccentricity = (apogeeRadius - perigeeRadius) / (apogeeRadius + perigeeRadius)
+	}
+
+	if eccentricity < 0 || math.Round(eccentricity*1e7) >= 1e7 {
+		return 0, 0, fmt.Errorf("eccentricity %v out of range [0, 1[", eccentricity)
+	}
+
+	meanMotion := ke.MeanMotion
+	if semiMajorAxis != 0 {
+		n := math.Sqrt(EarthMu / (semiMajorAxis * semiMajorAxis * semiMajorAxis))
+		meanMotion = n * 86400 / (2 * math.Pi)
+	}
+	if meanMotion <= 0 || meanMotion >= 100 {
+		return 0, 0, fmt.Errorf("mean motion %v rev/day out of range ]0, 100[", meanMotion)
+	}
+
+	perigee := Elements{MeanMotion: meanMotion, Eccentricity: eccentricity}.Perigee()
+	if perigee <= 0 {
+		return 0, 0, fmt.Errorf("perigee altitude %.1f km is below the Earth surface", perigee)
+	}
+
+	return meanMotion, eccentricity, nil
+}
+
+// normalizeDegrees wraps an angle to [0, 360[
+func normalizeDegrees(angle float64) float64 {
+	angle = math.Mod(angle, 360)
+	if angle < 0 {
+		angle += 360
+	}
+	return angle
+}
@@ -0,0 +1,104 @@
+package data
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGenerateTLE(t *testing.T) {
+	// LAGEOS 1 elements from samples/tle_filesource_testing.txt
+	lageosEpoch := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(205.68532073 * 86400 * float64(time.Second))).Round(time.Microsecond)
+	lageosElements := KeplerianElements{
+		SatelliteName:   "LAGEOS 1",
+		NORADID:         8820,
+		ObjectID:        "1976-039A",
+		Epoch:           lageosEpoch,
+		MeanMotion:      6.38664901,
+		Eccentricity:    0.0045094,
+		Inclination:     109.8533,
+		RAAN:            52.0899,
+		ArgOfPericenter: 246.5947,
+		MeanAnomaly:     308.4924,
+		MeanMotionDot:   0.00000028,
+		ElementSetNo:    999,
+		RevAtEpoch:      82229,
+	}
+
+	got, err := GenerateTLE(lageosElements)
+	if err != nil {
+		t.Fatalf("GenerateTLE() error = %v", err)
+	}
+	want := Satellite{
+		SatelliteName: "LAGEOS 1",
+		NORADID:       8820,
+		TLELine1:      "1 08820U 76039A   22206.68532073  .00000028  00000-0  00000-0 0  9999",
+		TLELine2:      "2 08820 109.8533  52.0899 0045094 246.5947 308.4924  6.38664901822297",
+	}
+	if got != want {
+		t.Errorf("GenerateTLE() got\n%v\n%v\nwant\n%v\n%v", got.TLELine1, got.TLELine2, want.TLELine1, want.TLELine2)
+	}
+
+	epoch := time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name              string
+		elements          KeplerianElements
+		wantSemiMajorAxis float64
+		wantEccentricity  float64
+		wantErr           bool
+	}{
+		{
+			name:              "semi-major axis",
+			elements:          KeplerianElements{SatelliteName: "PLANNED-1", NORADID: 99901, Epoch: epoch, SemiMajorAxisKm: 6928.137, Eccentricity: 0.001, Inclination: 97.5, RAAN: -10, MeanAnomaly: 370},
+			wantSemiMajorAxis: 6928.137,
+			wantEccentricity:  0.001,
+		},
+		{
+			name:              "apogee and perigee",
+			elements:          KeplerianElements{NORADID: 99902, ObjectID: "2026-999A", Epoch: epoch, ApogeeKm: 35786, PerigeeKm: 250, Inclination: 6, BStar: 0.00012345},
+			wantSemiMajorAxis: EarthRadius + (35786+250)/2,
+			wantEccentricity:  (35786 - 250) / (2*EarthRadius + 35786 + 250),
+		},
+		{"no orbit size", KeplerianElements{NORADID: 99903, Epoch: epoch, Inclination: 50}, 0, 0, true},
+		{"several orbit sizes", KeplerianElements{NORADID: 99903, Epoch: epoch, SemiMajorAxisKm: 7000, MeanMotion: 15}, 0, 0, true},
+		{"orbit below the surface", KeplerianElements{NORADID: 99903, Epoch: epoch, SemiMajorAxisKm: 6000}, 0, 0, true},
+		{"apogee below perigee", KeplerianElements{NORADID: 99903, Epoch: epoch, ApogeeKm: 200, PerigeeKm: 400}, 0, 0, true},
+		{"hyperbolic orbit", KeplerianElements{NORADID: 99903, Epoch: epoch, SemiMajorAxisKm: 7000, Eccentricity: 1.2}, 0, 0, true},
+		{"invalid inclination", KeplerianElements{NORADID: 99903, Epoch: epoch, MeanMotion: 15, Inclination: 200}, 0, 0, true},
+		{"invalid NORAD ID", KeplerianElements{NORADID: 100000, Epoch: epoch, MeanMotion: 15}, 0, 0, true},
+		{"invalid object ID", KeplerianElements{NORADID: 99903, ObjectID: "26999A", Epoch: epoch, MeanMotion: 15}, 0, 0, true},
+		{"missing epoch", KeplerianElements{NORADID: 99903, MeanMotion: 15}, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GenerateTLE(tt.elements)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateTLE() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if err := ValidateSatellite(got, epoch); err != nil {
+				t.Fatalf("generated TLE is invalid: %v\n%v\n%v", err, got.TLELine1, got.TLELine2)
+			}
+			el, err := ParseElements(got)
+			if err != nil {
+				t.Fatalf("ParseElements() error = %v", err)
+			}
+			if !el.Epoch.Equal(epoch) {
+				t.Errorf("epoch = %v, want %v", el.Epoch, epoch)
+			}
+			if math.Abs(el.SemiMajorAxis()-tt.wantSemiMajorAxis) > 0.01 {
+				t.Errorf("semi-major axis = %v, want %v", el.SemiMajorAxis(), tt.wantSemiMajorAxis)
+			}
+			if math.Abs(el.Eccentricity-tt.wantEccentricity) > 1e-7 {
+				t.Errorf("eccentricity = %v, want %v", el.Eccentricity, tt.wantEccentricity)
+			}
+			if el.RAAN < 0 || el.RAAN >= 360 || el.MeanAnomaly < 0 || el.MeanAnomaly >= 360 {
+				t.Errorf("angles not normalized: RAAN %v, mean anomaly %v", el.RAAN, el.MeanAnomaly)
+			}
+		})
+	}
+}
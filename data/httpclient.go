@@ -0,0 +1,360 @@
+package data
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHTTPTimeout    = 60 * time.Second
+	defaultMaxRetries     = 3
+	defaultRetryBackoff   = 2 * time.Second
+	defaultMaxBackoff     = 2 * time.Minute
+	maxRateLimitBackoff   = 24 * time.Hour
+	maxResponseBodyLength = 1 << 30
+)
+
+// HTTPConfiguration outbound HTTP settings of the sources querying data over HTTP.
+// MaxRetries is a pointer so that 0 disables the retries, the default being used when it is not set.
+type HTTPConfiguration struct {
+	TimeoutSeconds            int               `yaml:"timeout_seconds"`
+	MaxRetries                *int              `yaml:"max_retries"`
+	RetryBackoffSeconds       int               `yaml:"retry_backoff_seconds"`
+	MaxBackoffSeconds         int               `yaml:"max_backoff_seconds"`
+	MinRequestIntervalSeconds int               `yaml:"min_request_interval_seconds"`
+	RateLimitBackoffSeconds   int               `yaml:"rate_limit_backoff_seconds"`
+	ProxyURL                  string            `yaml:"proxy_url"`
+	ProxyUsername             string            `yaml:"proxy_username"`
+	ProxyPassword             string            `yaml:"proxy_password"`
+	CAFile                    string            `yaml:"ca_file"`
+	ClientCertFile            string            `yaml:"client_cert_file"`
+	ClientKeyFile             string            `yaml:"client_key_file"`
+	Headers                   map[string]string `yaml:"headers"`
+}
+
+// errRetryable error of the requests which may succeed if sent again later
+type errRetryable struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e errRetryable) Error() string {
+	return e.err.Error()
+}
+
+// httpFetcher downloads data over HTTP, retrying on transient errors and using conditional requests
+// so that unchanged data is not downloaded again. The requests to the same host can be spaced by a minimum interval,
+// and suspended after a 403 response for servers using it to signal their rate limit.
+type httpFetcher struct {
+	client           *http.Client
+	maxRetries       int
+	backoff          time.Duration
+	maxBackoff       time.Duration
+	minInterval      time.Duration
+	rateLimitBackoff time.Duration
+	headers          map[string]string
+	mu               sync.Mutex
+	etags            map[string]string
+	lastModified     map[string]string
+	lastRequest      map[string]time.Time
+	rateLimited      map[string]rateLimit
+}
+
+// rateLimit suspension of the requests to a host after consecutive rate limit responses
+type rateLimit struct {
+	count int
+	until time.Time
+}
+
+func newHTTPFetcher(config HTTPConfiguration) (*httpFetcher, error) {
+	transport, err := newHTTPTransport(config)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher := &httpFetcher{
+		client:       &http.Client{Timeout: defaultHTTPTimeout, Transport: transport},
+		maxRetries:   defaultMaxRetries,
+		backoff:      defaultRetryBackoff,
+		maxBackoff:   defaultMaxBackoff,
+		headers:      config.Headers,
+		etags:        make(map[string]string),
+		lastModified: make(map[string]string),
+		lastRequest:  make(map[string]time.Time),
+		rateLimited:  make(map[string]rateLimit),
+	}
+
+	if config.TimeoutSeconds > 0 {
+		fetcher.client.Timeout = time.Duration(config.TimeoutSeconds) * time.Second
+	}
+	if config.MaxRetries != nil {
+		if *config.MaxRetries < 0 {
+			return nil, fmt.Errorf("max_retries must be positive or zero")
+		}
+		fetcher.maxRetries = *config.MaxRetries
+	}
+	if config.RetryBackoffSeconds > 0 {
+		fetcher.backoff = time.Duration(config.RetryBackoffSeconds) * time.Second
+	}
+	if config.MaxBackoffSeconds > 0 {
+		fetcher.maxBackoff = time.Duration(config.MaxBackoffSeconds) * time.Second
+	}
+	if config.MinRequestIntervalSeconds > 0 {
+		fetcher.minInterval = time.Duration(config.MinRequestIntervalSeconds) * time.Second
+	}
+	if config.RateLimitBackoffSeconds > 0 {
+		fetcher.rateLimitBackoff = time.Duration(config.RateLimitBackoffSeconds) * time.Second
+	}
+
+	return fetcher, nil
+}
+
+// newHTTPTransport generates the transport going through the configured proxy, and using the configured CA bundle and client certificate.
+// Without proxy in the configuration, the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used.
+func newHTTPTransport(config HTTPConfiguration) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		if proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %s: scheme and host are required", config.ProxyURL)
+		}
+		if config.ProxyUsername != "" {
+			proxyURL.User = url.UserPassword(config.ProxyUsername, config.ProxyPassword)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if config.CAFile == "" && config.ClientCertFile == "" && config.ClientKeyFile == "" {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in CA bundle %s", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.ClientCertFile != "" || config.ClientKeyFile != "" {
+		if config.ClientCertFile == "" || config.ClientKeyFile == "" {
+			return nil, fmt.Errorf("both client_cert_file and client_key_file are required for client certificate authentication")
+		}
+		cert, err := tls.LoadX509KeyPair(config.ClientCertFile, config.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
+
+// fetch downloads the content of the URL. ErrNotModified is returned when the server reports that the content did not change
+// since the last successful download. Network errors, 429 and 5xx responses are retried with exponential backoff and jitter,
+// the Retry-After header being respected when present.
+func (f *httpFetcher) fetch(url string) ([]byte, error) {
+	if err := f.checkRateLimit(url); err != nil {
+		return nil, err
+	}
+
+	var err error
+	for attempt := 0; attempt <= f.maxRetries; attempt++ {
+		var body []byte
+		body, err = f.fetchOnce(url)
+		if err == nil || errors.Is(err, ErrNotModified) {
+			return body, err
+		}
+
+		var retryable errRetryable
+		if !errors.As(err, &retryable) || attempt == f.maxRetries {
+			break
+		}
+
+		delay := BackoffDelay(f.backoff, f.maxBackoff, attempt+1)
+		if retryable.retryAfter > 0 {
+			if retryable.retryAfter > f.maxBackoff {
+				return nil, fmt.Errorf("%w, server asked to retry in %v", err, retryable.retryAfter)
+			}
+			delay = retryable.retryAfter
+		}
+
+		log.Printf("request to %s failed (%v), retrying in %v\n", origin(url), err, delay.Round(time.Millisecond))
+		time.Sleep(delay)
+	}
+
+	return nil, err
+}
+
+func (f *httpFetcher) fetchOnce(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, redactURL(err)
+	}
+
+	for key, value := range f.headers {
+		req.Header.Set(key, value)
+	}
+
+	f.mu.Lock()
+	if etag := f.etags[url]; etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := f.lastModified[url]; lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+	f.mu.Unlock()
+
+	f.waitInterval(req.URL.Host)
+	response, err := f.client.Do(req)
+	if err != nil {
+		return nil, errRetryable{err: redactURL(err)}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotModified {
+		f.resetRateLimit(req.URL.Host)
+		return nil, ErrNotModified
+	}
+
+	if response.StatusCode == http.StatusForbidden && f.rateLimitBackoff > 0 {
+		io.Copy(io.Discard, io.LimitReader(response.Body, 1<<20))
+		until := f.suspend(req.URL.Host)
+		return nil, fmt.Errorf("failed to query data from %s, response error code = %v, requests suspended until %s as rate limited",
+			origin(url), response.StatusCode, until.Format(time.RFC3339))
+	}
+
+	if response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500 {
+		io.Copy(io.Discard, io.LimitReader(response.Body, 1<<20))
+		return nil, errRetryable{
+			err:        fmt.Errorf("failed to query data from %s, response error code = %v", origin(url), response.StatusCode),
+			retryAfter: parseRetryAfter(response.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	if response.StatusCode >= 400 {
+		return nil, fmt.Errorf("failed to query data from %s, response error code = %v", origin(url), response.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxResponseBodyLength))
+	if err != nil {
+		return nil, errRetryable{err: err}
+	}
+
+	f.mu.Lock()
+	f.etags[url] = response.Header.Get("ETag")
+	f.lastModified[url] = response.Header.Get("Last-Modified")
+	f.mu.Unlock()
+	f.resetRateLimit(req.URL.Host)
+
+	return body, nil
+}
+
+// waitInterval waits until the minimum interval since the previous request to the host has elapsed
+func (f *httpFetcher) waitInterval(host string) {
+	if f.minInterval <= 0 {
+		return
+	}
+
+	f.mu.Lock()
+	now := time.Now()
+	next := f.lastRequest[host].Add(f.minInterval)
+	if next.Before(now) {
+		next = now
+	}
+	// the slot is reserved before waiting, so that concurrent requests are spaced as well
+	f.lastRequest[host] = next
+	f.mu.Unlock()
+
+	time.Sleep(next.Sub(now))
+}
+
+// checkRateLimit fails without sending a request while the requests to the host of the URL are suspended
+func (f *httpFetcher) checkRateLimit(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return redactURL(err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if until := f.rateLimited[parsed.Host].until; time.Now().Before(until) {
+		return fmt.Errorf("requests to %s suspended until %s after a rate limit response", parsed.Host, until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// suspend stops the requests to the host after a rate limit response, the delay being doubled at each consecutive one
+func (f *httpFetcher) suspend(host string) time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	limit := f.rateLimited[host]
+	limit.count++
+	limit.until = time.Now().Add(BackoffDelay(f.rateLimitBackoff, maxRateLimitBackoff, limit.count))
+	f.rateLimited[host] = limit
+	return limit.until
+}
+
+func (f *httpFetcher) resetRateLimit(host string) {
+	f.mu.Lock()
+	delete(f.rateLimited, host)
+	f.mu.Unlock()
+}
+
+// forget removes the validators of the URL, so that its content is downloaded again on the next call
+func (f *httpFetcher) forget(url string) {
+	f.mu.Lock()
+	delete(f.etags, url)
+	delete(f.lastModified, url)
+	f.mu.Unlock()
+}
+
+// parseRetryAfter reads the Retry-After header value, either in seconds or as an HTTP date. Zero is returned if the header is absent or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// BackoffDelay exponential backoff for the given attempt number (starting at 1), capped to maxDelay, with up to 50% random jitter
+func BackoffDelay(base, maxDelay time.Duration, attempt int) time.Duration {
+	delay := base << (attempt - 1)
+	if delay > maxDelay || delay <= 0 {
+		delay = maxDelay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
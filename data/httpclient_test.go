@@ -0,0 +1,388 @@
+package data
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testFetcher(t *testing.T, config HTTPConfiguration) *httpFetcher {
+	t.Helper()
+	config.TimeoutSeconds = 5
+	if config.MaxRetries == nil {
+		retries := 2
+		config.MaxRetries = &retries
+	}
+	fetcher, err := newHTTPFetcher(config)
+	if err != nil {
+		t.Fatalf("newHTTPFetcher() error = %v", err)
+	}
+	fetcher.backoff = time.Millisecond
+	fetcher.maxBackoff = 10 * time.Millisecond
+	return fetcher
+}
+
+func TestHTTPFetcher_fetch(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		header    http.Header
+		wantErr   bool
+		wantCalls int32
+	}{
+		{"success", []int{http.StatusOK}, nil, false, 1},
+		{"retry on server error", []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}, nil, false, 3},
+		{"retry on rate limit", []int{http.StatusTooManyRequests, http.StatusOK}, http.Header{"Retry-After": {"0"}}, false, 2},
+		{"retries exhausted", []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK}, nil, true, 3},
+		{"no retry on client error", []int{http.StatusNotFound, http.StatusOK}, nil, true, 1},
+		{"Retry-After above maximum backoff", []int{http.StatusTooManyRequests, http.StatusOK}, http.Header{"Retry-After": {"3600"}}, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				call := atomic.AddInt32(&calls, 1)
+				for key, values := range tt.header {
+					w.Header()[key] = values
+				}
+				w.WriteHeader(tt.statuses[call-1])
+				w.Write([]byte("data"))
+			}))
+			defer server.Close()
+
+			body, err := testFetcher(t, HTTPConfiguration{}).fetch(server.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(body) != "data" {
+				t.Errorf("fetch() body = %q, want %q", body, "data")
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("fetch() sent %v requests, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestHTTPFetcher_maxRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries *int
+		wantErr    bool
+		wantCalls  int32
+	}{
+		{"default", nil, false, defaultMaxRetries + 1},
+		{"disabled", intPointer(0), false, 1},
+		{"one retry", intPointer(1), false, 2},
+		{"negative", intPointer(-1), true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			fetcher, err := newHTTPFetcher(HTTPConfiguration{MaxRetries: tt.maxRetries})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newHTTPFetcher() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			fetcher.backoff = time.Millisecond
+			fetcher.maxBackoff = time.Millisecond
+
+			if _, err := fetcher.fetch(server.URL); err == nil {
+				t.Errorf("fetch() expected an error")
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("fetch() sent %v requests, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func TestHTTPFetcher_rateLimit(t *testing.T) {
+	var calls int32
+	var forbidden int32 = 1
+	var times []time.Time
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		if atomic.LoadInt32(&forbidden) == 1 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	fetcher := testFetcher(t, HTTPConfiguration{RateLimitBackoffSeconds: 3600})
+	fetcher.minInterval = 50 * time.Millisecond
+
+	// the 403 response is not retried, and suspends the next requests to the host
+	if _, err := fetcher.fetch(server.URL); err == nil {
+		t.Fatalf("fetch() expected a rate limit error")
+	}
+	if _, err := fetcher.fetch(server.URL + "/other"); err == nil || !strings.Contains(err.Error(), "suspended") {
+		t.Fatalf("fetch() error = %v, want a suspended error", err)
+	}
+	if calls != 1 {
+		t.Fatalf("fetch() sent %v requests, want 1", calls)
+	}
+
+	fetcher.mu.Lock()
+	host := strings.TrimPrefix(server.URL, "http://")
+	if limit := fetcher.rateLimited[host]; limit.count != 1 || time.Until(limit.until) < 30*time.Minute {
+		t.Errorf("unexpected rate limit %+v", limit)
+	}
+	fetcher.rateLimited[host] = rateLimit{count: 1, until: time.Now()}
+	fetcher.mu.Unlock()
+
+	atomic.StoreInt32(&forbidden, 0)
+	for i := 0; i < 2; i++ {
+		if _, err := fetcher.fetch(server.URL); err != nil {
+			t.Fatalf("fetch() error = %v", err)
+		}
+	}
+	if _, suspended := fetcher.rateLimited[host]; suspended {
+		t.Errorf("rate limit not reset after a successful request")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i := 1; i < len(times); i++ {
+		if interval := times[i].Sub(times[i-1]); interval < 45*time.Millisecond {
+			t.Errorf("requests %d and %d spaced by %v, want at least %v", i, i+1, interval, fetcher.minInterval)
+		}
+	}
+}
+
+func TestHTTPFetcher_conditionalRequests(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Wed, 21 Oct 2026 07:28:00 GMT"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag && r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	fetcher := testFetcher(t, HTTPConfiguration{})
+
+	if _, err := fetcher.fetch(server.URL); err != nil {
+		t.Fatalf("first fetch() error = %v", err)
+	}
+	if _, err := fetcher.fetch(server.URL); !errors.Is(err, ErrNotModified) {
+		t.Fatalf("second fetch() error = %v, want %v", err, ErrNotModified)
+	}
+
+	fetcher.forget(server.URL)
+	if body, err := fetcher.fetch(server.URL); err != nil || string(body) != "data" {
+		t.Fatalf("fetch() after forget = %q, %v", body, err)
+	}
+}
+
+func TestHTTPFetcher_headersAndProxy(t *testing.T) {
+	var proxied int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&proxied, 1)
+		wantAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:password"))
+		if r.Header.Get("Proxy-Authorization") != wantAuth {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		if r.URL.Host != "tle.example.invalid" || r.Header.Get("X-Api-Key") != "key" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("data"))
+	}))
+	defer proxy.Close()
+
+	fetcher := testFetcher(t, HTTPConfiguration{
+		ProxyURL:      proxy.URL,
+		ProxyUsername: "user",
+		ProxyPassword: "password",
+		Headers:       map[string]string{"X-Api-Key": "key"},
+	})
+
+	body, err := fetcher.fetch("http://tle.example.invalid/active.json")
+	if err != nil || string(body) != "data" {
+		t.Fatalf("fetch() through proxy = %q, %v", body, err)
+	}
+	if proxied != 1 {
+		t.Errorf("proxy received %v requests, want 1", proxied)
+	}
+}
+
+func TestHTTPFetcher_TLS(t *testing.T) {
+	dir := t.TempDir()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "tle-provider" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("data"))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+	certFile, keyFile := writeClientCertificate(t, dir)
+
+	tests := []struct {
+		name          string
+		config        HTTPConfiguration
+		wantConfigErr bool
+		wantErr       bool
+	}{
+		{"CA and client certificate", HTTPConfiguration{CAFile: caFile, ClientCertFile: certFile, ClientKeyFile: keyFile}, false, false},
+		{"unknown CA", HTTPConfiguration{ClientCertFile: certFile, ClientKeyFile: keyFile}, false, true},
+		{"no client certificate", HTTPConfiguration{CAFile: caFile}, false, true},
+		{"missing client key", HTTPConfiguration{CAFile: caFile, ClientCertFile: certFile}, true, false},
+		{"missing CA file", HTTPConfiguration{CAFile: filepath.Join(dir, "missing.pem")}, true, false},
+		{"invalid CA file", HTTPConfiguration{CAFile: keyFile}, true, false},
+		{"invalid proxy URL", HTTPConfiguration{ProxyURL: "proxy:3128"}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher, err := newHTTPFetcher(tt.config)
+			if (err != nil) != tt.wantConfigErr {
+				t.Fatalf("newHTTPFetcher() error = %v, wantErr %v", err, tt.wantConfigErr)
+			}
+			if err != nil {
+				return
+			}
+			fetcher.maxRetries = 0
+
+			body, err := fetcher.fetch(server.URL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(body) != "data" {
+				t.Errorf("fetch() body = %q, want %q", body, "data")
+			}
+		})
+	}
+}
+
+func writePEM(t *testing.T, path, blockType string, content []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: content}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeClientCertificate generates a self-signed client certificate, and returns the paths of the certificate and key files
+func writeClientCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "tle-provider"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	writePEM(t, certFile, "CERTIFICATE", cert)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyBytes)
+
+	return certFile, keyFile
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 21, 7, 28, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"absent", "", 0},
+		{"seconds", "120", 2 * time.Minute},
+		{"HTTP date", "Wed, 21 Oct 2026 07:29:30 GMT", 90 * time.Second},
+		{"past HTTP date", "Wed, 21 Oct 2026 07:00:00 GMT", 0},
+		{"invalid", "soon", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{10, 5 * time.Second},
+		{100, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		got := BackoffDelay(time.Second, 5*time.Second, tt.attempt)
+		if got < tt.want/2 || got > tt.want {
+			t.Errorf("BackoffDelay(attempt %v) = %v, want between %v and %v", tt.attempt, got, tt.want/2, tt.want)
+		}
+	}
+}
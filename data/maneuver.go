@@ -0,0 +1,208 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+)
+
+// J2 Earth second zonal harmonic (WGS72, as used by SGP4)
+const J2 = 0.001082616
+
+// OrbitEventType nature of a discontinuity detected between two successive element sets
+type OrbitEventType string
+
+const (
+	// OrbitEventManeuver orbit change not explained by the natural evolution of the orbit
+	OrbitEventManeuver OrbitEventType = "maneuver"
+	// OrbitEventDecay fast semi-major axis decrease at low perigee altitude
+	OrbitEventDecay OrbitEventType = "decay"
+	// OrbitEventBadElementSet element set inconsistent with the previous one, probably erroneous
+	OrbitEventBadElementSet OrbitEventType = "bad_element_set"
+)
+
+// DetectionThresholds maximum differences between the element set predicted from the previous one and the new one before raising an event
+type DetectionThresholds struct {
+	SemiMajorAxisKm     float64 `yaml:"semi_major_axis_km"`
+	InclinationDeg      float64 `yaml:"inclination_deg"`
+	RAANDeg             float64 `yaml:"raan_deg"`
+	MeanMotionRevPerDay float64 `yaml:"mean_motion_rev_per_day"`
+	DecayPerigeeKm      float64 `yaml:"decay_perigee_km"`
+	BadSemiMajorAxisKm  float64 `yaml:"bad_semi_major_axis_km"`
+	BadInclinationDeg   float64 `yaml:"bad_inclination_deg"`
+}
+
+// DefaultDetectionThresholds thresholds used for the zero values of DetectionThresholds
+var DefaultDetectionThresholds = DetectionThresholds{
+	SemiMajorAxisKm:     2,
+	InclinationDeg:      0.05,
+	RAANDeg:             0.5,
+	MeanMotionRevPerDay: 0.01,
+	DecayPerigeeKm:      200,
+	BadSemiMajorAxisKm:  500,
+	BadInclinationDeg:   5,
+}
+
+// withDefaults replaces the unset thresholds by their default value
+func (dt DetectionThresholds) withDefaults() DetectionThresholds {
+	defaults := DefaultDetectionThresholds
+	for _, pair := range []struct {
+		value        *float64
+		defaultValue float64
+	}{
+		{&dt.SemiMajorAxisKm, defaults.SemiMajorAxisKm},
+		{&dt.InclinationDeg, defaults.InclinationDeg},
+		{&dt.RAANDeg, defaults.RAANDeg},
+		{&dt.MeanMotionRevPerDay, defaults.MeanMotionRevPerDay},
+		{&dt.DecayPerigeeKm, defaults.DecayPerigeeKm},
+		{&dt.BadSemiMajorAxisKm, defaults.BadSemiMajorAxisKm},
+		{&dt.BadInclinationDeg, defaults.BadInclinationDeg},
+	} {
+		if *pair.value <= 0 {
+			*pair.value = pair.defaultValue
+		}
+	}
+	return dt
+}
+
+// OrbitEvent discontinuity detected between two successive element sets of a satellite.
+// Residuals are the differences between the new element set and the values predicted from the previous one.
+type OrbitEvent struct {
+	Type                  OrbitEventType `json:"type"`
+	SatelliteName         string         `json:"satellite_name"`
+	NORADID               int            `json:"norad_id"`
+	PreviousEpoch         time.Time      `json:"previous_epoch"`
+	Epoch                 time.Time      `json:"epoch"`
+	SemiMajorAxisResidual float64        `json:"semi_major_axis_residual_km"`
+	InclinationResidual   float64        `json:"inclination_residual_deg"`
+	RAANResidual          float64        `json:"raan_residual_deg"`
+	MeanMotionResidual    float64        `json:"mean_motion_residual_rev_per_day"`
+	Description           string         `json:"description"`
+}
+
+func (e OrbitEvent) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// DetectOrbitEvent compares two successive element sets of the same satellite, and returns the detected event if any.
+// The previous element set is propagated to the new epoch using the mean motion derivative and the J2 secular RAAN drift.
+func DetectOrbitEvent(previous, current Satellite, thresholds DetectionThresholds) (*OrbitEvent, error) {
+	thresholds = thresholds.withDefaults()
+
+	prev, cur, err := parseElementSets(previous, current)
+	if err != nil {
+		return nil, err
+	}
+	event := newOrbitEvent(current, prev, cur)
+
+	if !cur.Epoch.After(prev.Epoch) {
+		if cur.Epoch.Equal(prev.Epoch) {
+			return nil, nil
+		}
+		event.Type = OrbitEventBadElementSet
+		event.Description = fmt.Sprintf("epoch %s is older than the previous epoch %s", cur.Epoch.Format(time.RFC3339), prev.Epoch.Format(time.RFC3339))
+		return &event, nil
+	}
+
+	days := event.setResiduals(prev, cur)
+
+	switch {
+	case math.Abs(event.SemiMajorAxisResidual) > thresholds.BadSemiMajorAxisKm ||
+		math.Abs(event.InclinationResidual) > thresholds.BadInclinationDeg:
+		event.Type = OrbitEventBadElementSet
+		event.Description = fmt.Sprintf("implausible change: semi-major axis %+.1f km, inclination %+.3f deg in %.2f days",
+			event.SemiMajorAxisResidual, event.InclinationResidual, days)
+	case event.SemiMajorAxisResidual < -thresholds.SemiMajorAxisKm && cur.Perigee() < thresholds.DecayPerigeeKm:
+		event.Type = OrbitEventDecay
+		event.Description = fmt.Sprintf("semi-major axis decreased by %.1f km, perigee at %.0f km", -event.SemiMajorAxisResidual, cur.Perigee())
+	case math.Abs(event.SemiMajorAxisResidual) > thresholds.SemiMajorAxisKm ||
+		math.Abs(event.MeanMotionResidual) > thresholds.MeanMotionRevPerDay:
+		event.Type = OrbitEventManeuver
+		event.Description = fmt.Sprintf("semi-major axis changed by %+.1f km, mean motion by %+.5f rev/day", event.SemiMajorAxisResidual, event.MeanMotionResidual)
+	case math.Abs(event.InclinationResidual) > thresholds.InclinationDeg:
+		event.Type = OrbitEventManeuver
+		event.Description = fmt.Sprintf("inclination changed by %+.3f deg", event.InclinationResidual)
+	case math.Abs(event.RAANResidual) > thresholds.RAANDeg:
+		event.Type = OrbitEventManeuver
+		event.Description = fmt.Sprintf("RAAN drift differs from the J2 prediction by %+.3f deg", event.RAANResidual)
+	default:
+		return nil, nil
+	}
+
+	return &event, nil
+}
+
+// OrbitResiduals returns the residuals of the new element set of a satellite, without classifying the change: the event type
+// is empty. Nil if the new epoch is not more recent than the previous one.
+func OrbitResiduals(previous, current Satellite) (*OrbitEvent, error) {
+	prev, cur, err := parseElementSets(previous, current)
+	if err != nil {
+		return nil, err
+	}
+	if !cur.Epoch.After(prev.Epoch) {
+		return nil, nil
+	}
+
+	event := newOrbitEvent(current, prev, cur)
+	event.setResiduals(prev, cur)
+	return &event, nil
+}
+
+func parseElementSets(previous, current Satellite) (Elements, Elements, error) {
+	prev, err := ParseElements(previous)
+	if err != nil {
+		return Elements{}, Elements{}, fmt.Errorf("previous element set of %v: %v", previous.SatelliteName, err)
+	}
+	cur, err := ParseElements(current)
+	if err != nil {
+		return Elements{}, Elements{}, fmt.Errorf("new element set of %v: %v", current.SatelliteName, err)
+	}
+	return prev, cur, nil
+}
+
+func newOrbitEvent(current Satellite, prev, cur Elements) OrbitEvent {
+	return OrbitEvent{
+		SatelliteName: current.SatelliteName,
+		NORADID:       current.NORADID,
+		PreviousEpoch: prev.Epoch,
+		Epoch:         cur.Epoch,
+	}
+}
+
+// setResiduals compares the new elements to the ones predicted from the previous elements at the new epoch,
+// and returns the days between both epochs
+func (e *OrbitEvent) setResiduals(prev, cur Elements) float64 {
+	days := cur.Epoch.Sub(prev.Epoch).Hours() / 24
+
+	// The TLE first derivative field is half the mean motion derivative
+	predicted := prev
+	predicted.MeanMotion = prev.MeanMotion + 2*prev.MeanMotionDot*days
+	predicted.RAAN = math.Mod(prev.RAAN+raanDriftRate(prev)*days, 360)
+
+	e.MeanMotionResidual = cur.MeanMotion - predicted.MeanMotion
+	e.SemiMajorAxisResidual = cur.SemiMajorAxis() - predicted.SemiMajorAxis()
+	e.InclinationResidual = cur.Inclination - prev.Inclination
+	e.RAANResidual = wrapAngle(cur.RAAN - predicted.RAAN)
+	return days
+}
+
+// raanDriftRate secular drift of the right ascension of the ascending node due to J2, in degrees per day
+func raanDriftRate(el Elements) float64 {
+	a := el.SemiMajorAxis()
+	p := a * (1 - el.Eccentricity*el.Eccentricity)
+	if p <= 0 {
+		return 0
+	}
+	n := el.MeanMotion * 360 // degrees per day
+	return -1.5 * n * J2 * (EarthRadius / p) * (EarthRadius / p) * math.Cos(el.Inclination*math.Pi/180)
+}
+
+// wrapAngle brings an angle difference in degrees to the [-180, 180[ interval
+func wrapAngle(angle float64) float64 {
+	angle = math.Mod(angle+180, 360)
+	if angle < 0 {
+		angle += 360
+	}
+	return angle - 180
+}
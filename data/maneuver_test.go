@@ -0,0 +1,108 @@
+package data
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectOrbitEvent(t *testing.T) {
+	oneweb := Satellite{
+		SatelliteName: "ONEWEB-0012",
+		NORADID:       44057,
+		TLELine1:      "1 44057U 19010A   22206.81764082 -.00000043  00000+0 -14585-3 0  9993",
+		TLELine2:      "2 44057  87.9150 151.8950 0002369 106.7932 253.3459 13.16592117164401",
+	}
+	starlink := Satellite{
+		SatelliteName: "STARLINK-61",
+		NORADID:       44249,
+		TLELine1:      "1 44249U 19029Q   22207.21981331  .01879246  22465-2  37398-2 0  9995",
+		TLELine2:      "2 44249  52.9518 229.8866 0008132  34.7714 325.3242 15.99740001176748",
+	}
+
+	// modify returns a copy of the satellite with the epoch and line 2 substrings replaced
+	modify := func(sat Satellite, oldEpoch, newEpoch, oldLine2, newLine2 string) Satellite {
+		sat.TLELine1 = strings.Replace(sat.TLELine1, oldEpoch, newEpoch, 1)
+		sat.TLELine2 = strings.Replace(sat.TLELine2, oldLine2, newLine2, 1)
+		return sat
+	}
+
+	tests := []struct {
+		name     string
+		previous Satellite
+		current  Satellite
+		want     OrbitEventType
+		wantErr  bool
+	}{
+		{
+			name:     "nominal evolution",
+			previous: oneweb,
+			current:  modify(oneweb, "22206.", "22207.", "", ""),
+			want:     "",
+		},
+		{
+			name:     "same epoch",
+			previous: oneweb,
+			current:  oneweb,
+			want:     "",
+		},
+		{
+			name:     "orbit raising",
+			previous: oneweb,
+			current:  modify(oneweb, "22206.", "22207.", "13.16592117", "13.10592117"),
+			want:     OrbitEventManeuver,
+		},
+		{
+			name:     "plane change",
+			previous: oneweb,
+			current:  modify(oneweb, "22206.", "22207.", " 87.9150", " 87.9950"),
+			want:     OrbitEventManeuver,
+		},
+		{
+			name:     "decay",
+			previous: starlink,
+			current:  modify(starlink, "22207.", "22208.", "15.99740001", "16.29740001"),
+			want:     OrbitEventDecay,
+		},
+		{
+			name:     "implausible inclination change",
+			previous: oneweb,
+			current:  modify(oneweb, "22206.", "22207.", " 87.9150", " 97.9150"),
+			want:     OrbitEventBadElementSet,
+		},
+		{
+			name:     "epoch regression",
+			previous: oneweb,
+			current:  modify(oneweb, "22206.", "22205.", "", ""),
+			want:     OrbitEventBadElementSet,
+		},
+		{
+			name:     "invalid element set",
+			previous: oneweb,
+			current:  Satellite{SatelliteName: "ONEWEB-0012", TLELine1: "1 44057U", TLELine2: "2 44057"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectOrbitEvent(tt.previous, tt.current, DetectionThresholds{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DetectOrbitEvent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.want == "" {
+				if got != nil {
+					t.Errorf("DetectOrbitEvent() got %s event (%s), want none", got.Type, got.Description)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("DetectOrbitEvent() got no event, want %s", tt.want)
+			}
+			if got.Type != tt.want {
+				t.Errorf("DetectOrbitEvent() got %s event (%s), want %s", got.Type, got.Description, tt.want)
+			}
+		})
+	}
+}
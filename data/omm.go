@@ -0,0 +1,288 @@
+package data
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ommXML CCSDS OMM XML document, either a standalone omm element or omm elements within an ndm element, as produced by Celestrak
+// and Space-Track
+type ommXML struct {
+	XMLName     xml.Name
+	NDMSegments []ommSegment `xml:"omm>body>segment"`
+	OMMSegments []ommSegment `xml:"body>segment"`
+}
+
+// ommSegment metadata and mean elements of a satellite in an OMM XML document
+type ommSegment struct {
+	Metadata struct {
+		ObjectName string `xml:"OBJECT_NAME"`
+		ObjectID   string `xml:"OBJECT_ID"`
+	} `xml:"metadata"`
+	Data struct {
+		MeanElements struct {
+			Epoch           string  `xml:"EPOCH"`
+			MeanMotion      float64 `xml:"MEAN_MOTION"`
+			Eccentricity    float64 `xml:"ECCENTRICITY"`
+			Inclination     float64 `xml:"INCLINATION"`
+			RaOfASCMode     float64 `xml:"RA_OF_ASC_NODE"`
+			ArgOfPericenter float64 `xml:"ARG_OF_PERICENTER"`
+			MeanAnomaly     float64 `xml:"MEAN_ANOMALY"`
+		} `xml:"meanElements"`
+		TLEParameters struct {
+			EphemerisType      int     `xml:"EPHEMERIS_TYPE"`
+			ClassificationType string  `xml:"CLASSIFICATION_TYPE"`
+			NORADCatID         int     `xml:"NORAD_CAT_ID"`
+			ElementSetNo       int     `xml:"ELEMENT_SET_NO"`
+			RevAtEpoch         int     `xml:"REV_AT_EPOCH"`
+			BStar              float64 `xml:"BSTAR"`
+			MeanMotionDOT      float64 `xml:"MEAN_MOTION_DOT"`
+			MeanMotionDDOT     float64 `xml:"MEAN_MOTION_DDOT"`
+		} `xml:"tleParameters"`
+	} `xml:"data"`
+}
+
+// ParseOMMJSON reads satellites from OMM JSON, as served by the Celestrak GP JSON API or by Space-Track (values as strings).
+// Both an array of objects and a single object are accepted.
+func ParseOMMJSON(r io.Reader) ([]Satellite, []SatelliteErr, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records []map[string]json.RawMessage
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		var record map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &record); err != nil {
+			return nil, nil, err
+		}
+		records = append(records, record)
+	} else if err := json.Unmarshal(content, &records); err != nil {
+		return nil, nil, err
+	}
+
+	var satData []CelestrakData
+	var rejected []SatelliteErr
+	for i, record := range records {
+		fields := make(map[string]string, len(record))
+		for key, raw := range record {
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				value = string(raw)
+			}
+			if value != "null" {
+				fields[key] = value
+			}
+		}
+
+		element, err := celestrakDataFromFields(fields)
+		if err != nil {
+			rejected = append(rejected, SatelliteErr{
+				Err: fmt.Errorf("record %v: %w", i+1, err),
+				Sat: Satellite{SatelliteName: fields["OBJECT_NAME"]},
+			})
+			continue
+		}
+		satData = append(satData, element)
+	}
+
+	sats, conversionRejected := convertAll(satData)
+	return sats, append(rejected, conversionRejected...), nil
+}
+
+// ParseOMMCSV reads satellites from OMM CSV, as served by Celestrak and Space-Track. The columns are identified by the header line.
+func ParseOMMCSV(r io.Reader) ([]Satellite, []SatelliteErr, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToUpper(strings.TrimSpace(strings.TrimPrefix(header[i], "\uFEFF")))
+	}
+
+	var satData []CelestrakData
+	var rejected []SatelliteErr
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			rejected = append(rejected, SatelliteErr{Err: fmt.Errorf("line %v: %w", line, err)})
+			continue
+		}
+		if len(row) != len(header) {
+			rejected = append(rejected, SatelliteErr{Err: fmt.Errorf("line %v: expected %v columns, got %v", line, len(header), len(row))})
+			continue
+		}
+
+		fields := make(map[string]string, len(header))
+		for i, column := range header {
+			fields[column] = row[i]
+		}
+
+		element, err := celestrakDataFromFields(fields)
+		if err != nil {
+			rejected = append(rejected, SatelliteErr{
+				Err: fmt.Errorf("line %v: %w", line, err),
+				Sat: Satellite{SatelliteName: fields["OBJECT_NAME"]},
+			})
+			continue
+		}
+		satData = append(satData, element)
+	}
+
+	sats, conversionRejected := convertAll(satData)
+	return sats, append(rejected, conversionRejected...), nil
+}
+
+// celestrakDataFromFields builds the Celestrak data from the OMM field values, indexed by OMM keyword
+func celestrakDataFromFields(fields map[string]string) (CelestrakData, error) {
+	element := CelestrakData{
+		ObjectName:         strings.TrimSpace(fields["OBJECT_NAME"]),
+		ObjectID:           strings.TrimSpace(fields["OBJECT_ID"]),
+		ClassificationType: strings.TrimSpace(fields["CLASSIFICATION_TYPE"]),
+	}
+	if element.ClassificationType == "" {
+		element.ClassificationType = "U"
+	}
+
+	epoch, err := normalizeEpoch(fields["EPOCH"])
+	if err != nil {
+		return CelestrakData{}, err
+	}
+	element.Epoch = epoch
+
+	floats := []struct {
+		key      string
+		value    *float64
+		required bool
+	}{
+		{"MEAN_MOTION", &element.MeanMotion, true},
+		{"ECCENTRICITY", &element.Eccentricity, true},
+		{"INCLINATION", &element.Inclination, true},
+		{"RA_OF_ASC_NODE", &element.RaOfASCMode, true},
+		{"ARG_OF_PERICENTER", &element.ArgOfPericenter, true},
+		{"MEAN_ANOMALY", &element.MeanAnomaly, true},
+		{"BSTAR", &element.BStar, false},
+		{"MEAN_MOTION_DOT", &element.MeanMotionDOT, false},
+		{"MEAN_MOTION_DDOT", &element.MeanMotionDDOT, false},
+	}
+	for _, field := range floats {
+		raw := strings.TrimSpace(fields[field.key])
+		if raw == "" {
+			if field.required {
+				return CelestrakData{}, fmt.Errorf("missing %v", field.key)
+			}
+			continue
+		}
+		if *field.value, err = strconv.ParseFloat(raw, 64); err != nil {
+			return CelestrakData{}, fmt.Errorf("invalid %v %v", field.key, raw)
+		}
+	}
+
+	ints := []struct {
+		key      string
+		value    *int
+		required bool
+	}{
+		{"NORAD_CAT_ID", &element.NORADCatID, true},
+		{"EPHEMERIS_TYPE", &element.EphemerisType, false},
+		{"ELEMENT_SET_NO", &element.ElementSetNo, false},
+		{"REV_AT_EPOCH", &element.RevAtEpoch, false},
+	}
+	for _, field := range ints {
+		raw := strings.TrimSpace(fields[field.key])
+		if raw == "" {
+			if field.required {
+				return CelestrakData{}, fmt.Errorf("missing %v", field.key)
+			}
+			continue
+		}
+		if *field.value, err = strconv.Atoi(raw); err != nil {
+			return CelestrakData{}, fmt.Errorf("invalid %v %v", field.key, raw)
+		}
+	}
+
+	return element, nil
+}
+
+// normalizeEpoch converts an OMM epoch, with or without the UTC Z suffix and with any number of fraction digits,
+// to the format of the Celestrak JSON API expected by the TLE conversion
+func normalizeEpoch(raw string) (string, error) {
+	epoch, err := time.Parse("2006-01-02T15:04:05", strings.TrimSuffix(strings.TrimSpace(raw), "Z"))
+	if err != nil {
+		return "", fmt.Errorf("invalid EPOCH %v", raw)
+	}
+	return epoch.Format("2006-01-02T15:04:05.000000"), nil
+}
+
+// ParseOMMXML reads satellites from CCSDS OMM XML
+func ParseOMMXML(r io.Reader) ([]Satellite, []SatelliteErr, error) {
+	var doc ommXML
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, nil, err
+	}
+
+	var segments []ommSegment
+	switch doc.XMLName.Local {
+	case "ndm":
+		segments = doc.NDMSegments
+	case "omm":
+		segments = doc.OMMSegments
+	default:
+		return nil, nil, fmt.Errorf("unexpected root element %v, want ndm or omm", doc.XMLName.Local)
+	}
+	if len(segments) == 0 {
+		return nil, nil, fmt.Errorf("no OMM segment found")
+	}
+
+	satData := make([]CelestrakData, 0, len(segments))
+	var rejected []SatelliteErr
+	for i, segment := range segments {
+		elements := segment.Data.MeanElements
+		parameters := segment.Data.TLEParameters
+		epoch, err := normalizeEpoch(elements.Epoch)
+		if err != nil {
+			rejected = append(rejected, SatelliteErr{
+				Err: fmt.Errorf("segment %v: %w", i+1, err),
+				Sat: Satellite{SatelliteName: segment.Metadata.ObjectName, NORADID: parameters.NORADCatID},
+			})
+			continue
+		}
+		satData = append(satData, CelestrakData{
+			ObjectName:         segment.Metadata.ObjectName,
+			ObjectID:           segment.Metadata.ObjectID,
+			Epoch:              epoch,
+			MeanMotion:         elements.MeanMotion,
+			Eccentricity:       elements.Eccentricity,
+			Inclination:        elements.Inclination,
+			RaOfASCMode:        elements.RaOfASCMode,
+			ArgOfPericenter:    elements.ArgOfPericenter,
+			MeanAnomaly:        elements.MeanAnomaly,
+			EphemerisType:      parameters.EphemerisType,
+			ClassificationType: parameters.ClassificationType,
+			NORADCatID:         parameters.NORADCatID,
+			ElementSetNo:       parameters.ElementSetNo,
+			RevAtEpoch:         parameters.RevAtEpoch,
+			BStar:              parameters.BStar,
+			MeanMotionDOT:      parameters.MeanMotionDOT,
+			MeanMotionDDOT:     parameters.MeanMotionDDOT,
+		})
+	}
+
+	sats, conversionRejected := convertAll(satData)
+	return sats, append(rejected, conversionRejected...), nil
+}
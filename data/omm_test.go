@@ -0,0 +1,128 @@
+package data
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOMM(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want []Satellite
+	}{
+		{
+			name: "XML",
+			path: "../samples/directory/starlink.xml",
+			want: []Satellite{
+				{
+					SatelliteName: "STARLINK-71",
+					NORADID:       44252,
+					TLELine1:      "1 44252U 19029T   22206.63642171  .00063016  00000-0  13933-2 0  9997",
+					TLELine2:      "2 44252  52.9947 285.2994 0003334  27.1844 332.9334 15.43254345174817",
+				},
+			},
+		},
+		{
+			name: "JSON",
+			path: "../samples/directory/oneweb.json",
+			want: []Satellite{
+				{
+					SatelliteName: "ONEWEB-0012",
+					NORADID:       44057,
+					TLELine1:      "1 44057U 19010A   22206.81764082 -.00000043  00000-0 -14584-3 0  9993",
+					TLELine2:      "2 44057  87.9150 151.8950 0002369 106.7932 253.3459 13.16592117164401",
+				},
+				{
+					SatelliteName: "ONEWEB-0010",
+					NORADID:       44058,
+					TLELine1:      "1 44058U 19010B   22206.61495996  .00000036  00000-0  59419-4 0  9994",
+					TLELine2:      "2 44058  87.9152 151.9295 0002498  91.0942 269.0475 13.16593199164422",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := os.Open(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			parser := ParseOMMJSON
+			if tt.name == "XML" {
+				parser = ParseOMMXML
+			}
+
+			got, rejected, err := parser(file)
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+			if len(rejected) != 0 {
+				t.Errorf("rejected records: %v", rejected)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOMMXML_epoch(t *testing.T) {
+	content, err := os.ReadFile("../samples/directory/starlink.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const line1 = "1 44252U 19029T   22206.63642171  .00063016  00000-0  13933-2 0  9997"
+
+	tests := []struct {
+		name     string
+		epoch    string
+		wantLine string
+	}{
+		{"UTC suffix", "2022-07-25T15:16:26.835744Z", line1},
+		{"short fraction", "2022-07-25T15:16:26.8357Z", line1},
+		{"invalid", "2022-07-25 15:16", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			xml := strings.Replace(string(content), "2022-07-25T15:16:26.835744", tt.epoch, 1)
+			got, rejected, err := ParseOMMXML(strings.NewReader(xml))
+			if err != nil {
+				t.Fatalf("ParseOMMXML() error = %v", err)
+			}
+			if tt.wantLine == "" {
+				if len(got) != 0 || len(rejected) != 1 || rejected[0].Sat.NORADID != 44252 {
+					t.Errorf("expected the segment to be rejected, got %v, rejected %v", got, rejected)
+				}
+				return
+			}
+			if len(rejected) != 0 || len(got) != 1 || got[0].TLELine1 != tt.wantLine {
+				t.Errorf("got %v, rejected %v, want line 1 %q", got, rejected, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestParseOMMXML_root(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"ndm without segment", `<ndm><omm><header/><body/></omm></ndm>`, "no OMM segment"},
+		{"omm without segment", `<omm><header/><body/></omm>`, "no OMM segment"},
+		{"other document", `<catalog><body><segment/></body></catalog>`, "unexpected root element catalog"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseOMMXML(strings.NewReader(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseOMMXML() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// OriginOverride origin of the satellites whose element set is overridden
+const OriginOverride = "override"
+
+// OverrideConfiguration manual element sets taking precedence over the source data
+type OverrideConfiguration struct {
+	Enabled   bool     `yaml:"enabled"`
+	StorePath string   `yaml:"store_path"`
+	APITokens []string `yaml:"api_tokens"`
+}
+
+// Override element set uploaded manually, served instead of the source data until it expires,
+// or until the source provides a more recent epoch for the satellite
+type Override struct {
+	Satellite Satellite  `json:"satellite"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Reason    string     `json:"reason,omitempty"`
+}
+
+func (o Override) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// expired returns true if the override expiry date is passed
+func (o Override) expired(now time.Time) bool {
+	return o.ExpiresAt != nil && !now.Before(*o.ExpiresAt)
+}
+
+// OverrideStore overrides by NORAD ID, saved to a JSON file after each modification
+type OverrideStore struct {
+	path      string
+	mu        sync.Mutex
+	overrides map[int]Override
+}
+
+// NewOverrideStore loads the overrides saved in the file. The file is created on the first modification if it does not exist.
+// With an empty path, the overrides are only kept in memory.
+func NewOverrideStore(path string) (*OverrideStore, error) {
+	store := &OverrideStore{
+		path:      path,
+		overrides: make(map[int]Override),
+	}
+	if path == "" {
+		return store, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides []Override
+	if err := json.Unmarshal(content, &overrides); err != nil {
+		return nil, fmt.Errorf("invalid override store %s: %w", path, err)
+	}
+	for _, o := range overrides {
+		store.overrides[o.Satellite.NORADID] = o
+	}
+
+	return store, nil
+}
+
+// List returns the overrides sorted by NORAD ID
+func (s *OverrideStore) List() []Override {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list()
+}
+
+// Set adds the overrides, replacing the existing ones for the same NORAD IDs
+func (s *OverrideStore) Set(overrides []Override) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, o := range overrides {
+		o.Satellite.Origin = OriginOverride
+		s.overrides[o.Satellite.NORADID] = o
+	}
+
+	return s.save()
+}
+
+// Delete removes the override of the satellite, returning false if there is none
+func (s *OverrideStore) Delete(noradID int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.overrides[noradID]; !ok {
+		return false, nil
+	}
+	delete(s.overrides, noradID)
+
+	return true, s.save()
+}
+
+// Apply replaces the satellites of the source data by their override, and adds the overridden satellites missing from the source data.
+// The expired overrides, and the overrides older than the source element set of the satellite, are removed from the store.
+func (s *OverrideStore) Apply(sats []Satellite, now time.Time) ([]Satellite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.overrides) == 0 {
+		return sats, nil
+	}
+
+	removed := false
+	for id, o := range s.overrides {
+		if o.expired(now) {
+			delete(s.overrides, id)
+			removed = true
+		}
+	}
+
+	output := make([]Satellite, 0, len(sats)+len(s.overrides))
+	applied := make(map[int]struct{}, len(s.overrides))
+	for _, sat := range sats {
+		o, ok := s.overrides[sat.NORADID]
+		if !ok {
+			output = append(output, sat)
+			continue
+		}
+		if Supersedes(sat, o.Satellite) {
+			delete(s.overrides, sat.NORADID)
+			removed = true
+			output = append(output, sat)
+			continue
+		}
+		output = append(output, o.Satellite)
+		applied[sat.NORADID] = struct{}{}
+	}
+
+	for _, o := range s.list() {
+		if _, ok := applied[o.Satellite.NORADID]; !ok {
+			output = append(output, o.Satellite)
+		}
+	}
+
+	if !removed {
+		return output, nil
+	}
+	return output, s.save()
+}
+
+// Supersedes returns true if the source element set is more recent than the override
+func Supersedes(sat, override Satellite) bool {
+	satElements, err := ParseElements(sat)
+	if err != nil {
+		return false
+	}
+	overrideElements, err := ParseElements(override)
+	if err != nil {
+		return true
+	}
+	return satElements.Epoch.After(overrideElements.Epoch)
+}
+
+func (s *OverrideStore) list() []Override {
+	overrides := make([]Override, 0, len(s.overrides))
+	for _, o := range s.overrides {
+		overrides = append(overrides, o)
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].Satellite.NORADID < overrides[j].Satellite.NORADID
+	})
+	return overrides
+}
+
+// save writes the overrides to a temporary file renamed afterwards, so that the store is never partially written
+func (s *OverrideStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(s.list(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,132 @@
+package data
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	lageos = Satellite{
+		SatelliteName: "LAGEOS 1",
+		NORADID:       8820,
+		TLELine1:      "1 08820U 76039A   22206.68532073  .00000028  00000-0  00000-0 0  9999",
+		TLELine2:      "2 08820 109.8533  52.0899 0045094 246.5947 308.4924  6.38664901822297",
+	}
+	lageosNewer = Satellite{
+		SatelliteName: "LAGEOS 1",
+		NORADID:       8820,
+		TLELine1:      "1 08820U 76039A   22210.50000000  .00000028  00000-0  00000-0 0  9995",
+		TLELine2:      "2 08820 109.8533  52.0899 0045094 246.5947 308.4924  6.38664901822309",
+	}
+	lageosOlder = Satellite{
+		SatelliteName: "LAGEOS 1",
+		NORADID:       8820,
+		TLELine1:      "1 08820U 76039A   22200.50000000  .00000028  00000-0  00000-0 0  9994",
+		TLELine2:      "2 08820 109.8533  52.0899 0045094 246.5947 308.4924  6.38664901822309",
+	}
+	prelaunch = Satellite{
+		SatelliteName: "PRELAUNCH-1",
+		NORADID:       99901,
+		TLELine1:      "1 99901U 22999A   22206.50000000  .00000000  00000-0  00000-0 0  9996",
+		TLELine2:      "2 99901  97.5000 100.0000 0001000  90.0000 270.0000 15.20000000    10",
+	}
+)
+
+func TestOverrideStore_Apply(t *testing.T) {
+	now := time.Date(2022, 7, 26, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	withOrigin := func(sat Satellite) Satellite {
+		sat.Origin = OriginOverride
+		return sat
+	}
+
+	tests := []struct {
+		name          string
+		overrides     []Override
+		want          []Satellite
+		wantRemaining int
+	}{
+		{
+			name: "no override",
+			want: []Satellite{lageos},
+		},
+		{
+			name:          "override of a source satellite",
+			overrides:     []Override{{Satellite: lageosNewer, ExpiresAt: &future}},
+			want:          []Satellite{withOrigin(lageosNewer)},
+			wantRemaining: 1,
+		},
+		{
+			name:          "satellite missing from the source",
+			overrides:     []Override{{Satellite: prelaunch}},
+			want:          []Satellite{lageos, withOrigin(prelaunch)},
+			wantRemaining: 1,
+		},
+		{
+			name:          "expired override",
+			overrides:     []Override{{Satellite: lageosNewer, ExpiresAt: &past}, {Satellite: prelaunch, ExpiresAt: &past}},
+			want:          []Satellite{lageos},
+			wantRemaining: 0,
+		},
+		{
+			name:          "override superseded by a newer source epoch",
+			overrides:     []Override{{Satellite: lageosOlder}},
+			want:          []Satellite{lageos},
+			wantRemaining: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "overrides.json")
+			store, err := NewOverrideStore(path)
+			if err != nil {
+				t.Fatalf("NewOverrideStore() error = %v", err)
+			}
+			if err := store.Set(tt.overrides); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			got, err := store.Apply([]Satellite{lageos}, now)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Apply() got %v satellites, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Apply() satellite %v = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+
+			reloaded, err := NewOverrideStore(path)
+			if err != nil {
+				t.Fatalf("NewOverrideStore() reload error = %v", err)
+			}
+			if len(reloaded.List()) != tt.wantRemaining {
+				t.Errorf("reloaded store has %v overrides, want %v", len(reloaded.List()), tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestOverrideStore_Delete(t *testing.T) {
+	store, err := NewOverrideStore("")
+	if err != nil {
+		t.Fatalf("NewOverrideStore() error = %v", err)
+	}
+	if err := store.Set([]Override{{Satellite: prelaunch}}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	if deleted, err := store.Delete(prelaunch.NORADID); !deleted || err != nil {
+		t.Errorf("Delete() = %v, %v, want true", deleted, err)
+	}
+	if deleted, err := store.Delete(prelaunch.NORADID); deleted || err != nil {
+		t.Errorf("second Delete() = %v, %v, want false", deleted, err)
+	}
+}
@@ -0,0 +1,110 @@
+package data
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// tleLine non-blank line of a TLE file, with its line number in the file
+type tleLine struct {
+	number int
+	text   string
+}
+
+// isTLELine returns true if the line looks like the given TLE line (1 or 2)
+func (l tleLine) isTLELine(lineNumber byte) bool {
+	return len(l.text) >= 2 && l.text[0] == lineNumber && l.text[1] == ' '
+}
+
+// ParseTLE reads satellites from TLE text in the 2LE or 3LE format, the format being detected for each record.
+// Name lines may be prefixed with "0 ", and blank lines, surrounding whitespace and CRLF line endings are ignored.
+// Invalid records are reported individually with their line number, and do not prevent reading the other records.
+func ParseTLE(r io.Reader) ([]Satellite, []SatelliteErr, error) {
+	var lines []tleLine
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		text := strings.TrimSpace(scanner.Text())
+		if text != "" {
+			lines = append(lines, tleLine{number: lineNumber, text: text})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var output []Satellite
+	var rejected []SatelliteErr
+
+	i := 0
+	for i < len(lines) {
+		name := ""
+		nameLine := lines[i]
+		if !nameLine.isTLELine('1') {
+			name = strings.TrimSpace(strings.TrimPrefix(nameLine.text, "0 "))
+			i++
+		}
+
+		if i >= len(lines) || !lines[i].isTLELine('1') {
+			rejected = append(rejected, SatelliteErr{
+				Err: fmt.Errorf("line %v: expected TLE line 1 after name line", nameLine.number),
+				Sat: Satellite{SatelliteName: name},
+			})
+			continue
+		}
+		line1 := lines[i]
+		i++
+
+		if i >= len(lines) || !lines[i].isTLELine('2') {
+			rejected = append(rejected, SatelliteErr{
+				Err: fmt.Errorf("line %v: TLE line 1 not followed by TLE line 2", line1.number),
+				Sat: Satellite{SatelliteName: name, TLELine1: line1.text},
+			})
+			continue
+		}
+		line2 := lines[i]
+		i++
+
+		sat, err := newSatellite(name, line1, line2)
+		if err != nil {
+			rejected = append(rejected, SatelliteErr{Err: err, Sat: sat})
+			continue
+		}
+		output = append(output, sat)
+	}
+
+	return output, rejected, nil
+}
+
+// newSatellite builds a satellite from its TLE lines. Satellites without name line are named after their NORAD ID.
+func newSatellite(name string, line1, line2 tleLine) (Satellite, error) {
+	sat := Satellite{
+		SatelliteName: name,
+		TLELine1:      line1.text,
+		TLELine2:      line2.text,
+	}
+
+	if len(line1.text) != 69 {
+		return sat, fmt.Errorf("line %v: TLE line 1 has wrong format, expected 69 characters, got %v", line1.number, len(line1.text))
+	}
+	if len(line2.text) != 69 {
+		return sat, fmt.Errorf("line %v: TLE line 2 has wrong format, expected 69 characters, got %v", line2.number, len(line2.text))
+	}
+
+	noradID, err := strconv.Atoi(strings.TrimSpace(line1.text[2:7]))
+	if err != nil {
+		return sat, fmt.Errorf("line %v: error parsing the NORAD ID from the first TLE line: %v could not be cast as an int", line1.number, line1.text[2:7])
+	}
+	sat.NORADID = noradID
+
+	if sat.SatelliteName == "" {
+		sat.SatelliteName = strconv.Itoa(noradID)
+	}
+
+	return sat, nil
+}
@@ -0,0 +1,89 @@
+package data
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTLE(t *testing.T) {
+	lageos := Satellite{
+		SatelliteName: "LAGEOS 1",
+		NORADID:       8820,
+		TLELine1:      "1 08820U 76039A   22206.68532073  .00000028  00000-0  00000-0 0  9999",
+		TLELine2:      "2 08820 109.8533  52.0899 0045094 246.5947 308.4924  6.38664901822297",
+	}
+	calsphere := Satellite{
+		SatelliteName: "CALSPHERE 1",
+		NORADID:       900,
+		TLELine1:      "1 00900U 64063C   22206.83199285  .00000371  00000-0  38562-3 0  9993",
+		TLELine2:      "2 00900  90.1732  41.6116 0024844 266.8448 104.5887 13.73849434875933",
+	}
+
+	tests := []struct {
+		name         string
+		input        string
+		want         []Satellite
+		wantRejected []string
+	}{
+		{
+			name: "3LE with trailing spaces and CRLF",
+			input: "LAGEOS 1                \r\n" + lageos.TLELine1 + "\r\n" + lageos.TLELine2 + "\r\n" +
+				"CALSPHERE 1\r\n" + calsphere.TLELine1 + "\r\n" + calsphere.TLELine2,
+			want: []Satellite{lageos, calsphere},
+		},
+		{
+			name:  "3LE with 0 prefixed names and blank lines",
+			input: "0 LAGEOS 1\n" + lageos.TLELine1 + "\n" + lageos.TLELine2 + "\n\n\n0 CALSPHERE 1\n\n" + calsphere.TLELine1 + "\n" + calsphere.TLELine2 + "\n\n",
+			want:  []Satellite{lageos, calsphere},
+		},
+		{
+			name:  "2LE",
+			input: lageos.TLELine1 + "\n" + lageos.TLELine2 + "\n" + calsphere.TLELine1 + "\n" + calsphere.TLELine2 + "\n",
+			want: []Satellite{
+				{SatelliteName: "8820", NORADID: 8820, TLELine1: lageos.TLELine1, TLELine2: lageos.TLELine2},
+				{SatelliteName: "900", NORADID: 900, TLELine1: calsphere.TLELine1, TLELine2: calsphere.TLELine2},
+			},
+		},
+		{
+			name:  "mixed 2LE and 3LE",
+			input: lageos.TLELine1 + "\n" + lageos.TLELine2 + "\nCALSPHERE 1\n" + calsphere.TLELine1 + "\n" + calsphere.TLELine2 + "\n",
+			want: []Satellite{
+				{SatelliteName: "8820", NORADID: 8820, TLELine1: lageos.TLELine1, TLELine2: lageos.TLELine2},
+				calsphere,
+			},
+		},
+		{
+			name: "invalid records in the middle",
+			input: "BROKEN 1\n" + lageos.TLELine1[:60] + "\n" + lageos.TLELine2 + "\n" +
+				"BROKEN 2\n" + lageos.TLELine1 + "\n" +
+				"CALSPHERE 1\n" + calsphere.TLELine1 + "\n" + calsphere.TLELine2 + "\n" +
+				"ORPHAN NAME\n",
+			want: []Satellite{calsphere},
+			wantRejected: []string{
+				"line 2: TLE line 1 has wrong format, expected 69 characters, got 60",
+				"line 5: TLE line 1 not followed by TLE line 2",
+				"line 9: expected TLE line 1 after name line",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rejected, err := ParseTLE(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("ParseTLE() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTLE() got = %v, want %v", got, tt.want)
+			}
+
+			var gotRejected []string
+			for _, record := range rejected {
+				gotRejected = append(gotRejected, record.Err.Error())
+			}
+			if !reflect.DeepEqual(gotRejected, tt.wantRejected) {
+				t.Errorf("ParseTLE() rejected = %v, want %v", gotRejected, tt.wantRejected)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package data
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+)
+
+// Authentication types of the URL source
+const (
+	AuthBasic  = "basic"
+	AuthBearer = "bearer"
+	AuthHeader = "header"
+)
+
+// URLAuthConfiguration credentials sent with the requests of the URL source
+type URLAuthConfiguration struct {
+	Type        string `yaml:"type"`
+	Username    string `yaml:"username"`
+	Password    string `yaml:"password"`
+	Token       string `yaml:"token"`
+	HeaderName  string `yaml:"header_name"`
+	HeaderValue string `yaml:"header_value"`
+}
+
+// header returns the name and value of the header carrying the credentials, an empty name if no authentication is configured
+func (a URLAuthConfiguration) header() (string, string, error) {
+	switch a.Type {
+	case "":
+		return "", "", nil
+	case AuthBasic:
+		if a.Username == "" {
+			return "", "", fmt.Errorf("username is required for basic authentication")
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(a.Username + ":" + a.Password))
+		return "Authorization", "Basic " + credentials, nil
+	case AuthBearer:
+		if a.Token == "" {
+			return "", "", fmt.Errorf("token is required for bearer authentication")
+		}
+		return "Authorization", "Bearer " + a.Token, nil
+	case AuthHeader:
+		if a.HeaderName == "" {
+			return "", "", fmt.Errorf("header_name is required for header authentication")
+		}
+		return a.HeaderName, a.HeaderValue, nil
+	default:
+		return "", "", fmt.Errorf("unsupported authentication type %v", a.Type)
+	}
+}
+
+// URLSource pulls the satellites from one or more HTTP(S) endpoints serving TLE or OMM data, parsed the same way as the file source.
+// When several URLs are configured, each satellite is tagged with its origin URL.
+type URLSource struct {
+	urls     []string
+	format   string
+	fetcher  *httpFetcher
+	mu       sync.Mutex
+	cache    map[string]urlData
+	rejected []SatelliteErr
+}
+
+// urlData satellites and rejected records of the last successful download of a URL
+type urlData struct {
+	satellites []Satellite
+	rejected   []SatelliteErr
+}
+
+// NewURLSource Generates a new URLSource from the information in the configuration file
+func NewURLSource(config URLSourceConfiguration) (*URLSource, error) {
+	if len(config.URLs) == 0 {
+		return nil, fmt.Errorf("no URL configured")
+	}
+	for _, u := range config.URLs {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return nil, redactURL(err)
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return nil, fmt.Errorf("invalid URL %v: only http and https are supported", origin(u))
+		}
+	}
+
+	format := config.Format
+	if format == "" {
+		format = FormatAuto
+	}
+	if !IsValidFormat(format) {
+		return nil, fmt.Errorf("unsupported format %v", format)
+	}
+
+	httpConfig := config.HTTP
+	name, value, err := config.Auth.header()
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		headers := make(map[string]string, len(httpConfig.Headers)+1)
+		for k, v := range httpConfig.Headers {
+			headers[k] = v
+		}
+		headers[name] = value
+		httpConfig.Headers = headers
+	}
+
+	fetcher, err := newHTTPFetcher(httpConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &URLSource{
+		urls:    config.URLs,
+		format:  format,
+		fetcher: fetcher,
+		cache:   make(map[string]urlData),
+	}, nil
+}
+
+// GetData downloads and parses the data of each URL. ErrNotModified is returned if none of the URLs changed since the last call.
+// With several URLs, the last data of the URLs which cannot be downloaded is kept, and the error is reported in the rejected records.
+func (us *URLSource) GetData() ([]Satellite, error) {
+	var failures []SatelliteErr
+	modified := false
+
+	for _, u := range us.urls {
+		data, err := us.download(u)
+		if errors.Is(err, ErrNotModified) {
+			continue
+		}
+		if err != nil {
+			if len(us.urls) == 1 {
+				return nil, err
+			}
+			log.Printf("url source: %v\n", err)
+			failures = append(failures, SatelliteErr{Err: err, Sat: Satellite{Origin: origin(u)}})
+			continue
+		}
+
+		us.mu.Lock()
+		us.cache[u] = data
+		us.mu.Unlock()
+		modified = true
+	}
+
+	if len(failures) == len(us.urls) {
+		return nil, fmt.Errorf("all the source URLs failed, first error: %w", failures[0].Err)
+	}
+	if !modified && len(failures) == 0 {
+		return nil, ErrNotModified
+	}
+
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	var output []Satellite
+	rejected := failures
+	for _, u := range us.urls {
+		output = append(output, us.cache[u].satellites...)
+		rejected = append(rejected, us.cache[u].rejected...)
+	}
+	us.rejected = rejected
+
+	return output, nil
+}
+
+func (us *URLSource) GetDataSource() string {
+	return "url"
+}
+
+func (us *URLSource) GetConfig() (map[string]interface{}, error) {
+	return nil, nil
+}
+
+// Rejected return the records which could not be parsed, and the URLs which could not be downloaded, during the last GetData call
+func (us *URLSource) Rejected() []SatelliteErr {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	return us.rejected
+}
+
+// download fetches and parses the content of a URL
+func (us *URLSource) download(u string) (urlData, error) {
+	content, err := us.fetcher.fetch(u)
+	if err != nil {
+		return urlData{}, err
+	}
+
+	data, err := us.parse(u, content)
+	if err != nil {
+		// Download the data again next time, instead of getting a not modified response
+		us.fetcher.forget(u)
+		return urlData{}, fmt.Errorf("%s: %w", origin(u), err)
+	}
+
+	return data, nil
+}
+
+// parse reads the satellites of the downloaded content, expanding compressed data and archives
+func (us *URLSource) parse(u string, content []byte) (urlData, error) {
+	path := u
+	if parsed, err := url.Parse(u); err == nil {
+		path = parsed.Path
+	}
+
+	entries, err := expandContent(path, content)
+	if err != nil {
+		return urlData{}, err
+	}
+
+	var data urlData
+	for _, entry := range entries {
+		sats, rejected, err := parseContent(us.format, entry.name, entry.content)
+		if err != nil {
+			if len(entries) == 1 {
+				return urlData{}, err
+			}
+			data.rejected = append(data.rejected, SatelliteErr{Err: fmt.Errorf("%s: %w", entry.name, err)})
+			continue
+		}
+		data.satellites = append(data.satellites, sats...)
+		data.rejected = append(data.rejected, rejected...)
+	}
+
+	if len(us.urls) > 1 {
+		for i := range data.satellites {
+			data.satellites[i].Origin = origin(u)
+		}
+		for i := range data.rejected {
+			data.rejected[i].Sat.Origin = origin(u)
+		}
+	}
+
+	return data, nil
+}
+
+// origin returns the URL without credentials and query parameters, which may contain API keys
+func origin(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "invalid URL"
+	}
+	parsed.User = nil
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+	return parsed.String()
+}
+
+// redactURL replaces the URL of the errors returned by net/url and net/http by its origin, as the full URL may contain
+// credentials and API keys
+func redactURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = origin(urlErr.URL)
+	}
+	return err
+}
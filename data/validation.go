@@ -0,0 +1,140 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrDuplicate error of the records rejected because a more recent element set is available for the same NORAD ID
+var ErrDuplicate = errors.New("duplicate NORAD ID")
+
+var (
+	// firstLaunch epoch of Sputnik 1, no element set can be older
+	firstLaunch = time.Date(1957, time.October, 4, 0, 0, 0, 0, time.UTC)
+	// maxEpochInFuture tolerance for element sets with an epoch in the future, for predicted element sets
+	maxEpochInFuture = 7 * 24 * time.Hour
+	// maxOverrideEpochInFuture tolerance for the element sets uploaded or generated manually, which may be placeholders
+	// of planned launches
+	maxOverrideEpochInFuture = 2 * 365 * 24 * time.Hour
+)
+
+// ValidateSatellite checks the element set of a satellite: line format, checksums, consistency between the lines, field ranges and epoch
+func ValidateSatellite(sat Satellite, now time.Time) error {
+	return validateSatellite(sat, now, maxEpochInFuture)
+}
+
+// ValidateOverride checks the element set of an override like ValidateSatellite, the epoch being allowed up to two years
+// in the future for the element sets of planned launches
+func ValidateOverride(sat Satellite, now time.Time) error {
+	return validateSatellite(sat, now, maxOverrideEpochInFuture)
+}
+
+func validateSatellite(sat Satellite, now time.Time, maxInFuture time.Duration) error {
+	for i, line := range []string{sat.TLELine1, sat.TLELine2} {
+		if len(line) != 69 {
+			return fmt.Errorf("TLE line %v has wrong format, expected 69 characters, got %v", i+1, len(line))
+		}
+		if line[0] != byte('1'+i) {
+			return fmt.Errorf("TLE line %v has wrong line number %q", i+1, line[0])
+		}
+
+		checksum, err := checksumAsString(line[:68])
+		if err != nil {
+			return err
+		}
+		if checksum[0] != line[68] {
+			return fmt.Errorf("TLE line %v has wrong checksum, expected %s, got %c", i+1, checksum, line[68])
+		}
+	}
+
+	if sat.TLELine1[2:7] != sat.TLELine2[2:7] {
+		return fmt.Errorf("NORAD ID differs between line 1 (%s) and line 2 (%s)", sat.TLELine1[2:7], sat.TLELine2[2:7])
+	}
+
+	el, err := ParseElements(sat)
+	if err != nil {
+		return err
+	}
+
+	if el.NORADID != sat.NORADID {
+		return fmt.Errorf("NORAD ID %v of the TLE lines differs from the satellite NORAD ID %v", el.NORADID, sat.NORADID)
+	}
+
+	ranges := []struct {
+		name     string
+		value    float64
+		min, max float64
+	}{
+		{"inclination", el.Inclination, 0, 180},
+		{"right ascension of the ascending node", el.RAAN, 0, 360},
+		{"argument of pericenter", el.ArgOfPericenter, 0, 360},
+		{"mean anomaly", el.MeanAnomaly, 0, 360},
+		{"eccentricity", el.Eccentricity, 0, 1},
+		{"mean motion", el.MeanMotion, 0, 20},
+	}
+	for _, r := range ranges {
+		if r.value < r.min || r.value > r.max {
+			return fmt.Errorf("%s %v out of range [%v, %v]", r.name, r.value, r.min, r.max)
+		}
+	}
+	if el.MeanMotion == 0 {
+		return fmt.Errorf("mean motion is zero")
+	}
+
+	if el.Epoch.Before(firstLaunch) {
+		return fmt.Errorf("epoch %s is before the first satellite launch", el.Epoch.Format(time.RFC3339))
+	}
+	if el.Epoch.After(now.Add(maxInFuture)) {
+		return fmt.Errorf("epoch %s is in the future", el.Epoch.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// ValidateAll validates each satellite individually, returning the valid satellites and the rejected ones with the reason.
+// When several element sets have the same NORAD ID, the most recent one is kept and the others are rejected as duplicates.
+func ValidateAll(sats []Satellite, now time.Time) ([]Satellite, []SatelliteErr) {
+	var rejected []SatelliteErr
+
+	type candidate struct {
+		index int
+		epoch time.Time
+	}
+	kept := make(map[int]candidate)
+
+	for i, sat := range sats {
+		if err := ValidateSatellite(sat, now); err != nil {
+			rejected = append(rejected, SatelliteErr{Err: err, Sat: sat})
+			continue
+		}
+
+		el, _ := ParseElements(sat)
+		previous, ok := kept[sat.NORADID]
+		if !ok {
+			kept[sat.NORADID] = candidate{index: i, epoch: el.Epoch}
+			continue
+		}
+
+		duplicate := sat
+		if el.Epoch.After(previous.epoch) {
+			duplicate = sats[previous.index]
+			kept[sat.NORADID] = candidate{index: i, epoch: el.Epoch}
+		}
+		rejected = append(rejected, SatelliteErr{Err: fmt.Errorf("%w %v, a more recent element set is available", ErrDuplicate, sat.NORADID), Sat: duplicate})
+	}
+
+	indexes := make([]int, 0, len(kept))
+	for _, c := range kept {
+		indexes = append(indexes, c.index)
+	}
+	sort.Ints(indexes)
+
+	valid := make([]Satellite, 0, len(indexes))
+	for _, i := range indexes {
+		valid = append(valid, sats[i])
+	}
+
+	return valid, rejected
+}
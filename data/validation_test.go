@@ -0,0 +1,148 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateSatellite(t *testing.T) {
+	now := time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC)
+	valid := Satellite{
+		SatelliteName: "ONEWEB-0012",
+		NORADID:       44057,
+		TLELine1:      "1 44057U 19010A   22206.81764082 -.00000043  00000+0 -14585-3 0  9993",
+		TLELine2:      "2 44057  87.9150 151.8950 0002369 106.7932 253.3459 13.16592117164401",
+	}
+
+	tests := []struct {
+		name    string
+		line1   string
+		line2   string
+		noradID int
+		now     time.Time
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			wantErr: "",
+		},
+		{
+			name:    "wrong length",
+			line1:   valid.TLELine1[:68],
+			wantErr: "expected 69 characters",
+		},
+		{
+			name:    "wrong checksum",
+			line2:   valid.TLELine2[:68] + "2",
+			wantErr: "wrong checksum",
+		},
+		{
+			name:    "wrong line number",
+			line1:   "3" + valid.TLELine1[1:68] + "5",
+			wantErr: "wrong line number",
+		},
+		{
+			name:    "NORAD ID mismatch between lines",
+			line2:   "2 44058  87.9150 151.8950 0002369 106.7932 253.3459 13.16592117164402",
+			wantErr: "differs between line 1",
+		},
+		{
+			name:    "NORAD ID mismatch with satellite",
+			noradID: 44058,
+			wantErr: "differs from the satellite NORAD ID",
+		},
+		{
+			name:    "inclination out of range",
+			line2:   "2 44057 187.9150 151.8950 0002369 106.7932 253.3459 13.16592117164402",
+			wantErr: "inclination",
+		},
+		{
+			name:    "epoch in the future",
+			now:     time.Date(2021, time.August, 1, 0, 0, 0, 0, time.UTC),
+			wantErr: "in the future",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sat := valid
+			if tt.line1 != "" {
+				sat.TLELine1 = tt.line1
+			}
+			if tt.line2 != "" {
+				sat.TLELine2 = tt.line2
+			}
+			if tt.noradID != 0 {
+				sat.NORADID = tt.noradID
+			}
+			if tt.now.IsZero() {
+				tt.now = now
+			}
+
+			err := ValidateSatellite(sat, tt.now)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateSatellite() error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateSatellite() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateOverride(t *testing.T) {
+	sat := Satellite{
+		SatelliteName: "ONEWEB-0012",
+		NORADID:       44057,
+		TLELine1:      "1 44057U 19010A   22206.81764082 -.00000043  00000+0 -14585-3 0  9993",
+		TLELine2:      "2 44057  87.9150 151.8950 0002369 106.7932 253.3459 13.16592117164401",
+	}
+	epoch := time.Date(2022, time.July, 25, 19, 37, 24, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantErr bool
+	}{
+		{"epoch in the past", epoch.AddDate(0, 1, 0), false},
+		{"planned epoch", epoch.AddDate(0, -6, 0), false},
+		{"epoch too far in the future", epoch.AddDate(-3, 0, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateOverride(sat, tt.now); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateOverride() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAll(t *testing.T) {
+	sats, err := NewFileSource("../samples/tle_validation_testing.txt").GetData()
+	if err != nil {
+		t.Fatalf("GetData() error = %v", err)
+	}
+
+	valid, rejected := ValidateAll(sats, time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC))
+
+	var validNames []string
+	for _, sat := range valid {
+		validNames = append(validNames, sat.SatelliteName)
+	}
+	if strings.Join(validNames, ",") != "CALSPHERE 1,ONEWEB-0012" {
+		t.Errorf("ValidateAll() valid = %v, want CALSPHERE 1 and ONEWEB-0012", validNames)
+	}
+
+	if len(rejected) != 2 {
+		t.Fatalf("ValidateAll() got %v rejected records, want 2", len(rejected))
+	}
+	if rejected[0].Sat.SatelliteName != "CALSPHERE 1 OLD" || !strings.Contains(rejected[0].Err.Error(), "duplicate") {
+		t.Errorf("ValidateAll() first rejected record = %v, want CALSPHERE 1 OLD duplicate", rejected[0])
+	}
+	if rejected[1].Sat.SatelliteName != "LAGEOS 1" || !strings.Contains(rejected[1].Err.Error(), "checksum") {
+		t.Errorf("ValidateAll() second rejected record = %v, want LAGEOS 1 wrong checksum", rejected[1])
+	}
+}
@@ -0,0 +1,207 @@
+// Package ephemeris generates ephemeris files from SGP4 propagations, in the CCSDS OEM, STK ephemeris and SP3 formats
+package ephemeris
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+// Ephemeris formats
+const (
+	FormatOEM = "oem"
+	FormatSTK = "stk"
+	FormatSP3 = "sp3"
+)
+
+// Reference frames of the ephemeris states
+const (
+	// FrameTEME frame of the SGP4 propagation
+	FrameTEME = "TEME"
+	// FrameITRF Earth fixed frame, with polar motion when the EOPs are known
+	FrameITRF = "ITRF"
+	// FrameGCRF geocentric celestial reference frame
+	FrameGCRF = "GCRF"
+	// FrameEME2000 mean equator and equinox of J2000
+	FrameEME2000 = "EME2000"
+)
+
+const (
+	// MaxPoints maximum number of states of an ephemeris
+	MaxPoints = 100000
+	// Originator name of the generator written in the files
+	Originator = "TLE-PROVIDER"
+)
+
+// framesName names of the ephemeris frames in the frames package
+var framesName = map[string]string{
+	FrameTEME:    frames.FrameTEME,
+	FrameITRF:    frames.FrameITRF,
+	FrameGCRF:    frames.FrameGCRF,
+	FrameEME2000: frames.FrameJ2000,
+}
+
+// Options parameters of an ephemeris generation
+type Options struct {
+	Start time.Time
+	End   time.Time
+	Step  time.Duration
+	// Frame reference frame of the states, the default frame of the format when empty
+	Frame string
+	// EOP Earth orientation parameters of the ITRF states, polar motion and UT1 being neglected when nil
+	EOP *frames.EOPTable
+}
+
+// Ephemeris states of a satellite in a reference frame, positions in km and velocities in km/s
+type Ephemeris struct {
+	SatelliteName string
+	NORADID       int
+	// ObjectID international designator in the YYYY-NNNP format, empty when unknown
+	ObjectID string
+	TLELine1 string
+	TLELine2 string
+	Frame    string
+	States   []sgp4.State
+	Created  time.Time
+}
+
+// ContentType media type of the ephemeris formats, which are all text files
+func ContentType(format string) (string, error) {
+	if _, err := Extension(format); err != nil {
+		return "", err
+	}
+	return "text/plain; charset=utf-8", nil
+}
+
+// Extension file extension of the ephemeris format
+func Extension(format string) (string, error) {
+	switch format {
+	case FormatOEM:
+		return ".oem", nil
+	case FormatSTK:
+		return ".e", nil
+	case FormatSP3:
+		return ".sp3", nil
+	default:
+		return "", fmt.Errorf("invalid format %v, expected oem, stk or sp3", format)
+	}
+}
+
+// DefaultFrame frame of the format when not specified: TEME, except for SP3 which only supports Earth fixed frames
+func DefaultFrame(format string) string {
+	if format == FormatSP3 {
+		return FrameITRF
+	}
+	return FrameTEME
+}
+
+// Validate checks the options of an ephemeris in the given format, the frame taking its default value
+func (o Options) Validate(format string) error {
+	if _, err := Extension(format); err != nil {
+		return err
+	}
+	switch o.Frame {
+	case "", FrameITRF:
+	case FrameTEME, FrameGCRF, FrameEME2000:
+		if format == FormatSP3 {
+			return fmt.Errorf("frame %v not supported by SP3, expected %v", o.Frame, FrameITRF)
+		}
+	default:
+		return fmt.Errorf("invalid frame %v, expected %v, %v, %v or %v", o.Frame, FrameTEME, FrameGCRF, FrameEME2000, FrameITRF)
+	}
+	if o.Step <= 0 {
+		return fmt.Errorf("step must be positive")
+	}
+	if !o.End.After(o.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+	if count := o.End.Sub(o.Start) / o.Step; count >= MaxPoints {
+		return fmt.Errorf("too many points (%d), the maximum is %d", count+1, MaxPoints)
+	}
+	return nil
+}
+
+// Points number of states of an ephemeris with the options, the end being always included
+func (o Options) Points() int {
+	points := int(o.End.Sub(o.Start) / o.Step)
+	if o.Start.Add(time.Duration(points) * o.Step).Before(o.End) {
+		points++
+	}
+	return points + 1
+}
+
+// New propagates the satellite from start to end with the step of the options, the end being always included
+func New(p *sgp4.Propagator, format string, options Options) (Ephemeris, error) {
+	if err := options.Validate(format); err != nil {
+		return Ephemeris{}, err
+	}
+	if options.Frame == "" {
+		options.Frame = DefaultFrame(format)
+	}
+
+	ephemeris := Ephemeris{
+		SatelliteName: p.Satellite.SatelliteName,
+		NORADID:       p.Satellite.NORADID,
+		TLELine1:      p.Satellite.TLELine1,
+		TLELine2:      p.Satellite.TLELine2,
+		Frame:         options.Frame,
+		States:        make([]sgp4.State, 0, options.Points()),
+		Created:       time.Now().UTC(),
+	}
+	if el, err := data.ParseElements(p.Satellite); err == nil {
+		ephemeris.ObjectID = objectID(el.InternationalDesig)
+	}
+
+	for t := options.Start; ; t = t.Add(options.Step) {
+		if t.After(options.End) {
+			t = options.End
+		}
+		state, err := p.Propagate(t)
+		if err != nil {
+			return Ephemeris{}, fmt.Errorf("propagation failed at %v: %w", t.Format(time.RFC3339), err)
+		}
+		eop, _ := options.EOP.At(t)
+		state.Position, state.Velocity, _ = frames.FromTEME(framesName[options.Frame], state.Position, state.Velocity, t, eop)
+		ephemeris.States = append(ephemeris.States, state)
+		if t.Equal(options.End) {
+			break
+		}
+	}
+
+	return ephemeris, nil
+}
+
+// Write writes the ephemeris in the given format
+func Write(w io.Writer, format string, ephemeris Ephemeris) error {
+	if len(ephemeris.States) == 0 {
+		return fmt.Errorf("empty ephemeris")
+	}
+	switch format {
+	case FormatOEM:
+		return WriteOEM(w, ephemeris)
+	case FormatSTK:
+		return WriteSTK(w, ephemeris)
+	case FormatSP3:
+		return WriteSP3(w, ephemeris)
+	default:
+		return fmt.Errorf("invalid format %v, expected oem, stk or sp3", format)
+	}
+}
+
+// objectID converts the international designator of a TLE, for example 19010A, to the YYYY-NNNP format
+func objectID(designator string) string {
+	designator = strings.TrimSpace(designator)
+	if len(designator) < 6 {
+		return ""
+	}
+	year := designator[:2]
+	if year >= "57" {
+		return "19" + year + "-" + designator[2:]
+	}
+	return "20" + year + "-" + designator[2:]
+}
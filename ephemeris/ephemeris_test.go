@@ -0,0 +1,194 @@
+package ephemeris
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+var oneweb = data.Satellite{
+	SatelliteName: "ONEWEB-0012",
+	NORADID:       44057,
+	TLELine1:      "1 44057U 19010A   22206.81764082 -.00000043  00000+0 -14585-3 0  9993",
+	TLELine2:      "2 44057  87.9150 151.8950 0002369 106.7932 253.3459 13.16592117164401",
+}
+
+func testPropagator(t *testing.T) *sgp4.Propagator {
+	t.Helper()
+	p, err := sgp4.New(oneweb)
+	if err != nil {
+		t.Fatalf("sgp4.New() error = %v", err)
+	}
+	return p
+}
+
+func TestNew(t *testing.T) {
+	p := testPropagator(t)
+	start := p.Epoch.Truncate(time.Minute)
+	day := start.Truncate(24 * time.Hour)
+	eop, err := frames.NewEOPTable([]frames.EOP{
+		{Date: day, XP: 0.2, YP: 0.35, DUT1: -0.05, LOD: 0.001},
+		{Date: day.Add(48 * time.Hour), XP: 0.21, YP: 0.34, DUT1: -0.052, LOD: 0.001},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		format     string
+		options    Options
+		wantPoints int
+		wantFrame  string
+		wantErr    bool
+	}{
+		{"one hour", FormatOEM, Options{Start: start, End: start.Add(time.Hour), Step: time.Minute}, 61, FrameTEME, false},
+		{"end not on a step", FormatSTK, Options{Start: start, End: start.Add(90 * time.Second), Step: time.Minute}, 3, FrameTEME, false},
+		{"SP3 default frame", FormatSP3, Options{Start: start, End: start.Add(time.Hour), Step: 5 * time.Minute}, 13, FrameITRF, false},
+		{"ITRF", FormatOEM, Options{Start: start, End: start.Add(time.Hour), Step: time.Minute, Frame: FrameITRF}, 61, FrameITRF, false},
+		{"ITRF with EOPs", FormatSP3, Options{Start: start, End: start.Add(time.Hour), Step: time.Minute, EOP: eop}, 61, FrameITRF, false},
+		{"GCRF", FormatSTK, Options{Start: start, End: start.Add(time.Hour), Step: time.Minute, Frame: FrameGCRF}, 61, FrameGCRF, false},
+		{"EME2000", FormatOEM, Options{Start: start, End: start.Add(time.Hour), Step: time.Minute, Frame: FrameEME2000}, 61, FrameEME2000, false},
+		{"SP3 in TEME", FormatSP3, Options{Start: start, End: start.Add(time.Hour), Step: time.Minute, Frame: FrameTEME}, 0, "", true},
+		{"unknown frame", FormatOEM, Options{Start: start, End: start.Add(time.Hour), Step: time.Minute, Frame: "ICRF"}, 0, "", true},
+		{"unknown format", "txt", Options{Start: start, End: start.Add(time.Hour), Step: time.Minute}, 0, "", true},
+		{"end before start", FormatOEM, Options{Start: start, End: start.Add(-time.Hour), Step: time.Minute}, 0, "", true},
+		{"null step", FormatOEM, Options{Start: start, End: start.Add(time.Hour)}, 0, "", true},
+		{"too many points", FormatOEM, Options{Start: start, End: start.Add(48 * time.Hour), Step: time.Second}, 0, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ephemeris, err := New(p, tt.format, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(ephemeris.States) != tt.wantPoints || tt.options.Points() != tt.wantPoints || ephemeris.Frame != tt.wantFrame {
+				t.Fatalf("expected %d states in %v, got %d in %v", tt.wantPoints, tt.wantFrame, len(ephemeris.States), ephemeris.Frame)
+			}
+			if ephemeris.ObjectID != "2019-010A" || !ephemeris.States[len(ephemeris.States)-1].Time.Equal(tt.options.End) {
+				t.Errorf("unexpected object ID %v or last state %v", ephemeris.ObjectID, ephemeris.States[len(ephemeris.States)-1].Time)
+			}
+
+			state, _ := p.Propagate(tt.options.Start)
+			parameters, _ := tt.options.EOP.At(tt.options.Start)
+			want, _, _ := frames.FromTEME(framesName[tt.wantFrame], state.Position, state.Velocity, tt.options.Start, parameters)
+			if got := ephemeris.States[0].Position; distance(got, want) > 1e-9 {
+				t.Errorf("first position = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	p := testPropagator(t)
+	start := time.Date(2022, 7, 26, 12, 0, 0, 0, time.UTC)
+	options := Options{Start: start, End: start.Add(10 * time.Minute), Step: time.Minute}
+	first, _ := p.Propagate(start)
+
+	tests := []struct {
+		name   string
+		format string
+		check  func(t *testing.T, lines []string)
+	}{
+		{
+			name:   "OEM",
+			format: FormatOEM,
+			check: func(t *testing.T, lines []string) {
+				for _, want := range []string{"CCSDS_OEM_VERS = 2.0", "OBJECT_NAME = ONEWEB-0012", "OBJECT_ID = 2019-010A", "REF_FRAME = TEME",
+					"START_TIME = 2022-07-26T12:00:00.000", "STOP_TIME = 2022-07-26T12:10:00.000"} {
+					if !contains(lines, want) {
+						t.Errorf("missing %q", want)
+					}
+				}
+				fields := strings.Fields(lines[len(lines)-11])
+				if len(fields) != 7 || fields[0] != "2022-07-26T12:00:00.000" || !close(fields[1:4], first.Position, 1e-6) || !close(fields[4:7], first.Velocity, 1e-9) {
+					t.Errorf("invalid first state %q", lines[len(lines)-11])
+				}
+			},
+		},
+		{
+			name:   "STK",
+			format: FormatSTK,
+			check: func(t *testing.T, lines []string) {
+				for _, want := range []string{"stk.v.11.0", "BEGIN Ephemeris", "NumberOfEphemerisPoints 11", "ScenarioEpoch           26 Jul 2022 12:00:00.000000",
+					"CoordinateSystem        TEMEOfDate", "EphemerisTimePosVel", "END Ephemeris"} {
+					if !contains(lines, want) {
+						t.Errorf("missing %q", want)
+					}
+				}
+				fields := strings.Fields(lines[len(lines)-3])
+				if len(fields) != 7 || fields[0] != "6.000000000e+02" {
+					t.Errorf("invalid last state %q", lines[len(lines)-3])
+				}
+			},
+		},
+		{
+			name:   "SP3",
+			format: FormatSP3,
+			check: func(t *testing.T, lines []string) {
+				if lines[0] != "#dV2022  7 26 12  0  0.00000000      11 ORBIT ITRF  FIT  TLEP" {
+					t.Errorf("invalid first line %q", lines[0])
+				}
+				if lines[1] != "## 2220 216000.00000000    60.00000000 59786 0.5000000000000" {
+					t.Errorf("invalid second line %q", lines[1])
+				}
+				if lines[len(lines)-1] != "EOF" || len(lines) != 2+10+6+4+3*11+1 {
+					t.Errorf("unexpected file end or length %d", len(lines))
+				}
+				position := lines[len(lines)-3]
+				if len(position) != 60 || position[:4] != "PL01" {
+					t.Errorf("invalid position line %q", position)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ephemeris, err := New(p, tt.format, options)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.format, ephemeris); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			tt.check(t, strings.Split(strings.TrimRight(buf.String(), "\n"), "\n"))
+		})
+	}
+}
+
+func contains(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+// close true if the fields parse to the values within the tolerance
+func close(fields []string, values [3]float64, tolerance float64) bool {
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil || math.Abs(value-values[i]) > tolerance {
+			return false
+		}
+	}
+	return true
+}
+
+func distance(a, b [3]float64) float64 {
+	return math.Sqrt((a[0]-b[0])*(a[0]-b[0]) + (a[1]-b[1])*(a[1]-b[1]) + (a[2]-b[2])*(a[2]-b[2]))
+}
@@ -0,0 +1,167 @@
+package ephemeris
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// oemTimeFormat epoch format of the CCSDS OEM, in UTC
+const oemTimeFormat = "2006-01-02T15:04:05.000"
+
+// WriteOEM writes the ephemeris as a CCSDS Orbit Ephemeris Message (CCSDS 502.0-B-2) in the KVN format,
+// positions in km and velocities in km/s, in the UTC time system
+func WriteOEM(w io.Writer, ephemeris Ephemeris) error {
+	bw := bufio.NewWriter(w)
+	start, stop := ephemeris.States[0].Time, ephemeris.States[len(ephemeris.States)-1].Time
+
+	fmt.Fprintln(bw, "CCSDS_OEM_VERS = 2.0")
+	fmt.Fprintf(bw, "CREATION_DATE = %s\n", ephemeris.Created.UTC().Format(oemTimeFormat))
+	fmt.Fprintf(bw, "ORIGINATOR = %s\n", Originator)
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "META_START")
+	fmt.Fprintf(bw, "OBJECT_NAME = %s\n", ephemeris.SatelliteName)
+	objectID := ephemeris.ObjectID
+	if objectID == "" {
+		objectID = "UNKNOWN"
+	}
+	fmt.Fprintf(bw, "OBJECT_ID = %s\n", objectID)
+	fmt.Fprintln(bw, "CENTER_NAME = EARTH")
+	fmt.Fprintf(bw, "REF_FRAME = %s\n", ephemeris.Frame)
+	fmt.Fprintln(bw, "TIME_SYSTEM = UTC")
+	fmt.Fprintf(bw, "START_TIME = %s\n", start.UTC().Format(oemTimeFormat))
+	fmt.Fprintf(bw, "STOP_TIME = %s\n", stop.UTC().Format(oemTimeFormat))
+	fmt.Fprintln(bw, "META_STOP")
+	fmt.Fprintln(bw)
+	fmt.Fprintf(bw, "COMMENT Propagated with SGP4 from the TLE of NORAD ID %d\n", ephemeris.NORADID)
+	fmt.Fprintf(bw, "COMMENT %s\n", ephemeris.TLELine1)
+	fmt.Fprintf(bw, "COMMENT %s\n", ephemeris.TLELine2)
+	for _, state := range ephemeris.States {
+		fmt.Fprintf(bw, "%s %.6f %.6f %.6f %.9f %.9f %.9f\n", state.Time.UTC().Format(oemTimeFormat),
+			state.Position[0], state.Position[1], state.Position[2],
+			state.Velocity[0], state.Velocity[1], state.Velocity[2])
+	}
+
+	return bw.Flush()
+}
+
+// stkCoordinateSystem name of the frame in the STK ephemeris format
+func stkCoordinateSystem(frame string) string {
+	switch frame {
+	case FrameITRF:
+		return "Fixed"
+	case FrameGCRF:
+		return "ICRF"
+	case FrameEME2000:
+		return "J2000"
+	default:
+		return "TEMEOfDate"
+	}
+}
+
+// WriteSTK writes the ephemeris in the STK ephemeris (.e) format, positions in m and velocities in m/s,
+// the times being in seconds from the first state
+func WriteSTK(w io.Writer, ephemeris Ephemeris) error {
+	bw := bufio.NewWriter(w)
+	epoch := ephemeris.States[0].Time.UTC()
+
+	fmt.Fprintln(bw, "stk.v.11.0")
+	fmt.Fprintln(bw)
+	fmt.Fprintf(bw, "# WrittenBy    %s\n", Originator)
+	fmt.Fprintf(bw, "# Satellite    %s (NORAD ID %d), propagated with SGP4\n", ephemeris.SatelliteName, ephemeris.NORADID)
+	fmt.Fprintf(bw, "# %s\n", ephemeris.TLELine1)
+	fmt.Fprintf(bw, "# %s\n", ephemeris.TLELine2)
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "BEGIN Ephemeris")
+	fmt.Fprintln(bw)
+	fmt.Fprintf(bw, "NumberOfEphemerisPoints %d\n", len(ephemeris.States))
+	fmt.Fprintf(bw, "ScenarioEpoch           %s\n", epoch.Format("2 Jan 2006 15:04:05.000000"))
+	fmt.Fprintln(bw, "InterpolationMethod     Lagrange")
+	fmt.Fprintln(bw, "InterpolationSamplesM1  7")
+	fmt.Fprintln(bw, "CentralBody             Earth")
+	fmt.Fprintf(bw, "CoordinateSystem        %s\n", stkCoordinateSystem(ephemeris.Frame))
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "EphemerisTimePosVel")
+	fmt.Fprintln(bw)
+	for _, state := range ephemeris.States {
+		fmt.Fprintf(bw, "%.9e %.9e %.9e %.9e %.9e %.9e %.9e\n", state.Time.Sub(epoch).Seconds(),
+			state.Position[0]*1000, state.Position[1]*1000, state.Position[2]*1000,
+			state.Velocity[0]*1000, state.Velocity[1]*1000, state.Velocity[2]*1000)
+	}
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "END Ephemeris")
+
+	return bw.Flush()
+}
+
+const (
+	// sp3SatelliteID identifier of the satellite in the SP3 files, L for a low Earth orbiter
+	sp3SatelliteID = "L01"
+	// sp3BadClock value of an unknown clock correction
+	sp3BadClock = 999999.999999
+)
+
+// sp3Descriptors time system, floating point and integer descriptor lines of the SP3 header
+const sp3Descriptors = `%c L  cc UTC ccc cccc cccc cccc cccc ccccc ccccc ccccc ccccc
+%c cc cc ccc ccc cccc cccc cccc cccc ccccc ccccc ccccc ccccc
+%f  0.0000000  0.000000000  0.00000000000  0.000000000000000
+%f  0.0000000  0.000000000  0.00000000000  0.000000000000000
+%i    0    0    0    0      0      0      0      0         0
+%i    0    0    0    0      0      0      0      0         0
+`
+
+// gpsEpoch origin of the GPS weeks
+var gpsEpoch = time.Date(1980, 1, 6, 0, 0, 0, 0, time.UTC)
+
+// WriteSP3 writes the ephemeris in the SP3-d format with positions and velocities, positions in km and velocities in dm/s,
+// in the UTC time system. The satellite is identified as L01, and the clock corrections are unknown.
+func WriteSP3(w io.Writer, ephemeris Ephemeris) error {
+	bw := bufio.NewWriter(w)
+	states := ephemeris.States
+	start := states[0].Time.UTC()
+	interval := 0.0
+	if len(states) > 1 {
+		interval = states[1].Time.Sub(states[0].Time).Seconds()
+	}
+
+	sinceGPSEpoch := start.Sub(gpsEpoch)
+	week := int(sinceGPSEpoch / (7 * 24 * time.Hour))
+	secondsOfWeek := (sinceGPSEpoch - time.Duration(week)*7*24*time.Hour).Seconds()
+	mjd := float64(start.Unix())/86400 + 40587
+	mjdDay := math.Floor(mjd)
+
+	fmt.Fprintf(bw, "#dV%4d %2d %2d %2d %2d %11.8f %7d ORBIT %-5s FIT  %-4s\n",
+		start.Year(), int(start.Month()), start.Day(), start.Hour(), start.Minute(),
+		float64(start.Second())+float64(start.Nanosecond())/1e9, len(states), ephemeris.Frame, "TLEP")
+	fmt.Fprintf(bw, "## %4d %15.8f %14.8f %5d %15.13f\n", week, secondsOfWeek, interval, int(mjdDay), mjd-mjdDay)
+
+	// satellite identifiers and accuracies, on at least 5 lines of 17 satellites
+	fmt.Fprintf(bw, "+  %3d   %s%s\n", 1, sp3SatelliteID, strings.Repeat("  0", 16))
+	for i := 0; i < 4; i++ {
+		fmt.Fprintf(bw, "+        %s\n", strings.Repeat("  0", 17))
+	}
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(bw, "++       %s\n", strings.Repeat("  0", 17))
+	}
+	io.WriteString(bw, sp3Descriptors)
+	fmt.Fprintf(bw, "/* %s propagated with SGP4 by %s\n", ephemeris.SatelliteName, Originator)
+	fmt.Fprintf(bw, "/* NORAD ID %d\n", ephemeris.NORADID)
+	fmt.Fprintf(bw, "/* %s\n", ephemeris.TLELine1)
+	fmt.Fprintf(bw, "/* %s\n", ephemeris.TLELine2)
+
+	for _, state := range states {
+		t := state.Time.UTC()
+		fmt.Fprintf(bw, "*  %4d %2d %2d %2d %2d %11.8f\n", t.Year(), int(t.Month()), t.Day(), t.Hour(), t.Minute(),
+			float64(t.Second())+float64(t.Nanosecond())/1e9)
+		fmt.Fprintf(bw, "P%s%14.6f%14.6f%14.6f%14.6f\n", sp3SatelliteID,
+			state.Position[0], state.Position[1], state.Position[2], sp3BadClock)
+		fmt.Fprintf(bw, "V%s%14.6f%14.6f%14.6f%14.6f\n", sp3SatelliteID,
+			state.Velocity[0]*1e4, state.Velocity[1]*1e4, state.Velocity[2]*1e4, sp3BadClock)
+	}
+	fmt.Fprintln(bw, "EOF")
+
+	return bw.Flush()
+}
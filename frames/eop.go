@@ -0,0 +1,160 @@
+package frames
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mjdEpoch origin of the modified julian dates
+var mjdEpoch = time.Date(1858, 11, 17, 0, 0, 0, 0, time.UTC)
+
+// EOP Earth orientation parameters: polar motion in arcseconds, UT1-UTC and excess length of day in seconds.
+// The zero value approximates UT1 by UTC and neglects polar motion.
+type EOP struct {
+	Date      time.Time `json:"date"`
+	XP        float64   `json:"xp"`
+	YP        float64   `json:"yp"`
+	DUT1      float64   `json:"dut1"`
+	LOD       float64   `json:"lod"`
+	Predicted bool      `json:"predicted"`
+}
+
+// EOPTable daily Earth orientation parameters, by date
+type EOPTable struct {
+	entries []EOP
+}
+
+// NewEOPTable creates a table from daily parameters, which are sorted by date
+func NewEOPTable(entries []EOP) (*EOPTable, error) {
+	if len(entries) < 2 {
+		return nil, fmt.Errorf("at least 2 EOP entries are required, got %d", len(entries))
+	}
+	sorted := append([]EOP(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+	return &EOPTable{entries: sorted}, nil
+}
+
+// ParseFinals reads the daily parameters of an IERS finals file (finals.all, finals.data, finals2000A.all or finals2000A.data).
+// The Bulletin A values are used, and the dates without UT1-UTC are skipped.
+func ParseFinals(r io.Reader) (*EOPTable, error) {
+	var entries []EOP
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if len(text) < 68 || strings.TrimSpace(text[58:68]) == "" {
+			continue
+		}
+
+		mjd, err := finalsField(text, 7, 15)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid MJD: %w", line, err)
+		}
+		entry := EOP{
+			Date:      mjdEpoch.Add(time.Duration(mjd * 24 * float64(time.Hour))),
+			Predicted: text[57] == 'P',
+		}
+		if entry.DUT1, err = finalsField(text, 58, 68); err != nil {
+			return nil, fmt.Errorf("line %d: invalid UT1-UTC: %w", line, err)
+		}
+		if entry.XP, err = finalsField(text, 18, 27); err != nil {
+			return nil, fmt.Errorf("line %d: invalid x pole: %w", line, err)
+		}
+		if entry.YP, err = finalsField(text, 37, 46); err != nil {
+			return nil, fmt.Errorf("line %d: invalid y pole: %w", line, err)
+		}
+		lod, err := finalsField(text, 79, 86)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid LOD: %w", line, err)
+		}
+		entry.LOD = lod / 1000
+
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewEOPTable(entries)
+}
+
+// LoadFinals reads the IERS finals file at the given path
+func LoadFinals(path string) (*EOPTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseFinals(file)
+}
+
+// finalsField reads a float field of a finals line, 0 when blank or beyond the end of the line
+func finalsField(line string, start, end int) (float64, error) {
+	if start >= len(line) {
+		return 0, nil
+	}
+	if end > len(line) {
+		end = len(line)
+	}
+	value := strings.TrimSpace(line[start:end])
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// Start date of the first parameters
+func (et *EOPTable) Start() time.Time {
+	return et.entries[0].Date
+}
+
+// End date of the last parameters
+func (et *EOPTable) End() time.Time {
+	return et.entries[len(et.entries)-1].Date
+}
+
+// LastObserved date of the last parameters which are not predicted, zero when they are all predicted
+func (et *EOPTable) LastObserved() time.Time {
+	for i := len(et.entries) - 1; i >= 0; i-- {
+		if !et.entries[i].Predicted {
+			return et.entries[i].Date
+		}
+	}
+	return time.Time{}
+}
+
+// At interpolates the parameters linearly at the given time. Outside the dates of the table, or with a nil table,
+// it returns the zero parameters and false.
+func (et *EOPTable) At(t time.Time) (EOP, bool) {
+	if et == nil || t.Before(et.Start()) || t.After(et.End()) {
+		return EOP{}, false
+	}
+
+	i := sort.Search(len(et.entries), func(i int) bool { return et.entries[i].Date.After(t) })
+	if i == len(et.entries) {
+		return et.entries[i-1], true
+	}
+	previous, next := et.entries[i-1], et.entries[i]
+	ratio := float64(t.Sub(previous.Date)) / float64(next.Date.Sub(previous.Date))
+
+	// UT1-UTC jumps by one second at the leap seconds, which happen at the end of the previous day
+	nextDUT1 := next.DUT1
+	if jump := nextDUT1 - previous.DUT1; math.Abs(jump) > 0.5 {
+		nextDUT1 -= math.Round(jump)
+	}
+
+	return EOP{
+		Date:      t,
+		XP:        previous.XP + ratio*(next.XP-previous.XP),
+		YP:        previous.YP + ratio*(next.YP-previous.YP),
+		DUT1:      previous.DUT1 + ratio*(nextDUT1-previous.DUT1),
+		LOD:       previous.LOD + ratio*(next.LOD-previous.LOD),
+		Predicted: previous.Predicted || next.Predicted,
+	}, true
+}
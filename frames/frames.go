@@ -0,0 +1,103 @@
+// Package frames converts positions from the TEME frame used by SGP4 to the J2000, GCRF and Earth fixed frames and to WGS84
+// geodetic coordinates, with the IAU-76/FK5 reduction and optional Earth orientation parameters, and computes the look angles
+// of satellites and the position of the Sun
+package frames
+
+import (
+	"math"
+	"time"
+)
+
+// WGS84 ellipsoid
+const (
+	// EarthRadius WGS84 equatorial radius in km
+	EarthRadius = 6378.137
+	// EarthFlattening WGS84 flattening
+	EarthFlattening = 1 / 298.257223563
+	// EarthRotationRate Earth rotation rate in rad/s
+	EarthRotationRate = 7.292115146706979e-5
+)
+
+var earthEccSq = EarthFlattening * (2 - EarthFlattening)
+
+// Geodetic WGS84 coordinates, latitude and longitude in degrees, altitude in km
+type Geodetic struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Altitude  float64 `json:"altitude"`
+}
+
+// JulianDate julian date of the time
+func JulianDate(t time.Time) float64 {
+	return float64(t.UnixNano())/86400e9 + 2440587.5
+}
+
+// GMST Greenwich mean sidereal time in radians, IAU-82 model, UT1 approximated by UTC
+func GMST(t time.Time) float64 {
+	tut1 := (JulianDate(t) - 2451545.0) / 36525.0
+	seconds := -6.2e-6*tut1*tut1*tut1 + 0.093104*tut1*tut1 + (876600.0*3600+8640184.812866)*tut1 + 67310.54841
+	gmst := math.Mod(seconds*math.Pi/180/240, 2*math.Pi)
+	if gmst < 0 {
+		gmst += 2 * math.Pi
+	}
+	return gmst
+}
+
+// TEMEToECEF rotates a position in km and velocity in km/s from TEME to the Earth fixed frame, UT1 being approximated by UTC
+// and polar motion being neglected
+func TEMEToECEF(position, velocity [3]float64, t time.Time) ([3]float64, [3]float64) {
+	return TEMEToITRF(position, velocity, t, EOP{})
+}
+
+// ECEFToGeodetic converts an Earth fixed position in km to WGS84 geodetic coordinates
+func ECEFToGeodetic(r [3]float64) Geodetic {
+	p := math.Hypot(r[0], r[1])
+	lon := math.Atan2(r[1], r[0])
+
+	// iterative solution, converging to well below a millimeter in a few iterations
+	lat := math.Atan2(r[2], p*(1-earthEccSq))
+	var alt float64
+	for i := 0; i < 10; i++ {
+		sinLat := math.Sin(lat)
+		n := EarthRadius / math.Sqrt(1-earthEccSq*sinLat*sinLat)
+		previous := lat
+		lat = math.Atan2(r[2]+n*earthEccSq*sinLat, p)
+		if math.Abs(lat-previous) < 1e-12 {
+			break
+		}
+	}
+
+	sinLat, cosLat := math.Sin(lat), math.Cos(lat)
+	n := EarthRadius / math.Sqrt(1-earthEccSq*sinLat*sinLat)
+	if cosLat > 1e-10 {
+		alt = p/cosLat - n
+	} else {
+		alt = math.Abs(r[2]) - n*(1-earthEccSq)
+	}
+
+	return Geodetic{
+		Latitude:  lat * 180 / math.Pi,
+		Longitude: lon * 180 / math.Pi,
+		Altitude:  alt,
+	}
+}
+
+// GeodeticToECEF converts WGS84 geodetic coordinates to an Earth fixed position in km
+func GeodeticToECEF(g Geodetic) [3]float64 {
+	lat := g.Latitude * math.Pi / 180
+	lon := g.Longitude * math.Pi / 180
+	sinLat, cosLat := math.Sin(lat), math.Cos(lat)
+	n := EarthRadius / math.Sqrt(1-earthEccSq*sinLat*sinLat)
+
+	return [3]float64{
+		(n + g.Altitude) * cosLat * math.Cos(lon),
+		(n + g.Altitude) * cosLat * math.Sin(lon),
+		(n*(1-earthEccSq) + g.Altitude) * sinLat,
+	}
+}
+
+// TEMEToGeodetic converts a TEME position in km to WGS84 geodetic coordinates
+func TEMEToGeodetic(position [3]float64, t time.Time) Geodetic {
+	r, _ := TEMEToECEF(position, [3]float64{}, t)
+	return ECEFToGeodetic(r)
+}
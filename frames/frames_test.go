@@ -0,0 +1,320 @@
+package frames
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGMST(t *testing.T) {
+	tests := []struct {
+		name string
+		time time.Time
+		want float64
+	}{
+		{"J2000", time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC), 280.46061837},
+		{"one sidereal day later", time.Date(2000, 1, 2, 11, 56, 4, 90500000, time.UTC), 280.46061837},
+		{"Vallado example 3-5", time.Date(1992, 8, 20, 12, 14, 0, 0, time.UTC), 152.57878781},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GMST(tt.time) * 180 / math.Pi
+			if math.Abs(got-tt.want) > 1e-4 {
+				t.Errorf("GMST() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestECEFToGeodetic(t *testing.T) {
+	tests := []struct {
+		name string
+		r    [3]float64
+		want Geodetic
+	}{
+		{"equator", [3]float64{EarthRadius, 0, 0}, Geodetic{0, 0, 0}},
+		{"north pole", [3]float64{0, 0, 6356.752314245 + 100}, Geodetic{90, 0, 100}},
+		{"antimeridian", [3]float64{-EarthRadius - 500, 0, 0}, Geodetic{0, 180, 500}},
+		{"Vallado example 3-3", [3]float64{6524.834, 6862.875, 6448.296}, Geodetic{34.352496, 46.446416, 5085.22}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ECEFToGeodetic(tt.r)
+			if math.Abs(got.Latitude-tt.want.Latitude) > 1e-6 || math.Abs(got.Longitude-tt.want.Longitude) > 1e-6 || math.Abs(got.Altitude-tt.want.Altitude) > 1e-2 {
+				t.Errorf("ECEFToGeodetic() = %+v, want %+v", got, tt.want)
+			}
+
+			back := GeodeticToECEF(got)
+			for i := range back {
+				if math.Abs(back[i]-tt.r[i]) > 1e-6 {
+					t.Errorf("GeodeticToECEF() = %v, want %v", back, tt.r)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestTEMEToECEF(t *testing.T) {
+	at := time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC)
+	gmst := GMST(at)
+
+	// a point of the TEME x axis rotated by the sidereal time, and at rest in the Earth fixed frame
+	position := [3]float64{7000 * math.Cos(gmst), 7000 * math.Sin(gmst), 100}
+	velocity := [3]float64{-EarthRotationRate * position[1], EarthRotationRate * position[0], 0}
+
+	r, v := TEMEToECEF(position, velocity, at)
+	want := [3]float64{7000, 0, 100}
+	for i := range r {
+		if math.Abs(r[i]-want[i]) > 1e-6 || math.Abs(v[i]) > 1e-9 {
+			t.Fatalf("TEMEToECEF() = %v, %v, want %v at rest", r, v, want)
+		}
+	}
+}
+
+func TestFromTEME(t *testing.T) {
+	// Vallado, Fundamentals of Astrodynamics and Applications, example 3-15
+	at := time.Date(2004, 4, 6, 7, 51, 28, 386009000, time.UTC)
+	position := [3]float64{5094.18016210, 6127.64465950, 6380.34453270}
+	velocity := [3]float64{-4.746131487, 0.785818041, 5.531931288}
+	eop := EOP{XP: -0.140682, YP: 0.333309, DUT1: -0.4399619, LOD: 0.0015563}
+
+	tests := []struct {
+		name         string
+		frame        string
+		wantPosition [3]float64
+		wantVelocity [3]float64
+		// tolerance in km, the GCRF reference including the IERS nutation corrections which do not apply to TEME
+		tolerance float64
+		wantErr   bool
+	}{
+		{"TEME", FrameTEME, position, velocity, 0, false},
+		{"ITRF", FrameITRF, [3]float64{-1033.4793830, 7901.2952754, 6380.3565958}, [3]float64{-3.225636520, -2.872451450, 5.531924446}, 1e-6, false},
+		{"GCRF", FrameGCRF, [3]float64{5102.508958, 6123.011401, 6378.136928}, [3]float64{-4.74322016, 0.79053650, 5.53375528}, 1e-3, false},
+		{"J2000", FrameJ2000, [3]float64{5102.509600, 6123.011520, 6378.136300}, [3]float64{-4.74322016, 0.79053650, 5.53375528}, 1e-3, false},
+		{"unknown frame", "icrf", [3]float64{}, [3]float64{}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, v, err := FromTEME(tt.frame, position, velocity, at, eop)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromTEME() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for i := range r {
+				if math.Abs(r[i]-tt.wantPosition[i]) > tt.tolerance || math.Abs(v[i]-tt.wantVelocity[i]) > tt.tolerance/1000 {
+					t.Fatalf("FromTEME() = %v, %v, want %v, %v", r, v, tt.wantPosition, tt.wantVelocity)
+				}
+			}
+		})
+	}
+}
+
+// finalsLine formats a line of an IERS finals file with the Bulletin A values
+func finalsLine(date time.Time, flag string, xp, yp, dut1, lod float64) string {
+	mjd := date.Sub(mjdEpoch).Hours() / 24
+	return fmt.Sprintf("%2d%2d%2d %8.2f %1s %9.6f%9.6f %9.6f%9.6f  %1s%10.7f%10.7f %7.4f%7.4f",
+		date.Year()%100, int(date.Month()), date.Day(), mjd, flag, xp, 0.0001, yp, 0.0001, flag, dut1, 0.00001, lod, 0.01)
+}
+
+func TestEOPTable(t *testing.T) {
+	day := time.Date(2016, 12, 30, 0, 0, 0, 0, time.UTC)
+	finals := strings.Join([]string{
+		finalsLine(day, "I", 0.1, 0.3, -0.4, 1.0),
+		finalsLine(day.AddDate(0, 0, 1), "I", 0.2, 0.4, -0.41, 1.2),
+		// leap second at the end of 2016-12-31
+		finalsLine(day.AddDate(0, 0, 2), "P", 0.3, 0.5, 0.58, 1.4),
+		// prediction without UT1-UTC
+		finalsLine(day.AddDate(0, 0, 3), "P", 0.4, 0.6, 0, 0)[:58],
+	}, "\n")
+
+	table, err := ParseFinals(strings.NewReader(finals))
+	if err != nil {
+		t.Fatalf("ParseFinals() error = %v", err)
+	}
+	if !table.Start().Equal(day) || !table.End().Equal(day.AddDate(0, 0, 2)) || !table.LastObserved().Equal(day.AddDate(0, 0, 1)) {
+		t.Fatalf("unexpected table range %v - %v, observed until %v", table.Start(), table.End(), table.LastObserved())
+	}
+
+	tests := []struct {
+		name   string
+		time   time.Time
+		want   EOP
+		wantOK bool
+	}{
+		{"first day", day, EOP{XP: 0.1, YP: 0.3, DUT1: -0.4, LOD: 0.001}, true},
+		{"interpolated", day.Add(6 * time.Hour), EOP{XP: 0.125, YP: 0.325, DUT1: -0.4025, LOD: 0.00105}, true},
+		{"leap second day", day.Add(36 * time.Hour), EOP{XP: 0.25, YP: 0.45, DUT1: -0.415, LOD: 0.0013, Predicted: true}, true},
+		{"before the table", day.Add(-time.Hour), EOP{}, false},
+		{"after the table", day.AddDate(0, 0, 3), EOP{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := table.At(tt.time)
+			if ok != tt.wantOK || math.Abs(got.XP-tt.want.XP) > 1e-9 || math.Abs(got.YP-tt.want.YP) > 1e-9 ||
+				math.Abs(got.DUT1-tt.want.DUT1) > 1e-9 || math.Abs(got.LOD-tt.want.LOD) > 1e-12 || got.Predicted != tt.want.Predicted {
+				t.Errorf("At() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	var nilTable *EOPTable
+	if _, ok := nilTable.At(day); ok {
+		t.Errorf("expected no parameters from a nil table")
+	}
+}
+
+func TestParseLeapSeconds(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantLatest  LeapSecond
+		wantExpires time.Time
+		wantErr     bool
+	}{
+		{
+			name: "IERS Leap_Second.dat",
+			content: `#  Value of TAI-UTC in second valid beetween the initial value until
+#  the epoch given on the next line. The last line reads that NO
+#  leap second was introduced since the corresponding date
+#
+#  File expires on 28 June 2027
+#
+#    MJD        Date        TAI-UTC (s)
+#           day month year
+#    ---    --------------   ------
+#
+    41317.0    1  1 1972       10
+    57204.0    1  7 2015       36
+    57754.0    1  1 2017       37
+`,
+			wantLatest:  LeapSecond{Date: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), TAIMinusUTC: 37},
+			wantExpires: time.Date(2027, 6, 28, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "leap-seconds.list",
+			content: `#	Updated through IERS Bulletin C
+#$	 3676924800
+#@	3960057600
+#
+2272060800	10	# 1 Jan 1972
+3644697600	36	# 1 Jul 2015
+3692217600	37	# 1 Jan 2017
+#h	16edd0f0 3666784f 37db6bdd e74ced87 59af48f1
+`,
+			wantLatest:  LeapSecond{Date: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), TAIMinusUTC: 37},
+			wantExpires: time.Date(2025, 6, 28, 0, 0, 0, 0, time.UTC),
+		},
+		{"empty", "# no data\n", LeapSecond{}, time.Time{}, true},
+		{"invalid line", "41317.0 1 1972 10\n", LeapSecond{}, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := ParseLeapSeconds(strings.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLeapSeconds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := table.Latest(); !got.Date.Equal(tt.wantLatest.Date) || got.TAIMinusUTC != tt.wantLatest.TAIMinusUTC {
+				t.Errorf("Latest() = %+v, want %+v", got, tt.wantLatest)
+			}
+			if !table.Expires.Equal(tt.wantExpires) {
+				t.Errorf("Expires = %v, want %v", table.Expires, tt.wantExpires)
+			}
+			if got := table.TAIMinusUTC(time.Date(2016, 12, 31, 23, 59, 59, 0, time.UTC)); got != 36 {
+				t.Errorf("TAIMinusUTC() before the 2017 leap second = %v, want 36", got)
+			}
+			if got := table.TAIMinusUTC(time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC)); got != 10 {
+				t.Errorf("TAIMinusUTC() before 1972 = %v, want 10", got)
+			}
+		})
+	}
+}
+
+func TestNewLookAngles(t *testing.T) {
+	observer := Geodetic{Latitude: 45, Longitude: 10, Altitude: 0.2}
+	o := GeodeticToECEF(observer)
+	overhead := GeodeticToECEF(Geodetic{Latitude: 45, Longitude: 10, Altitude: 500.2})
+	up := [3]float64{(overhead[0] - o[0]) / 500, (overhead[1] - o[1]) / 500, (overhead[2] - o[2]) / 500}
+
+	tests := []struct {
+		name     string
+		observer Geodetic
+		position [3]float64
+		velocity [3]float64
+		want     LookAngles
+	}{
+		{"overhead moving away", observer, overhead, up, LookAngles{Azimuth: 0, Elevation: 90, Range: 500, RangeRate: 1}},
+		{"east on the horizon", Geodetic{}, [3]float64{EarthRadius, 1000, 0}, [3]float64{0, 0, 7}, LookAngles{Azimuth: 90, Elevation: 0, Range: 1000}},
+		{"west above the horizon", Geodetic{}, [3]float64{EarthRadius + 1000, -1000, 0}, [3]float64{}, LookAngles{Azimuth: 270, Elevation: 45, Range: 1000 * math.Sqrt2}},
+		{"north below the horizon", observer, GeodeticToECEF(Geodetic{Latitude: 46, Longitude: 10, Altitude: 0.2}), [3]float64{}, LookAngles{Azimuth: 0, Elevation: -0.5, Range: 111.1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewLookAngles(tt.observer, tt.position, tt.velocity)
+			if math.Abs(got.Elevation-tt.want.Elevation) > 0.1 || math.Abs(got.Range-tt.want.Range) > 0.5 || math.Abs(got.RangeRate-tt.want.RangeRate) > 1e-6 {
+				t.Errorf("NewLookAngles() = %+v, want %+v", got, tt.want)
+			}
+			// the azimuth is undefined at the zenith
+			if diff := math.Mod(math.Abs(got.Azimuth-tt.want.Azimuth), 360); tt.want.Elevation < 89 && math.Min(diff, 360-diff) > 0.1 {
+				t.Errorf("NewLookAngles() azimuth = %v, want %v", got.Azimuth, tt.want.Azimuth)
+			}
+		})
+	}
+}
+
+func TestSunPosition(t *testing.T) {
+	tests := []struct {
+		name string
+		time time.Time
+		want [3]float64
+	}{
+		{"Vallado example 5-1", time.Date(2006, 4, 2, 0, 0, 0, 0, time.UTC), [3]float64{0.9771945, 0.1924424, 0.0834308}},
+		{"March equinox", time.Date(2026, 3, 20, 14, 46, 0, 0, time.UTC), [3]float64{0.996, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SunPosition(tt.time)
+			for i := range got {
+				if math.Abs(got[i]/AstronomicalUnit-tt.want[i]) > 1e-3 {
+					t.Errorf("SunPosition() = %v AU, want %v", [3]float64{got[0] / AstronomicalUnit, got[1] / AstronomicalUnit, got[2] / AstronomicalUnit}, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestSunElevation(t *testing.T) {
+	tests := []struct {
+		name     string
+		observer Geodetic
+		time     time.Time
+		min, max float64
+	}{
+		{"Greenwich noon at the June solstice", Geodetic{Latitude: 51.48}, time.Date(2026, 6, 21, 12, 2, 0, 0, time.UTC), 61.5, 62.5},
+		{"Greenwich midnight", Geodetic{Latitude: 51.48}, time.Date(2026, 6, 21, 0, 0, 0, 0, time.UTC), -16, -14},
+		{"equator noon at the equinox", Geodetic{Longitude: -90}, time.Date(2026, 3, 20, 18, 7, 0, 0, time.UTC), 89, 90},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SunElevation(tt.observer, tt.time, EOP{}); got < tt.min || got > tt.max {
+				t.Errorf("SunElevation() = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package frames
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ttMinusTAI constant difference between TT and TAI in seconds
+const ttMinusTAI = 32.184
+
+// ntpEpoch origin of the times of the leap-seconds.list files
+var ntpEpoch = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// LeapSecond TAI-UTC difference in seconds from the date of a leap second
+type LeapSecond struct {
+	Date        time.Time `json:"date"`
+	TAIMinusUTC float64   `json:"tai_minus_utc"`
+}
+
+// LeapSecondTable leap seconds by date, with the expiration date of the file they come from
+type LeapSecondTable struct {
+	entries []LeapSecond
+	// Expires date until which no other leap second can happen, zero when unknown
+	Expires time.Time
+}
+
+// builtinLeapSeconds leap seconds introduced since 1972, up to the one of the end of 2016
+var builtinLeapSeconds = []LeapSecond{
+	{time.Date(1972, 1, 1, 0, 0, 0, 0, time.UTC), 10},
+	{time.Date(1972, 7, 1, 0, 0, 0, 0, time.UTC), 11},
+	{time.Date(1973, 1, 1, 0, 0, 0, 0, time.UTC), 12},
+	{time.Date(1974, 1, 1, 0, 0, 0, 0, time.UTC), 13},
+	{time.Date(1975, 1, 1, 0, 0, 0, 0, time.UTC), 14},
+	{time.Date(1976, 1, 1, 0, 0, 0, 0, time.UTC), 15},
+	{time.Date(1977, 1, 1, 0, 0, 0, 0, time.UTC), 16},
+	{time.Date(1978, 1, 1, 0, 0, 0, 0, time.UTC), 17},
+	{time.Date(1979, 1, 1, 0, 0, 0, 0, time.UTC), 18},
+	{time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC), 19},
+	{time.Date(1981, 7, 1, 0, 0, 0, 0, time.UTC), 20},
+	{time.Date(1982, 7, 1, 0, 0, 0, 0, time.UTC), 21},
+	{time.Date(1983, 7, 1, 0, 0, 0, 0, time.UTC), 22},
+	{time.Date(1985, 7, 1, 0, 0, 0, 0, time.UTC), 23},
+	{time.Date(1988, 1, 1, 0, 0, 0, 0, time.UTC), 24},
+	{time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC), 25},
+	{time.Date(1991, 1, 1, 0, 0, 0, 0, time.UTC), 26},
+	{time.Date(1992, 7, 1, 0, 0, 0, 0, time.UTC), 27},
+	{time.Date(1993, 7, 1, 0, 0, 0, 0, time.UTC), 28},
+	{time.Date(1994, 7, 1, 0, 0, 0, 0, time.UTC), 29},
+	{time.Date(1996, 1, 1, 0, 0, 0, 0, time.UTC), 30},
+	{time.Date(1997, 7, 1, 0, 0, 0, 0, time.UTC), 31},
+	{time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC), 32},
+	{time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC), 33},
+	{time.Date(2009, 1, 1, 0, 0, 0, 0, time.UTC), 34},
+	{time.Date(2012, 7, 1, 0, 0, 0, 0, time.UTC), 35},
+	{time.Date(2015, 7, 1, 0, 0, 0, 0, time.UTC), 36},
+	{time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), 37},
+}
+
+var (
+	leapSecondsMu sync.RWMutex
+	leapSeconds   = BuiltinLeapSeconds()
+)
+
+// BuiltinLeapSeconds table of the leap seconds known at build time, without expiration date
+func BuiltinLeapSeconds() *LeapSecondTable {
+	return &LeapSecondTable{entries: builtinLeapSeconds}
+}
+
+// NewLeapSecondTable creates a table from leap seconds, which are sorted by date
+func NewLeapSecondTable(entries []LeapSecond, expires time.Time) (*LeapSecondTable, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no leap second")
+	}
+	sorted := append([]LeapSecond(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+	return &LeapSecondTable{entries: sorted, Expires: expires}, nil
+}
+
+// leapSecondExpiry expiration date of the IERS Leap_Second.dat files
+var leapSecondExpiry = regexp.MustCompile(`File expires on\s+(\d{1,2} \w+ \d{4})`)
+
+// ParseLeapSeconds reads an IERS Leap_Second.dat file, or a leap-seconds.list file as distributed by the IETF and NIST
+func ParseLeapSeconds(r io.Reader) (*LeapSecondTable, error) {
+	var entries []LeapSecond
+	var expires time.Time
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(text, "#@"):
+			seconds, err := strconv.ParseInt(strings.TrimSpace(text[2:]), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid expiration date: %w", line, err)
+			}
+			expires = ntpEpoch.Add(time.Duration(seconds) * time.Second)
+			continue
+		case strings.HasPrefix(text, "#"):
+			if match := leapSecondExpiry.FindStringSubmatch(text); match != nil {
+				date, err := time.Parse("2 January 2006", match[1])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid expiration date: %w", line, err)
+				}
+				expires = date
+			}
+			continue
+		case text == "":
+			continue
+		}
+
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		var entry LeapSecond
+		switch len(fields) {
+		case 2:
+			// leap-seconds.list: NTP time of the leap second and TAI-UTC
+			seconds, err := strconv.ParseInt(fields[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid date: %w", line, err)
+			}
+			entry.Date = ntpEpoch.Add(time.Duration(seconds) * time.Second)
+		case 5:
+			// Leap_Second.dat: MJD, day, month, year and TAI-UTC
+			date, err := time.Parse("2 1 2006", strings.Join(fields[1:4], " "))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid date: %w", line, err)
+			}
+			entry.Date = date
+		default:
+			return nil, fmt.Errorf("line %d: unexpected leap second format %q", line, text)
+		}
+		offset, err := strconv.ParseFloat(fields[len(fields)-1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid TAI-UTC: %w", line, err)
+		}
+		entry.TAIMinusUTC = offset
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewLeapSecondTable(entries, expires)
+}
+
+// LoadLeapSeconds reads a leap second file
+func LoadLeapSeconds(path string) (*LeapSecondTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseLeapSeconds(file)
+}
+
+// Latest last leap second of the table
+func (lt *LeapSecondTable) Latest() LeapSecond {
+	return lt.entries[len(lt.entries)-1]
+}
+
+// TAIMinusUTC difference between TAI and UTC in seconds at the given time, the first value of the table being used before 1972
+func (lt *LeapSecondTable) TAIMinusUTC(t time.Time) float64 {
+	i := sort.Search(len(lt.entries), func(i int) bool { return lt.entries[i].Date.After(t) })
+	if i == 0 {
+		return lt.entries[0].TAIMinusUTC
+	}
+	return lt.entries[i-1].TAIMinusUTC
+}
+
+// SetLeapSeconds replaces the leap seconds used by the conversions, the built-in table being used by default
+func SetLeapSeconds(table *LeapSecondTable) {
+	leapSecondsMu.Lock()
+	leapSeconds = table
+	leapSecondsMu.Unlock()
+}
+
+// LeapSeconds returns the leap seconds used by the conversions
+func LeapSeconds() *LeapSecondTable {
+	leapSecondsMu.RLock()
+	defer leapSecondsMu.RUnlock()
+	return leapSeconds
+}
+
+// ttMinusUTC difference between TT and UTC in seconds at the given time
+func ttMinusUTC(t time.Time) float64 {
+	return LeapSeconds().TAIMinusUTC(t) + ttMinusTAI
+}
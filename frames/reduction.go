@@ -0,0 +1,247 @@
+package frames
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Output frames of the propagated states
+const (
+	// FrameTEME true equator, mean equinox frame of the SGP4 propagation
+	FrameTEME = "teme"
+	// FrameJ2000 mean equator and equinox of J2000 (FK5), reached with the IAU-76 precession and IAU-80 nutation
+	FrameJ2000 = "j2000"
+	// FrameGCRF geocentric celestial reference frame, the J2000 frame corrected with the frame bias
+	FrameGCRF = "gcrf"
+	// FrameITRF international terrestrial reference frame, with polar motion when the EOPs are known
+	FrameITRF = "itrf"
+	// FrameECEF Earth fixed frame, alias of the ITRF
+	FrameECEF = "ecef"
+)
+
+const arcsecToRad = math.Pi / (180 * 3600)
+
+// matrix rotation matrix, applied to column vectors
+type matrix [3][3]float64
+
+func (m matrix) apply(v [3]float64) [3]float64 {
+	return [3]float64{
+		m[0][0]*v[0] + m[0][1]*v[1] + m[0][2]*v[2],
+		m[1][0]*v[0] + m[1][1]*v[1] + m[1][2]*v[2],
+		m[2][0]*v[0] + m[2][1]*v[1] + m[2][2]*v[2],
+	}
+}
+
+func (m matrix) times(n matrix) matrix {
+	var p matrix
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			p[i][j] = m[i][0]*n[0][j] + m[i][1]*n[1][j] + m[i][2]*n[2][j]
+		}
+	}
+	return p
+}
+
+func (m matrix) transpose() matrix {
+	var t matrix
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			t[i][j] = m[j][i]
+		}
+	}
+	return t
+}
+
+// rot1, rot2 and rot3 rotate the frame by the angle in radians around the x, y and z axes
+func rot1(a float64) matrix {
+	c, s := math.Cos(a), math.Sin(a)
+	return matrix{{1, 0, 0}, {0, c, s}, {0, -s, c}}
+}
+
+func rot2(a float64) matrix {
+	c, s := math.Cos(a), math.Sin(a)
+	return matrix{{c, 0, -s}, {0, 1, 0}, {s, 0, c}}
+}
+
+func rot3(a float64) matrix {
+	c, s := math.Cos(a), math.Sin(a)
+	return matrix{{c, s, 0}, {-s, c, 0}, {0, 0, 1}}
+}
+
+// julianCenturiesTT Julian centuries of TT since J2000
+func julianCenturiesTT(t time.Time) float64 {
+	return (JulianDate(t) + ttMinusUTC(t)/86400 - 2451545.0) / 36525.0
+}
+
+// precession IAU-76 precession matrix from the J2000 frame to the mean of date frame
+func precession(ttt float64) matrix {
+	zeta := ((0.017998*ttt+0.30188)*ttt + 2306.2181) * ttt * arcsecToRad
+	theta := ((-0.041833*ttt-0.42665)*ttt + 2004.3109) * ttt * arcsecToRad
+	z := ((0.018203*ttt+1.09468)*ttt + 2306.2181) * ttt * arcsecToRad
+	return rot3(-z).times(rot2(theta)).times(rot3(-zeta))
+}
+
+// meanObliquity IAU-80 mean obliquity of the ecliptic in radians
+func meanObliquity(ttt float64) float64 {
+	return (((0.001813*ttt-0.00059)*ttt-46.8150)*ttt + 84381.448) * arcsecToRad
+}
+
+// nutationTerm term of the IAU-80 nutation series: multipliers of the fundamental arguments l, l', F, D and Ω,
+// and coefficients of the longitude and obliquity in units of 0.1 mas, with their rates per Julian century
+type nutationTerm struct {
+	l, lp, f, d, om float64
+	psi, psiT       float64
+	eps, epsT       float64
+}
+
+// nutationSeries IAU-80 nutation series truncated to the terms above 0.5 mas, the remaining terms amounting to a few mas
+var nutationSeries = []nutationTerm{
+	{0, 0, 0, 0, 1, -171996, -174.2, 92025, 8.9},
+	{0, 0, 2, -2, 2, -13187, -1.6, 5736, -3.1},
+	{0, 0, 2, 0, 2, -2274, -0.2, 977, -0.5},
+	{0, 0, 0, 0, 2, 2062, 0.2, -895, 0.5},
+	{0, 1, 0, 0, 0, 1426, -3.4, 54, -0.1},
+	{1, 0, 0, 0, 0, 712, 0.1, -7, 0},
+	{0, 1, 2, -2, 2, -517, 1.2, 224, -0.6},
+	{0, 0, 2, 0, 1, -386, -0.4, 200, 0},
+	{1, 0, 2, 0, 2, -301, 0, 129, -0.1},
+	{0, -1, 2, -2, 2, 217, -0.5, -95, 0.3},
+	{1, 0, 0, -2, 0, -158, 0, -1, 0},
+	{0, 0, 2, -2, 1, 129, 0.1, -70, 0},
+	{-1, 0, 2, 0, 2, 123, 0, -53, 0},
+	{1, 0, 0, 0, 1, 63, 0.1, -33, 0},
+	{0, 0, 0, 2, 0, 63, 0, -2, 0},
+	{-1, 0, 2, 2, 2, -59, 0, 26, 0},
+	{-1, 0, 0, 0, 1, -58, -0.1, 32, 0},
+	{1, 0, 2, 0, 1, -51, 0, 27, 0},
+	{2, 0, 0, -2, 0, 48, 0, 1, 0},
+	{-2, 0, 2, 0, 1, 46, 0, -24, 0},
+	{0, 0, 2, 2, 2, -38, 0, 16, 0},
+	{2, 0, 2, 0, 2, -31, 0, 13, 0},
+	{2, 0, 0, 0, 0, 29, 0, -1, 0},
+	{1, 0, 2, -2, 2, 29, 0, -12, 0},
+	{0, 0, 2, 0, 0, 26, 0, -1, 0},
+	{0, 0, 2, -2, 0, -22, 0, 0, 0},
+	{-1, 0, 2, 0, 1, 21, 0, -10, 0},
+	{0, 2, 0, 0, 0, 17, -0.1, 0, 0},
+	{0, 2, 2, -2, 2, -16, 0.1, 7, 0},
+	{-1, 0, 0, 2, 1, 16, 0, -8, 0},
+	{0, 1, 0, 0, 1, -15, 0, 9, 0},
+	{1, 0, 0, -2, 1, -13, 0, 7, 0},
+	{0, -1, 0, 0, 1, -12, 0, 6, 0},
+	{2, 0, -2, 0, 0, 11, 0, 0, 0},
+	{-1, 0, 2, 2, 1, -10, 0, 5, 0},
+	{1, 0, 2, 2, 2, -8, 0, 3, 0},
+	{0, -1, 2, 0, 2, -7, 0, 3, 0},
+	{0, 0, 2, 2, 1, -7, 0, 3, 0},
+	{1, 1, 0, -2, 0, -7, 0, 0, 0},
+	{0, 1, 2, 0, 2, 7, 0, -3, 0},
+	{-2, 0, 0, 2, 1, -6, 0, 3, 0},
+	{0, 0, 0, 2, 1, -6, 0, 3, 0},
+	{2, 0, 2, -2, 2, 6, 0, -3, 0},
+	{1, 0, 0, 2, 0, 6, 0, 0, 0},
+	{1, 0, 2, -2, 1, 6, 0, -3, 0},
+	{0, 0, 0, -2, 1, -5, 0, 3, 0},
+	{0, -1, 2, -2, 1, -5, 0, 3, 0},
+	{2, 0, 2, 0, 1, -5, 0, 3, 0},
+	{1, -1, 0, 0, 0, 5, 0, 0, 0},
+}
+
+// nutation IAU-80 nutation in longitude and obliquity in radians
+func nutation(ttt float64) (float64, float64) {
+	// fundamental arguments of the Moon and the Sun in arcseconds
+	l := ((0.064*ttt+31.310)*ttt+1717915922.6330)*ttt + 485866.733
+	lp := ((-0.012*ttt-0.577)*ttt+129596581.2240)*ttt + 1287099.804
+	f := ((0.011*ttt-13.257)*ttt+1739527263.1370)*ttt + 335778.877
+	d := ((0.019*ttt-6.891)*ttt+1602961601.3280)*ttt + 1072261.307
+	om := ((0.008*ttt+7.455)*ttt-6962890.5390)*ttt + 450160.280
+	l, lp = math.Mod(l, 1296000)*arcsecToRad, math.Mod(lp, 1296000)*arcsecToRad
+	f, d, om = math.Mod(f, 1296000)*arcsecToRad, math.Mod(d, 1296000)*arcsecToRad, math.Mod(om, 1296000)*arcsecToRad
+
+	var dpsi, deps float64
+	for _, term := range nutationSeries {
+		argument := term.l*l + term.lp*lp + term.f*f + term.d*d + term.om*om
+		dpsi += (term.psi + term.psiT*ttt) * math.Sin(argument)
+		deps += (term.eps + term.epsT*ttt) * math.Cos(argument)
+	}
+	return dpsi * 1e-4 * arcsecToRad, deps * 1e-4 * arcsecToRad
+}
+
+// temeToJ2000 rotation from TEME to the J2000 frame: equation of the equinoxes to the true of date frame,
+// then nutation and precession
+func temeToJ2000(t time.Time) matrix {
+	ttt := julianCenturiesTT(t)
+	dpsi, deps := nutation(ttt)
+	eps := meanObliquity(ttt)
+	equinoxes := dpsi * math.Cos(eps)
+
+	nut := rot1(-eps).times(rot3(dpsi)).times(rot1(eps + deps))
+	return precession(ttt).transpose().times(nut).times(rot3(-equinoxes))
+}
+
+// frameBias rotation from the J2000 frame to the GCRF, first order in the IERS 2003 bias angles
+var frameBias = func() matrix {
+	da0, xi0, eta0 := -14.6e-3*arcsecToRad, -16.6170e-3*arcsecToRad, -6.8192e-3*arcsecToRad
+	return matrix{{1, da0, -xi0}, {-da0, 1, -eta0}, {xi0, eta0, 1}}.transpose()
+}()
+
+// TEMEToJ2000 rotates a position in km and velocity in km/s from TEME to the J2000 frame
+func TEMEToJ2000(position, velocity [3]float64, t time.Time) ([3]float64, [3]float64) {
+	m := temeToJ2000(t)
+	return m.apply(position), m.apply(velocity)
+}
+
+// TEMEToGCRF rotates a position in km and velocity in km/s from TEME to the GCRF
+func TEMEToGCRF(position, velocity [3]float64, t time.Time) ([3]float64, [3]float64) {
+	m := frameBias.times(temeToJ2000(t))
+	return m.apply(position), m.apply(velocity)
+}
+
+// TEMEToITRF rotates a position in km and velocity in km/s from TEME to the ITRF, with the Earth rotation
+// at the UT1 time and the polar motion of the EOPs
+func TEMEToITRF(position, velocity [3]float64, t time.Time, eop EOP) ([3]float64, [3]float64) {
+	gmst := GMST(t.Add(time.Duration(eop.DUT1 * float64(time.Second))))
+	rotation := rot3(gmst)
+	omega := EarthRotationRate * (1 - eop.LOD/86400)
+
+	r := rotation.apply(position)
+	v := rotation.apply(velocity)
+	v[0] += omega * r[1]
+	v[1] -= omega * r[0]
+
+	if eop.XP == 0 && eop.YP == 0 {
+		return r, v
+	}
+	polarMotion := rot1(eop.YP * arcsecToRad).times(rot2(eop.XP * arcsecToRad)).transpose()
+	return polarMotion.apply(r), polarMotion.apply(v)
+}
+
+// ValidateFrame checks that the frame is one of the output frames
+func ValidateFrame(frame string) error {
+	switch frame {
+	case FrameTEME, FrameJ2000, FrameGCRF, FrameITRF, FrameECEF:
+		return nil
+	default:
+		return fmt.Errorf("invalid frame %v, expected teme, j2000, gcrf, itrf or ecef", frame)
+	}
+}
+
+// FromTEME converts a TEME position in km and velocity in km/s to the given frame, the EOPs being only used for the ITRF
+func FromTEME(frame string, position, velocity [3]float64, t time.Time, eop EOP) ([3]float64, [3]float64, error) {
+	switch frame {
+	case FrameTEME:
+		return position, velocity, nil
+	case FrameJ2000:
+		r, v := TEMEToJ2000(position, velocity, t)
+		return r, v, nil
+	case FrameGCRF:
+		r, v := TEMEToGCRF(position, velocity, t)
+		return r, v, nil
+	case FrameITRF, FrameECEF:
+		r, v := TEMEToITRF(position, velocity, t, eop)
+		return r, v, nil
+	default:
+		return position, velocity, ValidateFrame(frame)
+	}
+}
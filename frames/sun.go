@@ -0,0 +1,37 @@
+package frames
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// AstronomicalUnit astronomical unit in km
+	AstronomicalUnit = 149597870.7
+	// SunRadius mean radius of the Sun in km
+	SunRadius = 696000.0
+)
+
+// SunPosition position of the Sun in km in the mean equator of date frame, used as TEME.
+// Low precision model of the Astronomical Almanac, accurate to 0.01 degree from 1950 to 2050.
+func SunPosition(t time.Time) [3]float64 {
+	tut1 := (JulianDate(t) - 2451545.0) / 36525.0
+
+	meanLongitude := 280.460 + 36000.771*tut1
+	meanAnomaly := (357.5291092 + 35999.05034*tut1) * math.Pi / 180
+	longitude := (meanLongitude + 1.914666471*math.Sin(meanAnomaly) + 0.019994643*math.Sin(2*meanAnomaly)) * math.Pi / 180
+	distance := (1.000140612 - 0.016708617*math.Cos(meanAnomaly) - 0.000139589*math.Cos(2*meanAnomaly)) * AstronomicalUnit
+	obliquity := (23.439291 - 0.0130042*tut1) * math.Pi / 180
+
+	return [3]float64{
+		distance * math.Cos(longitude),
+		distance * math.Cos(obliquity) * math.Sin(longitude),
+		distance * math.Sin(obliquity) * math.Sin(longitude),
+	}
+}
+
+// SunElevation elevation of the Sun in degrees seen by an observer, with the Earth orientation parameters at that time
+func SunElevation(observer Geodetic, t time.Time, eop EOP) float64 {
+	r, _ := TEMEToITRF(SunPosition(t), [3]float64{}, t, eop)
+	return NewLookAngles(observer, r, [3]float64{}).Elevation
+}
@@ -0,0 +1,41 @@
+package frames
+
+import "math"
+
+// LookAngles position of a satellite seen from an observer, azimuth from the north clockwise and elevation in degrees,
+// range in km and range rate in km/s, positive when the satellite moves away
+type LookAngles struct {
+	Azimuth   float64 `json:"azimuth"`
+	Elevation float64 `json:"elevation"`
+	Range     float64 `json:"range"`
+	RangeRate float64 `json:"range_rate"`
+}
+
+// NewLookAngles computes the look angles of a satellite from its Earth fixed position in km and velocity in km/s
+func NewLookAngles(observer Geodetic, position, velocity [3]float64) LookAngles {
+	o := GeodeticToECEF(observer)
+	d := [3]float64{position[0] - o[0], position[1] - o[1], position[2] - o[2]}
+
+	lat := observer.Latitude * math.Pi / 180
+	lon := observer.Longitude * math.Pi / 180
+	sinLat, cosLat := math.Sin(lat), math.Cos(lat)
+	sinLon, cosLon := math.Sin(lon), math.Cos(lon)
+
+	// local east, north, up frame of the observer
+	east := -sinLon*d[0] + cosLon*d[1]
+	north := -sinLat*cosLon*d[0] - sinLat*sinLon*d[1] + cosLat*d[2]
+	up := cosLat*cosLon*d[0] + cosLat*sinLon*d[1] + sinLat*d[2]
+
+	rng := math.Sqrt(d[0]*d[0] + d[1]*d[1] + d[2]*d[2])
+	azimuth := math.Atan2(east, north) * 180 / math.Pi
+	if azimuth < 0 {
+		azimuth += 360
+	}
+
+	return LookAngles{
+		Azimuth:   azimuth,
+		Elevation: math.Asin(up/rng) * 180 / math.Pi,
+		Range:     rng,
+		RangeRate: (d[0]*velocity[0] + d[1]*velocity[1] + d[2]*velocity[2]) / rng,
+	}
+}
@@ -0,0 +1,252 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+// Shadow of the Earth on a satellite
+type Shadow string
+
+const (
+	ShadowNone     Shadow = "sunlit"
+	ShadowPenumbra Shadow = "penumbra"
+	ShadowUmbra    Shadow = "umbra"
+)
+
+const (
+	// MaxEventSamples maximum number of propagated samples of an eclipse or pass search
+	MaxEventSamples = 100000
+	// eventPrecision precision of the bisection of the eclipse and pass times
+	eventPrecision = 100 * time.Millisecond
+)
+
+// Illumination illumination of a satellite by the Sun at a given time.
+// SunlitFraction is the visible fraction of the solar disc, 0 in the umbra and 1 in full sunlight.
+type Illumination struct {
+	SatelliteName  string    `json:"satellite_name"`
+	NORADID        int       `json:"norad_id"`
+	Time           time.Time `json:"time"`
+	Shadow         Shadow    `json:"shadow"`
+	SunlitFraction float64   `json:"sunlit_fraction"`
+}
+
+func (i Illumination) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// Eclipse passage of a satellite in the shadow of the Earth, from the penumbra entry to the penumbra exit.
+// Umbra times are absent when the satellite only goes through the penumbra. The times are truncated to the search window.
+type Eclipse struct {
+	Entry           time.Time  `json:"entry"`
+	UmbraEntry      *time.Time `json:"umbra_entry,omitempty"`
+	UmbraExit       *time.Time `json:"umbra_exit,omitempty"`
+	Exit            time.Time  `json:"exit"`
+	DurationSeconds float64    `json:"duration_seconds"`
+}
+
+// Eclipses eclipses of a satellite over a time window
+type Eclipses struct {
+	SatelliteName string    `json:"satellite_name"`
+	NORADID       int       `json:"norad_id"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	Eclipses      []Eclipse `json:"eclipses"`
+}
+
+func (e Eclipses) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// EarthShadow computes the shadow of the Earth on a satellite with a conical model, from the positions in km of the satellite
+// and of the Sun relative to the center of the Earth. It returns the visible fraction of the solar disc.
+func EarthShadow(position, sun [3]float64) (Shadow, float64) {
+	toSun := [3]float64{sun[0] - position[0], sun[1] - position[1], sun[2] - position[2]}
+	distance := norm(position)
+	sunDistance := norm(toSun)
+
+	// apparent radii of the Sun and the Earth, and apparent separation of their centers, seen from the satellite
+	a := math.Asin(math.Min(1, frames.SunRadius/sunDistance))
+	b := math.Asin(math.Min(1, frames.EarthRadius/distance))
+	c := math.Acos(math.Max(-1, math.Min(1, -(position[0]*toSun[0]+position[1]*toSun[1]+position[2]*toSun[2])/(distance*sunDistance))))
+
+	switch {
+	case c >= a+b:
+		return ShadowNone, 1
+	case c <= b-a:
+		return ShadowUmbra, 0
+	case c <= a-b:
+		// annular eclipse, only possible far from the Earth
+		return ShadowPenumbra, 1 - b*b/(a*a)
+	}
+
+	x := (c*c + a*a - b*b) / (2 * c)
+	y := math.Sqrt(math.Max(0, a*a-x*x))
+	overlap := a*a*math.Acos(math.Max(-1, math.Min(1, x/a))) + b*b*math.Acos(math.Max(-1, math.Min(1, (c-x)/b))) - c*y
+	return ShadowPenumbra, 1 - overlap/(math.Pi*a*a)
+}
+
+// SatelliteShadow shadow of the Earth on the satellite at the given time
+func SatelliteShadow(p *sgp4.Propagator, t time.Time) (Shadow, float64, error) {
+	state, err := p.Propagate(t)
+	if err != nil {
+		return "", 0, err
+	}
+	shadow, fraction := EarthShadow(state.Position, frames.SunPosition(t))
+	return shadow, fraction, nil
+}
+
+// NewIllumination computes the illumination of the satellite at the given time
+func NewIllumination(p *sgp4.Propagator, t time.Time) (Illumination, error) {
+	shadow, fraction, err := SatelliteShadow(p, t)
+	if err != nil {
+		return Illumination{}, err
+	}
+	return Illumination{
+		SatelliteName:  p.Satellite.SatelliteName,
+		NORADID:        p.Satellite.NORADID,
+		Time:           t,
+		Shadow:         shadow,
+		SunlitFraction: fraction,
+	}, nil
+}
+
+// NewEclipses searches the eclipses of the satellite from start to end. The shadow is sampled with the given step,
+// and the entry and exit times are refined by bisection: eclipses shorter than the step may be missed.
+func NewEclipses(p *sgp4.Propagator, start, end time.Time, step time.Duration) (Eclipses, error) {
+	if err := validateSearch(start, end, step); err != nil {
+		return Eclipses{}, err
+	}
+
+	var propagationErr error
+	shadowAt := func(t time.Time) Shadow {
+		shadow, _, err := SatelliteShadow(p, t)
+		if err != nil && propagationErr == nil {
+			propagationErr = err
+		}
+		return shadow
+	}
+	inShadow := func(t time.Time) bool { return shadowAt(t) != ShadowNone }
+	inUmbra := func(t time.Time) bool { return shadowAt(t) == ShadowUmbra }
+
+	eclipses := Eclipses{
+		SatelliteName: p.Satellite.SatelliteName,
+		NORADID:       p.Satellite.NORADID,
+		Start:         start,
+		End:           end,
+		Eclipses:      []Eclipse{},
+	}
+
+	var current *Eclipse
+	previousTime, previous := start, shadowAt(start)
+	if previous != ShadowNone {
+		current = &Eclipse{Entry: start}
+		if previous == ShadowUmbra {
+			umbraEntry := start
+			current.UmbraEntry = &umbraEntry
+		}
+	}
+
+	forEachSample(start, end, step, func(t time.Time) bool {
+		if t.Equal(start) {
+			return true
+		}
+		shadow := shadowAt(t)
+		if propagationErr != nil {
+			return false
+		}
+
+		wasShadow, isShadow := previous != ShadowNone, shadow != ShadowNone
+		wasUmbra, isUmbra := previous == ShadowUmbra, shadow == ShadowUmbra
+
+		// entries are refined before the exits, the satellite going through the whole shadow within a step
+		if !wasShadow && isShadow {
+			current = &Eclipse{Entry: bisect(previousTime, t, inShadow)}
+		}
+		if !wasUmbra && isUmbra {
+			umbraEntry := bisect(previousTime, t, inUmbra)
+			current.UmbraEntry = &umbraEntry
+		}
+		if wasUmbra && !isUmbra {
+			umbraExit := bisect(previousTime, t, inUmbra)
+			current.UmbraExit = &umbraExit
+		}
+		if wasShadow && !isShadow {
+			current.Exit = bisect(previousTime, t, inShadow)
+			eclipses.Eclipses = append(eclipses.Eclipses, current.close())
+			current = nil
+		}
+
+		previousTime, previous = t, shadow
+		return true
+	})
+	if propagationErr != nil {
+		return Eclipses{}, propagationErr
+	}
+
+	if current != nil {
+		current.Exit = end
+		if current.UmbraEntry != nil && current.UmbraExit == nil {
+			umbraExit := end
+			current.UmbraExit = &umbraExit
+		}
+		eclipses.Eclipses = append(eclipses.Eclipses, current.close())
+	}
+
+	return eclipses, nil
+}
+
+// close sets the duration of the eclipse
+func (e *Eclipse) close() Eclipse {
+	e.DurationSeconds = e.Exit.Sub(e.Entry).Seconds()
+	return *e
+}
+
+// validateSearch checks the window and the step of an eclipse or pass search
+func validateSearch(start, end time.Time, step time.Duration) error {
+	if step <= 0 {
+		return fmt.Errorf("step must be positive")
+	}
+	if !end.After(start) {
+		return fmt.Errorf("end must be after start")
+	}
+	if count := end.Sub(start) / step; count >= MaxEventSamples {
+		return fmt.Errorf("too many samples (%d), the maximum is %d", count+1, MaxEventSamples)
+	}
+	return nil
+}
+
+// forEachSample calls fn from start to end with the given step, the end being always included, until fn returns false
+func forEachSample(start, end time.Time, step time.Duration, fn func(t time.Time) bool) {
+	for t := start; ; t = t.Add(step) {
+		if t.After(end) {
+			t = end
+		}
+		if !fn(t) || t.Equal(end) {
+			return
+		}
+	}
+}
+
+// bisect finds the time where the condition changes between t1 and t2, the condition having different values at both ends
+func bisect(t1, t2 time.Time, condition func(t time.Time) bool) time.Time {
+	initial := condition(t1)
+	for t2.Sub(t1) > eventPrecision {
+		middle := t1.Add(t2.Sub(t1) / 2)
+		if condition(middle) == initial {
+			t1 = middle
+		} else {
+			t2 = middle
+		}
+	}
+	return t1.Add(t2.Sub(t1) / 2).Round(eventPrecision)
+}
+
+func norm(v [3]float64) float64 {
+	return math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+}
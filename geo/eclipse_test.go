@@ -0,0 +1,187 @@
+package geo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+// issPropagator propagator of a low inclined orbit going through the shadow of the Earth at each revolution
+func issPropagator(t *testing.T) *sgp4.Propagator {
+	t.Helper()
+	sat, err := data.GenerateTLE(data.KeplerianElements{
+		SatelliteName: "ISS-LIKE",
+		NORADID:       99903,
+		Epoch:         time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC),
+		ApogeeKm:      420,
+		PerigeeKm:     415,
+		Inclination:   51.6,
+		RAAN:          0,
+	})
+	if err != nil {
+		t.Fatalf("GenerateTLE() error = %v", err)
+	}
+	p, err := sgp4.New(sat)
+	if err != nil {
+		t.Fatalf("sgp4.New() error = %v", err)
+	}
+	return p
+}
+
+func TestEarthShadow(t *testing.T) {
+	sun := [3]float64{frames.AstronomicalUnit, 0, 0}
+
+	tests := []struct {
+		name         string
+		position     [3]float64
+		want         Shadow
+		wantFraction float64
+	}{
+		{"facing the Sun", [3]float64{7000, 0, 0}, ShadowNone, 1},
+		{"above the terminator", [3]float64{0, 7000, 0}, ShadowNone, 1},
+		{"behind the Earth", [3]float64{-7000, 0, 0}, ShadowUmbra, 0},
+		{"at the shadow edge", [3]float64{-7000, frames.EarthRadius, 0}, ShadowPenumbra, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fraction := EarthShadow(tt.position, sun)
+			if got != tt.want || fraction < tt.wantFraction-0.2 || fraction > tt.wantFraction+0.2 {
+				t.Errorf("EarthShadow() = %v, %v, want %v, %v", got, fraction, tt.want, tt.wantFraction)
+			}
+		})
+	}
+}
+
+func TestNewEclipses(t *testing.T) {
+	p := issPropagator(t)
+
+	tests := []struct {
+		name         string
+		start        time.Time
+		duration     time.Duration
+		step         time.Duration
+		wantEclipses int
+		wantErr      bool
+	}{
+		{"one day", p.Epoch, 24 * time.Hour, time.Minute, 16, false},
+		{"coarse step", p.Epoch, 24 * time.Hour, 10 * time.Minute, 16, false},
+		{"invalid step", p.Epoch, time.Hour, 0, 0, true},
+		{"invalid window", p.Epoch, -time.Hour, time.Minute, 0, true},
+		{"too many samples", p.Epoch, 365 * 24 * time.Hour, time.Minute, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewEclipses(p, tt.start, tt.start.Add(tt.duration), tt.step)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewEclipses() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got.Eclipses) < tt.wantEclipses-1 || len(got.Eclipses) > tt.wantEclipses {
+				t.Fatalf("expected %d eclipses, got %d", tt.wantEclipses, len(got.Eclipses))
+			}
+
+			for _, eclipse := range got.Eclipses {
+				if eclipse.UmbraEntry == nil || eclipse.UmbraExit == nil {
+					t.Fatalf("expected an umbra for eclipse %+v", eclipse)
+				}
+				// eclipses truncated by the window
+				if eclipse.Entry.Equal(got.Start) || eclipse.Exit.Equal(got.End) {
+					continue
+				}
+				if eclipse.DurationSeconds < 20*60 || eclipse.DurationSeconds > 40*60 {
+					t.Fatalf("invalid eclipse duration %+v", eclipse)
+				}
+				checks := []struct {
+					time time.Time
+					want Shadow
+				}{
+					{eclipse.Entry.Add(-time.Second), ShadowNone},
+					{eclipse.Entry.Add(time.Second), ShadowPenumbra},
+					{eclipse.UmbraEntry.Add(time.Second), ShadowUmbra},
+					{eclipse.UmbraExit.Add(time.Second), ShadowPenumbra},
+					{eclipse.Exit.Add(time.Second), ShadowNone},
+				}
+				for _, check := range checks {
+					if shadow, _, _ := SatelliteShadow(p, check.time); shadow != check.want {
+						t.Fatalf("shadow at %v = %v, want %v for eclipse %+v", check.time, shadow, check.want, eclipse)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestNewPasses(t *testing.T) {
+	p := issPropagator(t)
+	at := p.Epoch.Add(6 * time.Hour)
+	point, err := SubSatellitePoint(p, at)
+	if err != nil {
+		t.Fatalf("SubSatellitePoint() error = %v", err)
+	}
+	observer := frames.Geodetic{Latitude: point.Latitude, Longitude: point.Longitude}
+	illuminated := true
+	if shadow, _, _ := SatelliteShadow(p, at); shadow == ShadowUmbra {
+		illuminated = false
+	}
+
+	tests := []struct {
+		name        string
+		options     PassOptions
+		start       time.Time
+		end         time.Time
+		wantPasses  int
+		wantVisible bool
+		wantErr     bool
+	}{
+		{"overhead pass", PassOptions{MinElevation: 10, SunElevation: 90, Step: time.Minute}, at.Add(-20 * time.Minute), at.Add(20 * time.Minute), 1, illuminated, false},
+		{"daylight", PassOptions{MinElevation: 10, SunElevation: -90, Step: time.Minute}, at.Add(-20 * time.Minute), at.Add(20 * time.Minute), 1, false, false},
+		{"truncated pass", PassOptions{SunElevation: -90, Step: 30 * time.Second}, at, at.Add(20 * time.Minute), 1, false, false},
+		{"invalid elevation", PassOptions{MinElevation: 90, Step: time.Minute}, at, at.Add(time.Hour), 0, false, true},
+		{"invalid step", PassOptions{Step: 0}, at, at.Add(time.Hour), 0, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewPasses(context.Background(), p, observer, tt.start, tt.end, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewPasses() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got.Passes) != tt.wantPasses {
+				t.Fatalf("expected %d passes, got %+v", tt.wantPasses, got.Passes)
+			}
+
+			pass := got.Passes[0]
+			if pass.Rise.Before(tt.start) || pass.Set.After(tt.end) || pass.Culmination.Before(pass.Rise) || pass.Culmination.After(pass.Set) {
+				t.Errorf("invalid pass times %+v", pass)
+			}
+			if tt.start.Before(at) && (pass.MaxElevation < 89 || pass.Culmination.Sub(at) > 5*time.Second || at.Sub(pass.Culmination) > 5*time.Second) {
+				t.Errorf("expected a culmination at the zenith at %v, got %+v", at, pass)
+			}
+			if pass.Visible != tt.wantVisible {
+				t.Errorf("pass visible = %v, want %v", pass.Visible, tt.wantVisible)
+			}
+		})
+	}
+}
+
+func TestNewPasses_canceled(t *testing.T) {
+	p := issPropagator(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	options := PassOptions{MinElevation: 10, SunElevation: DefaultSunElevation, Step: time.Minute}
+	if _, err := NewPasses(ctx, p, frames.Geodetic{Latitude: 45}, p.Epoch, p.Epoch.Add(24*time.Hour), options); err != context.Canceled {
+		t.Errorf("NewPasses() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,311 @@
+package geo
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Funkit/tle-provider/frames"
+)
+
+// Export formats
+const (
+	FormatGeoJSON = "geojson"
+	FormatKML     = "kml"
+	FormatCZML    = "czml"
+)
+
+// Media types of the export formats
+const (
+	KMLContentType  = "application/vnd.google-earth.kml+xml"
+	CZMLContentType = "application/json"
+)
+
+// Trajectory sub-satellite points of a satellite, a single point for a current position
+type Trajectory struct {
+	SatelliteName string       `json:"satellite_name"`
+	NORADID       int          `json:"norad_id"`
+	Points        []TrackPoint `json:"points"`
+}
+
+// ContentType media type of the export format
+func ContentType(format string) (string, error) {
+	switch format {
+	case FormatGeoJSON:
+		return GeoJSONContentType, nil
+	case FormatKML:
+		return KMLContentType, nil
+	case FormatCZML:
+		return CZMLContentType, nil
+	default:
+		return "", fmt.Errorf("invalid format %v, expected geojson, kml or czml", format)
+	}
+}
+
+// Export writes the trajectories in the given format. Positions are exported as points, trajectories as lines.
+func Export(w io.Writer, format string, trajectories []Trajectory) error {
+	switch format {
+	case FormatGeoJSON:
+		return json.NewEncoder(w).Encode(TrajectoriesGeoJSON(trajectories))
+	case FormatKML:
+		return WriteKML(w, trajectories)
+	case FormatCZML:
+		return json.NewEncoder(w).Encode(TrajectoriesCZML(trajectories))
+	default:
+		return fmt.Errorf("invalid format %v, expected geojson, kml or czml", format)
+	}
+}
+
+// TrajectoriesGeoJSON one Point feature per position, and one MultiLineString feature split at the antimeridian per trajectory.
+// The third coordinate is the altitude in meters.
+func TrajectoriesGeoJSON(trajectories []Trajectory) FeatureCollection {
+	features := make([]Feature, 0, len(trajectories))
+	for _, trajectory := range trajectories {
+		properties := map[string]interface{}{
+			"satellite_name": trajectory.SatelliteName,
+			"norad_id":       trajectory.NORADID,
+		}
+
+		if len(trajectory.Points) == 1 {
+			point := trajectory.Points[0]
+			properties["time"] = point.Time.UTC().Format(time.RFC3339)
+			features = append(features, Feature{
+				Type:       "Feature",
+				Geometry:   Geometry{Type: "Point", Coordinates: coordinates(point)},
+				Properties: properties,
+			})
+			continue
+		}
+
+		segments := splitAntimeridian(trajectory.Points)
+		lines := make([][][3]float64, 0, len(segments))
+		times := make([][]string, 0, len(segments))
+		for _, segment := range segments {
+			line := make([][3]float64, 0, len(segment))
+			lineTimes := make([]string, 0, len(segment))
+			for _, point := range segment {
+				line = append(line, coordinates(point))
+				lineTimes = append(lineTimes, point.Time.UTC().Format(time.RFC3339))
+			}
+			lines = append(lines, line)
+			times = append(times, lineTimes)
+		}
+		properties["start"] = trajectory.Points[0].Time.UTC().Format(time.RFC3339)
+		properties["end"] = trajectory.Points[len(trajectory.Points)-1].Time.UTC().Format(time.RFC3339)
+		properties["times"] = times
+
+		features = append(features, Feature{
+			Type:       "Feature",
+			Geometry:   Geometry{Type: "MultiLineString", Coordinates: lines},
+			Properties: properties,
+		})
+	}
+
+	return NewFeatureCollection(features...)
+}
+
+// coordinates GeoJSON position of the point, altitude in meters
+func coordinates(point TrackPoint) [3]float64 {
+	return [3]float64{point.Longitude, point.Latitude, point.Altitude * 1000}
+}
+
+type kmlDocument struct {
+	XMLName  xml.Name `xml:"kml"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Document struct {
+		Name       string         `xml:"name"`
+		Placemarks []kmlPlacemark `xml:"Placemark"`
+	} `xml:"Document"`
+}
+
+type kmlPlacemark struct {
+	Name          string            `xml:"name"`
+	Description   string            `xml:"description"`
+	TimeStamp     *kmlTimeStamp     `xml:"TimeStamp,omitempty"`
+	TimeSpan      *kmlTimeSpan      `xml:"TimeSpan,omitempty"`
+	Point         *kmlPoint         `xml:"Point,omitempty"`
+	MultiGeometry *kmlMultiGeometry `xml:"MultiGeometry,omitempty"`
+}
+
+type kmlTimeStamp struct {
+	When string `xml:"when"`
+}
+
+type kmlTimeSpan struct {
+	Begin string `xml:"begin"`
+	End   string `xml:"end"`
+}
+
+type kmlPoint struct {
+	AltitudeMode string `xml:"altitudeMode"`
+	Coordinates  string `xml:"coordinates"`
+}
+
+type kmlLineString struct {
+	AltitudeMode string `xml:"altitudeMode"`
+	Coordinates  string `xml:"coordinates"`
+}
+
+type kmlMultiGeometry struct {
+	LineStrings []kmlLineString `xml:"LineString"`
+}
+
+// WriteKML writes the trajectories as a KML document, with absolute altitudes
+func WriteKML(w io.Writer, trajectories []Trajectory) error {
+	var doc kmlDocument
+	doc.XMLNS = "http://www.opengis.net/kml/2.2"
+	doc.Document.Name = "tle-provider"
+
+	for _, trajectory := range trajectories {
+		placemark := kmlPlacemark{
+			Name:        trajectory.SatelliteName,
+			Description: fmt.Sprintf("NORAD ID %d", trajectory.NORADID),
+		}
+
+		first, last := trajectory.Points[0], trajectory.Points[len(trajectory.Points)-1]
+		if len(trajectory.Points) == 1 {
+			placemark.TimeStamp = &kmlTimeStamp{When: first.Time.UTC().Format(time.RFC3339)}
+			placemark.Point = &kmlPoint{AltitudeMode: "absolute", Coordinates: kmlCoordinates(first)}
+		} else {
+			placemark.TimeSpan = &kmlTimeSpan{Begin: first.Time.UTC().Format(time.RFC3339), End: last.Time.UTC().Format(time.RFC3339)}
+			placemark.MultiGeometry = &kmlMultiGeometry{}
+			for _, segment := range splitAntimeridian(trajectory.Points) {
+				coords := make([]string, 0, len(segment))
+				for _, point := range segment {
+					coords = append(coords, kmlCoordinates(point))
+				}
+				placemark.MultiGeometry.LineStrings = append(placemark.MultiGeometry.LineStrings,
+					kmlLineString{AltitudeMode: "absolute", Coordinates: strings.Join(coords, " ")})
+			}
+		}
+
+		doc.Document.Placemarks = append(doc.Document.Placemarks, placemark)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// kmlCoordinates KML coordinates of the point, altitude in meters
+func kmlCoordinates(point TrackPoint) string {
+	return strconv.FormatFloat(point.Longitude, 'f', 6, 64) + "," +
+		strconv.FormatFloat(point.Latitude, 'f', 6, 64) + "," +
+		strconv.FormatFloat(point.Altitude*1000, 'f', 1, 64)
+}
+
+// CZMLPacket CZML packet, only the properties used by the export are defined
+type CZMLPacket struct {
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name,omitempty"`
+	Version      string                 `json:"version,omitempty"`
+	Clock        *CZMLClock             `json:"clock,omitempty"`
+	Availability string                 `json:"availability,omitempty"`
+	Description  string                 `json:"description,omitempty"`
+	Label        map[string]interface{} `json:"label,omitempty"`
+	Point        map[string]interface{} `json:"point,omitempty"`
+	Path         map[string]interface{} `json:"path,omitempty"`
+	Position     *CZMLPosition          `json:"position,omitempty"`
+}
+
+// CZMLClock clock of the CZML document
+type CZMLClock struct {
+	Interval    string  `json:"interval"`
+	CurrentTime string  `json:"currentTime"`
+	Multiplier  float64 `json:"multiplier"`
+	Range       string  `json:"range"`
+	Step        string  `json:"step"`
+}
+
+// CZMLPosition position in the Earth fixed frame, in meters. Sampled positions are given as [seconds from epoch, x, y, z] values.
+type CZMLPosition struct {
+	Epoch                  string    `json:"epoch,omitempty"`
+	ReferenceFrame         string    `json:"referenceFrame"`
+	InterpolationAlgorithm string    `json:"interpolationAlgorithm,omitempty"`
+	InterpolationDegree    int       `json:"interpolationDegree,omitempty"`
+	Cartesian              []float64 `json:"cartesian"`
+}
+
+// TrajectoriesCZML CZML document with one packet per satellite, after the document packet
+func TrajectoriesCZML(trajectories []Trajectory) []CZMLPacket {
+	document := CZMLPacket{ID: "document", Name: "tle-provider", Version: "1.0"}
+
+	var start, end time.Time
+	for _, trajectory := range trajectories {
+		first, last := trajectory.Points[0].Time, trajectory.Points[len(trajectory.Points)-1].Time
+		if start.IsZero() || first.Before(start) {
+			start = first
+		}
+		if last.After(end) {
+			end = last
+		}
+	}
+	if !start.IsZero() {
+		document.Clock = &CZMLClock{
+			Interval:    czmlInterval(start, end),
+			CurrentTime: start.UTC().Format(time.RFC3339),
+			Multiplier:  60,
+			Range:       "LOOP_STOP",
+			Step:        "SYSTEM_CLOCK_MULTIPLIER",
+		}
+		if start.Equal(end) {
+			document.Clock.Multiplier = 1
+			document.Clock.Range = "UNBOUNDED"
+		}
+	}
+
+	packets := make([]CZMLPacket, 0, len(trajectories)+1)
+	packets = append(packets, document)
+	for _, trajectory := range trajectories {
+		packet := CZMLPacket{
+			ID:          "norad-" + strconv.Itoa(trajectory.NORADID),
+			Name:        trajectory.SatelliteName,
+			Description: fmt.Sprintf("NORAD ID %d", trajectory.NORADID),
+			Label: map[string]interface{}{
+				"text":             trajectory.SatelliteName,
+				"font":             "11pt sans-serif",
+				"pixelOffset":      map[string]interface{}{"cartesian2": []float64{12, 0}},
+				"horizontalOrigin": "LEFT",
+			},
+			Point: map[string]interface{}{"pixelSize": 5},
+		}
+
+		epoch := trajectory.Points[0].Time
+		if len(trajectory.Points) == 1 {
+			r := frames.GeodeticToECEF(trajectory.Points[0].Geodetic)
+			packet.Position = &CZMLPosition{ReferenceFrame: "FIXED", Cartesian: []float64{r[0] * 1000, r[1] * 1000, r[2] * 1000}}
+			packets = append(packets, packet)
+			continue
+		}
+
+		last := trajectory.Points[len(trajectory.Points)-1].Time
+		packet.Availability = czmlInterval(epoch, last)
+		packet.Path = map[string]interface{}{"width": 1, "leadTime": 0, "trailTime": last.Sub(epoch).Seconds()}
+		packet.Position = &CZMLPosition{
+			Epoch:                  epoch.UTC().Format(time.RFC3339),
+			ReferenceFrame:         "FIXED",
+			InterpolationAlgorithm: "LAGRANGE",
+			InterpolationDegree:    5,
+			Cartesian:              make([]float64, 0, 4*len(trajectory.Points)),
+		}
+		for _, point := range trajectory.Points {
+			r := frames.GeodeticToECEF(point.Geodetic)
+			packet.Position.Cartesian = append(packet.Position.Cartesian, point.Time.Sub(epoch).Seconds(), r[0]*1000, r[1]*1000, r[2]*1000)
+		}
+		packets = append(packets, packet)
+	}
+
+	return packets
+}
+
+// czmlInterval ISO 8601 interval between two times
+func czmlInterval(start, end time.Time) string {
+	return start.UTC().Format(time.RFC3339) + "/" + end.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,114 @@
+package geo
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExport(t *testing.T) {
+	p := testPropagator(t)
+	position, err := SubSatellitePoint(p, p.Epoch)
+	if err != nil {
+		t.Fatalf("SubSatellitePoint() error = %v", err)
+	}
+	points, err := Sample(p, p.Epoch, p.Epoch.Add(p.Period()), time.Minute)
+	if err != nil {
+		t.Fatalf("Sample() error = %v", err)
+	}
+
+	positions := []Trajectory{{SatelliteName: "ONEWEB-0012", NORADID: 44057, Points: []TrackPoint{position}}}
+	trajectories := []Trajectory{{SatelliteName: "ONEWEB-0012", NORADID: 44057, Points: points}}
+
+	tests := []struct {
+		name         string
+		format       string
+		trajectories []Trajectory
+		check        func(t *testing.T, body []byte)
+		wantErr      bool
+	}{
+		{
+			name:         "GeoJSON positions",
+			format:       FormatGeoJSON,
+			trajectories: positions,
+			check: func(t *testing.T, body []byte) {
+				var fc FeatureCollection
+				if err := json.Unmarshal(body, &fc); err != nil {
+					t.Fatalf("invalid GeoJSON: %v", err)
+				}
+				if len(fc.Features) != 1 || fc.Features[0].Geometry.Type != "Point" {
+					t.Errorf("expected one Point feature, got %s", body)
+				}
+			},
+		},
+		{
+			name:         "GeoJSON trajectories",
+			format:       FormatGeoJSON,
+			trajectories: trajectories,
+			check: func(t *testing.T, body []byte) {
+				var fc FeatureCollection
+				if err := json.Unmarshal(body, &fc); err != nil {
+					t.Fatalf("invalid GeoJSON: %v", err)
+				}
+				if len(fc.Features) != 1 || fc.Features[0].Geometry.Type != "MultiLineString" {
+					t.Errorf("expected one MultiLineString feature, got %s", body)
+				}
+			},
+		},
+		{
+			name:         "KML trajectories",
+			format:       FormatKML,
+			trajectories: append(positions, trajectories...),
+			check: func(t *testing.T, body []byte) {
+				var doc kmlDocument
+				if err := xml.Unmarshal(body, &doc); err != nil {
+					t.Fatalf("invalid KML: %v", err)
+				}
+				placemarks := doc.Document.Placemarks
+				if len(placemarks) != 2 || placemarks[0].Point == nil || placemarks[1].MultiGeometry == nil {
+					t.Fatalf("expected a point and a track, got %s", body)
+				}
+				coords := strings.Fields(placemarks[1].MultiGeometry.LineStrings[0].Coordinates)
+				if len(coords) == 0 || len(strings.Split(coords[0], ",")) != 3 {
+					t.Errorf("invalid coordinates %v", placemarks[1].MultiGeometry.LineStrings[0].Coordinates)
+				}
+			},
+		},
+		{
+			name:         "CZML trajectories",
+			format:       FormatCZML,
+			trajectories: trajectories,
+			check: func(t *testing.T, body []byte) {
+				var packets []CZMLPacket
+				if err := json.Unmarshal(body, &packets); err != nil {
+					t.Fatalf("invalid CZML: %v", err)
+				}
+				if len(packets) != 2 || packets[0].ID != "document" || packets[0].Clock == nil {
+					t.Fatalf("expected a document packet with a clock and a satellite packet, got %s", body)
+				}
+				position := packets[1].Position
+				if position == nil || len(position.Cartesian) != 4*len(points) || position.Cartesian[4*len(points)-4] != p.Period().Seconds() {
+					t.Errorf("invalid sampled position %+v", position)
+				}
+			},
+		},
+		{"invalid format", "shapefile", positions, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			err := Export(&body, tt.format, tt.trajectories)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Export() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			tt.check(t, body.Bytes())
+		})
+	}
+}
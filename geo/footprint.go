@@ -0,0 +1,173 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+const (
+	// meanEarthRadius radius in km of the sphere used for the footprint computation
+	meanEarthRadius = 6371.0
+	// footprintPoints number of points of the footprint boundary
+	footprintPoints = 72
+)
+
+// Footprint area of the Earth surface seeing the satellite above a minimum elevation, at a given time.
+// The boundary is a list of polygons of [longitude, latitude] points, split at the antimeridian.
+type Footprint struct {
+	SatelliteName     string          `json:"satellite_name"`
+	NORADID           int             `json:"norad_id"`
+	Time              time.Time       `json:"time"`
+	SubSatellitePoint frames.Geodetic `json:"sub_satellite_point"`
+	MinElevation      float64         `json:"min_elevation"`
+	CentralAngle      float64         `json:"earth_central_angle"`
+	RadiusKm          float64         `json:"radius_km"`
+	Polygons          [][][2]float64  `json:"polygons"`
+}
+
+func (f Footprint) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// NewFootprint computes the footprint of the satellite at the given time, for a minimum elevation in degrees.
+// The Earth is approximated by a sphere.
+func NewFootprint(p *sgp4.Propagator, t time.Time, minElevation float64) (Footprint, error) {
+	if minElevation < 0 || minElevation >= 90 {
+		return Footprint{}, fmt.Errorf("minimum elevation must be between 0 and 90 degrees")
+	}
+
+	point, err := SubSatellitePoint(p, t)
+	if err != nil {
+		return Footprint{}, err
+	}
+	if point.Altitude <= 0 {
+		return Footprint{}, fmt.Errorf("%v is below the surface at %v", p.Satellite.SatelliteName, t.UTC().Format(time.RFC3339))
+	}
+
+	elevation := minElevation * math.Pi / 180
+	centralAngle := math.Acos(meanEarthRadius/(meanEarthRadius+point.Altitude)*math.Cos(elevation)) - elevation
+
+	return Footprint{
+		SatelliteName:     p.Satellite.SatelliteName,
+		NORADID:           p.Satellite.NORADID,
+		Time:              t,
+		SubSatellitePoint: point.Geodetic,
+		MinElevation:      minElevation,
+		CentralAngle:      centralAngle * 180 / math.Pi,
+		RadiusKm:          centralAngle * meanEarthRadius,
+		Polygons:          footprintPolygons(point.Latitude, point.Longitude, centralAngle),
+	}, nil
+}
+
+// footprintPolygons builds the counterclockwise boundary of the spherical cap centered on the point, with a radius given by the central angle in radians
+func footprintPolygons(latitude, longitude, centralAngle float64) [][][2]float64 {
+	lat1 := latitude * math.Pi / 180
+	sinLat1, cosLat1 := math.Sin(lat1), math.Cos(lat1)
+	sinAngle, cosAngle := math.Sin(centralAngle), math.Cos(centralAngle)
+
+	// longitudes are kept continuous around the center, decreasing azimuths give a counterclockwise ring
+	ring := make([][2]float64, 0, footprintPoints+1)
+	for i := 0; i < footprintPoints; i++ {
+		azimuth := -2 * math.Pi * float64(i) / footprintPoints
+		lat2 := math.Asin(sinLat1*cosAngle + cosLat1*sinAngle*math.Cos(azimuth))
+		deltaLon := math.Atan2(math.Sin(azimuth)*sinAngle*cosLat1, cosAngle-sinLat1*math.Sin(lat2))
+		ring = append(ring, [2]float64{longitude + deltaLon*180/math.Pi, lat2 * 180 / math.Pi})
+	}
+
+	northPole := 90-latitude < centralAngle*180/math.Pi
+	southPole := 90+latitude < centralAngle*180/math.Pi
+	if northPole || southPole {
+		return [][][2]float64{polarRing(ring, northPole)}
+	}
+
+	ring = append(ring, ring[0])
+	minLon, maxLon := 180.0, -180.0
+	for _, point := range ring {
+		minLon = math.Min(minLon, point[0])
+		maxLon = math.Max(maxLon, point[0])
+	}
+	switch {
+	case maxLon > 180:
+		return [][][2]float64{clipLongitude(ring, 180, false, 0), clipLongitude(ring, 180, true, -360)}
+	case minLon < -180:
+		return [][][2]float64{clipLongitude(ring, -180, true, 0), clipLongitude(ring, -180, false, 360)}
+	}
+	return [][][2]float64{ring}
+}
+
+// polarRing builds the ring of a footprint containing a pole, following the boundary from one side of the antimeridian
+// to the other, then closing along the antimeridian through the pole
+func polarRing(ring [][2]float64, north bool) [][2]float64 {
+	boundary := make([][2]float64, 0, len(ring))
+	for _, point := range ring {
+		boundary = append(boundary, [2]float64{normalizeLongitude(point[0]), point[1]})
+	}
+	sort.Slice(boundary, func(i, j int) bool { return boundary[i][0] < boundary[j][0] })
+
+	first, last := boundary[0], boundary[len(boundary)-1]
+	edgeLat := last[1] + (180-last[0])/(first[0]+360-last[0])*(first[1]-last[1])
+
+	pole := 90.0
+	if !north {
+		pole = -90
+		for i, j := 0, len(boundary)-1; i < j; i, j = i+1, j-1 {
+			boundary[i], boundary[j] = boundary[j], boundary[i]
+		}
+	}
+
+	// the region is on the left of the boundary: eastwards around the north pole, westwards around the south pole
+	start := -180.0
+	if !north {
+		start = 180
+	}
+	result := make([][2]float64, 0, len(boundary)+5)
+	result = append(result, [2]float64{start, edgeLat})
+	result = append(result, boundary...)
+	result = append(result, [2]float64{-start, edgeLat}, [2]float64{-start, pole}, [2]float64{start, pole}, [2]float64{start, edgeLat})
+	return result
+}
+
+// clipLongitude keeps the part of the closed ring west (or east) of the given longitude, and shifts its longitudes
+func clipLongitude(ring [][2]float64, limit float64, east bool, shift float64) [][2]float64 {
+	inside := func(point [2]float64) bool {
+		if east {
+			return point[0] >= limit
+		}
+		return point[0] <= limit
+	}
+
+	var clipped [][2]float64
+	for i := 0; i < len(ring)-1; i++ {
+		current, next := ring[i], ring[i+1]
+		if inside(current) {
+			clipped = append(clipped, current)
+		}
+		if inside(current) != inside(next) {
+			ratio := (limit - current[0]) / (next[0] - current[0])
+			clipped = append(clipped, [2]float64{limit, current[1] + ratio*(next[1]-current[1])})
+		}
+	}
+	if len(clipped) > 0 {
+		clipped = append(clipped, clipped[0])
+	}
+
+	for i := range clipped {
+		clipped[i][0] += shift
+	}
+	return clipped
+}
+
+// normalizeLongitude brings a longitude in degrees to the [-180, 180] range
+func normalizeLongitude(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
@@ -0,0 +1,188 @@
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+var oneweb = data.Satellite{
+	SatelliteName: "ONEWEB-0012",
+	NORADID:       44057,
+	TLELine1:      "1 44057U 19010A   22206.81764082 -.00000043  00000+0 -14585-3 0  9993",
+	TLELine2:      "2 44057  87.9150 151.8950 0002369 106.7932 253.3459 13.16592117164401",
+}
+
+func testPropagator(t *testing.T) *sgp4.Propagator {
+	t.Helper()
+	p, err := sgp4.New(oneweb)
+	if err != nil {
+		t.Fatalf("sgp4.New() error = %v", err)
+	}
+	return p
+}
+
+func TestNewGroundTrack(t *testing.T) {
+	p := testPropagator(t)
+
+	tests := []struct {
+		name         string
+		start        time.Time
+		duration     time.Duration
+		step         time.Duration
+		wantSegments int
+		wantErr      bool
+	}{
+		{"one orbit", p.Epoch, p.Period(), time.Minute, 2, false},
+		{"one day", p.Epoch, 24 * time.Hour, 30 * time.Second, 14, false},
+		{"end not on a step", p.Epoch, 10*time.Minute + 30*time.Second, time.Minute, 1, false},
+		{"end before start", p.Epoch, -time.Hour, time.Minute, 0, true},
+		{"null step", p.Epoch, time.Hour, 0, 0, true},
+		{"too many points", p.Epoch, 24 * time.Hour, time.Second, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := tt.start.Add(tt.duration)
+			got, err := NewGroundTrack(p, tt.start, end, tt.step)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewGroundTrack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if math.Abs(float64(len(got.Segments)-tt.wantSegments)) > 1 {
+				t.Errorf("got %d segments, want about %d", len(got.Segments), tt.wantSegments)
+			}
+
+			first := got.Segments[0][0]
+			lastSegment := got.Segments[len(got.Segments)-1]
+			last := lastSegment[len(lastSegment)-1]
+			if !first.Time.Equal(tt.start) || !last.Time.Equal(end) {
+				t.Errorf("track from %v to %v, want %v to %v", first.Time, last.Time, tt.start, end)
+			}
+
+			for i, segment := range got.Segments {
+				for j := 1; j < len(segment); j++ {
+					if math.Abs(segment[j].Longitude-segment[j-1].Longitude) > 180 {
+						t.Fatalf("segment %d crosses the antimeridian at %v", i, segment[j].Time)
+					}
+					if segment[j].Time.Before(segment[j-1].Time) {
+						t.Fatalf("segment %d is not ordered at %v", i, segment[j].Time)
+					}
+				}
+				if i > 0 {
+					end, start := got.Segments[i-1][len(got.Segments[i-1])-1], segment[0]
+					if math.Abs(end.Longitude) != 180 || end.Longitude != -start.Longitude || end.Latitude != start.Latitude {
+						t.Errorf("segments %d and %d not joined on the antimeridian: %+v, %+v", i-1, i, end, start)
+					}
+				}
+			}
+
+			// ONEWEB satellites orbit at about 1200 km
+			for _, point := range got.Segments[0] {
+				if point.Altitude < 1150 || point.Altitude > 1250 || math.Abs(point.Latitude) > 90 {
+					t.Fatalf("invalid point %+v", point)
+				}
+			}
+		})
+	}
+}
+
+func TestFootprintPolygons(t *testing.T) {
+	// 20 degrees of central angle
+	centralAngle := 20 * math.Pi / 180
+
+	tests := []struct {
+		name         string
+		latitude     float64
+		longitude    float64
+		wantPolygons int
+		wantPole     float64
+	}{
+		{"equator", 0, 10, 1, 0},
+		{"east of the antimeridian", 30, -175, 2, 0},
+		{"west of the antimeridian", -30, 170, 2, 0},
+		{"north pole", 80, 45, 1, 90},
+		{"south pole", -85, -100, 1, -90},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := footprintPolygons(tt.latitude, tt.longitude, centralAngle)
+			if len(got) != tt.wantPolygons {
+				t.Fatalf("got %d polygons, want %d", len(got), tt.wantPolygons)
+			}
+
+			var area float64
+			for _, polygon := range got {
+				if polygon[0] != polygon[len(polygon)-1] {
+					t.Errorf("polygon not closed: %v", polygon)
+				}
+
+				var poleFound bool
+				for _, point := range polygon {
+					if point[0] < -180 || point[0] > 180 || point[1] < -90 || point[1] > 90 {
+						t.Fatalf("point %v out of range", point)
+					}
+					if tt.wantPole != 0 && point[1] == tt.wantPole {
+						poleFound = true
+					}
+				}
+				if tt.wantPole != 0 && !poleFound {
+					t.Errorf("polygon does not go through the pole")
+				}
+
+				// shoelace formula, positive for counterclockwise rings
+				for i := 0; i < len(polygon)-1; i++ {
+					area += polygon[i][0]*polygon[i+1][1] - polygon[i+1][0]*polygon[i][1]
+				}
+			}
+			if area <= 0 {
+				t.Errorf("rings are not counterclockwise")
+			}
+		})
+	}
+}
+
+func TestNewFootprint(t *testing.T) {
+	p := testPropagator(t)
+
+	tests := []struct {
+		name         string
+		minElevation float64
+		wantRadius   float64
+		wantErr      bool
+	}{
+		// the radius of the footprint decreases with the minimum elevation
+		{"horizon", 0, 3700, false},
+		{"10 degrees", 10, 2650, false},
+		{"invalid elevation", 90, 0, true},
+		{"negative elevation", -5, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewFootprint(p, p.Epoch, tt.minElevation)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewFootprint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if math.Abs(got.RadiusKm-tt.wantRadius) > 100 {
+				t.Errorf("radius = %v km, want about %v km", got.RadiusKm, tt.wantRadius)
+			}
+
+			feature := got.GeoJSON()
+			if feature.Geometry.Type != "Polygon" && feature.Geometry.Type != "MultiPolygon" {
+				t.Errorf("invalid geometry %v", feature.Geometry.Type)
+			}
+		})
+	}
+}
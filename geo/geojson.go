@@ -0,0 +1,96 @@
+package geo
+
+import (
+	"net/http"
+	"time"
+)
+
+// GeoJSONContentType media type of GeoJSON documents (RFC 7946)
+const GeoJSONContentType = "application/geo+json"
+
+// Geometry GeoJSON geometry
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// Feature GeoJSON feature
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+func (f Feature) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// FeatureCollection GeoJSON feature collection
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+func (fc FeatureCollection) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// NewFeatureCollection wraps the features in a collection
+func NewFeatureCollection(features ...Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// GeoJSON ground track as a MultiLineString feature of [longitude, latitude] points, one line per segment
+func (g GroundTrack) GeoJSON() Feature {
+	lines := make([][][2]float64, 0, len(g.Segments))
+	for _, segment := range g.Segments {
+		line := make([][2]float64, 0, len(segment))
+		for _, point := range segment {
+			line = append(line, [2]float64{point.Longitude, point.Latitude})
+		}
+		lines = append(lines, line)
+	}
+
+	return Feature{
+		Type:     "Feature",
+		Geometry: Geometry{Type: "MultiLineString", Coordinates: lines},
+		Properties: map[string]interface{}{
+			"satellite_name": g.SatelliteName,
+			"norad_id":       g.NORADID,
+			"start":          g.Start.UTC().Format(time.RFC3339),
+			"end":            g.End.UTC().Format(time.RFC3339),
+			"step_seconds":   g.StepSeconds,
+		},
+	}
+}
+
+// GeoJSON footprint as a Polygon feature, or a MultiPolygon feature when it is split at the antimeridian
+func (f Footprint) GeoJSON() Feature {
+	geometry := Geometry{Type: "Polygon", Coordinates: [][][2]float64{f.Polygons[0]}}
+	if len(f.Polygons) > 1 {
+		polygons := make([][][][2]float64, 0, len(f.Polygons))
+		for _, polygon := range f.Polygons {
+			polygons = append(polygons, [][][2]float64{polygon})
+		}
+		geometry = Geometry{Type: "MultiPolygon", Coordinates: polygons}
+	}
+
+	return Feature{
+		Type:     "Feature",
+		Geometry: geometry,
+		Properties: map[string]interface{}{
+			"satellite_name":      f.SatelliteName,
+			"norad_id":            f.NORADID,
+			"time":                f.Time.UTC().Format(time.RFC3339),
+			"latitude":            f.SubSatellitePoint.Latitude,
+			"longitude":           f.SubSatellitePoint.Longitude,
+			"altitude":            f.SubSatellitePoint.Altitude,
+			"min_elevation":       f.MinElevation,
+			"earth_central_angle": f.CentralAngle,
+			"radius_km":           f.RadiusKm,
+		},
+	}
+}
@@ -0,0 +1,141 @@
+// Package geo computes the ground track and coverage footprint of satellites, and renders them in map formats
+package geo
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+// MaxTrackPoints maximum number of propagated points of a ground track
+const MaxTrackPoints = 10000
+
+// TrackPoint sub-satellite point at a given time
+type TrackPoint struct {
+	Time time.Time `json:"time"`
+	frames.Geodetic
+}
+
+// GroundTrack sub-satellite points over a time window, split in segments at the antimeridian
+type GroundTrack struct {
+	SatelliteName string         `json:"satellite_name"`
+	NORADID       int            `json:"norad_id"`
+	Start         time.Time      `json:"start"`
+	End           time.Time      `json:"end"`
+	StepSeconds   float64        `json:"step_seconds"`
+	Segments      [][]TrackPoint `json:"segments"`
+}
+
+func (g GroundTrack) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// NewGroundTrack propagates the satellite from start to end with the given step. The end of the window is always included.
+func NewGroundTrack(p *sgp4.Propagator, start, end time.Time, step time.Duration) (GroundTrack, error) {
+	points, err := Sample(p, start, end, step)
+	if err != nil {
+		return GroundTrack{}, err
+	}
+
+	return GroundTrack{
+		SatelliteName: p.Satellite.SatelliteName,
+		NORADID:       p.Satellite.NORADID,
+		Start:         start,
+		End:           end,
+		StepSeconds:   step.Seconds(),
+		Segments:      splitAntimeridian(points),
+	}, nil
+}
+
+// Sample computes the sub-satellite points from start to end with the given step, the end of the window being always included
+func Sample(p *sgp4.Propagator, start, end time.Time, step time.Duration) ([]TrackPoint, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("end must be after start")
+	}
+	if count := end.Sub(start) / step; count >= MaxTrackPoints {
+		return nil, fmt.Errorf("too many points (%d), the maximum is %d", count+1, MaxTrackPoints)
+	}
+
+	points := make([]TrackPoint, 0, end.Sub(start)/step+2)
+	for t := start; ; t = t.Add(step) {
+		if t.After(end) {
+			t = end
+		}
+
+		point, err := SubSatellitePoint(p, t)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+
+		if t.Equal(end) {
+			return points, nil
+		}
+	}
+}
+
+// splitAntimeridian splits the points in segments not crossing the antimeridian, the crossing point being added at both ends
+func splitAntimeridian(points []TrackPoint) [][]TrackPoint {
+	var segments [][]TrackPoint
+	var segment []TrackPoint
+	for _, point := range points {
+		if len(segment) > 0 {
+			previous := segment[len(segment)-1]
+			if crossesAntimeridian(previous.Longitude, point.Longitude) {
+				before, after := antimeridianCrossing(previous, point)
+				segments = append(segments, append(segment, before))
+				segment = []TrackPoint{after}
+			}
+		}
+		segment = append(segment, point)
+	}
+	return append(segments, segment)
+}
+
+// SubSatellitePoint geodetic coordinates of the satellite at the given time
+func SubSatellitePoint(p *sgp4.Propagator, t time.Time) (TrackPoint, error) {
+	state, err := p.Propagate(t)
+	if err != nil {
+		return TrackPoint{}, err
+	}
+	return TrackPoint{Time: t, Geodetic: frames.TEMEToGeodetic(state.Position, t)}, nil
+}
+
+// crossesAntimeridian true if the shortest path between the two longitudes crosses the antimeridian
+func crossesAntimeridian(lon1, lon2 float64) bool {
+	return math.Abs(lon2-lon1) > 180
+}
+
+// antimeridianCrossing interpolates the point where the track crosses the antimeridian between two points,
+// and returns it on both sides of the antimeridian
+func antimeridianCrossing(p1, p2 TrackPoint) (TrackPoint, TrackPoint) {
+	lon2 := p2.Longitude
+	edge := 180.0
+	if p1.Longitude > lon2 {
+		lon2 += 360
+	} else {
+		lon2 -= 360
+		edge = -180
+	}
+
+	ratio := (edge - p1.Longitude) / (lon2 - p1.Longitude)
+	crossing := TrackPoint{
+		Time: p1.Time.Add(time.Duration(ratio * float64(p2.Time.Sub(p1.Time)))),
+		Geodetic: frames.Geodetic{
+			Latitude:  p1.Latitude + ratio*(p2.Latitude-p1.Latitude),
+			Longitude: edge,
+			Altitude:  p1.Altitude + ratio*(p2.Altitude-p1.Altitude),
+		},
+	}
+
+	after := crossing
+	after.Longitude = -edge
+	return crossing, after
+}
@@ -0,0 +1,222 @@
+package geo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+const (
+	// DefaultSunElevation maximum elevation of the Sun for the observer to be in darkness, the end of the civil twilight
+	DefaultSunElevation = -6.0
+	// visibilityStep step of the optical visibility search within a pass
+	visibilityStep = 10 * time.Second
+)
+
+// PassOptions parameters of a pass search, the elevations being in degrees
+type PassOptions struct {
+	MinElevation float64
+	// SunElevation maximum elevation of the Sun for an optical observation
+	SunElevation float64
+	Step         time.Duration
+	// EOP Earth orientation parameters of the look angles, polar motion and UT1 being neglected when nil
+	EOP *frames.EOPTable
+}
+
+// Pass passage of a satellite above the minimum elevation of an observer. The rise and set times are truncated to the search window.
+// The pass is optically visible when the satellite is not in the umbra while the Sun is below the elevation threshold for the observer.
+type Pass struct {
+	Rise               time.Time  `json:"rise"`
+	RiseAzimuth        float64    `json:"rise_azimuth"`
+	Culmination        time.Time  `json:"culmination"`
+	MaxElevation       float64    `json:"max_elevation"`
+	CulminationAzimuth float64    `json:"culmination_azimuth"`
+	Set                time.Time  `json:"set"`
+	SetAzimuth         float64    `json:"set_azimuth"`
+	DurationSeconds    float64    `json:"duration_seconds"`
+	Visible            bool       `json:"visible"`
+	VisibleStart       *time.Time `json:"visible_start,omitempty"`
+	VisibleEnd         *time.Time `json:"visible_end,omitempty"`
+}
+
+// Passes passes of a satellite above an observer over a time window
+type Passes struct {
+	SatelliteName string          `json:"satellite_name"`
+	NORADID       int             `json:"norad_id"`
+	Observer      frames.Geodetic `json:"observer"`
+	MinElevation  float64         `json:"min_elevation"`
+	SunElevation  float64         `json:"sun_elevation"`
+	Start         time.Time       `json:"start"`
+	End           time.Time       `json:"end"`
+	Passes        []Pass          `json:"passes"`
+}
+
+func (p Passes) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// NewPasses searches the passes of the satellite over the observer from start to end. The elevation is sampled with the step of the options,
+// and the rise and set times are refined by bisection: passes shorter than the step may be missed. The search stops with the context error
+// when the context is done.
+func NewPasses(ctx context.Context, p *sgp4.Propagator, observer frames.Geodetic, start, end time.Time, options PassOptions) (Passes, error) {
+	if err := ValidatePassSearch(observer, start, end, options); err != nil {
+		return Passes{}, err
+	}
+
+	var propagationErr error
+	lookAngles := func(t time.Time) frames.LookAngles {
+		state, err := p.Propagate(t)
+		if err != nil {
+			if propagationErr == nil {
+				propagationErr = err
+			}
+			return frames.LookAngles{Elevation: -90}
+		}
+		eop, _ := options.EOP.At(t)
+		r, v := frames.TEMEToITRF(state.Position, state.Velocity, t, eop)
+		return frames.NewLookAngles(observer, r, v)
+	}
+	above := func(t time.Time) bool { return lookAngles(t).Elevation >= options.MinElevation }
+
+	passes := Passes{
+		SatelliteName: p.Satellite.SatelliteName,
+		NORADID:       p.Satellite.NORADID,
+		Observer:      observer,
+		MinElevation:  options.MinElevation,
+		SunElevation:  options.SunElevation,
+		Start:         start,
+		End:           end,
+		Passes:        []Pass{},
+	}
+
+	var rise, highest time.Time
+	var maxElevation float64
+	inPass := false
+	previousTime := start
+	forEachSample(start, end, options.Step, func(t time.Time) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		elevation := lookAngles(t).Elevation
+		if propagationErr != nil {
+			return false
+		}
+
+		switch {
+		case elevation >= options.MinElevation && !inPass:
+			inPass, rise, highest, maxElevation = true, start, t, elevation
+			if !t.Equal(start) {
+				rise = bisect(previousTime, t, above)
+			}
+		case elevation >= options.MinElevation && elevation > maxElevation:
+			highest, maxElevation = t, elevation
+		case elevation < options.MinElevation && inPass:
+			inPass = false
+			passes.Passes = append(passes.Passes, newPass(p, observer, options, lookAngles, rise, highest, bisect(previousTime, t, above)))
+		}
+
+		previousTime = t
+		return true
+	})
+	if err := ctx.Err(); err != nil {
+		return Passes{}, err
+	}
+	if inPass && propagationErr == nil {
+		passes.Passes = append(passes.Passes, newPass(p, observer, options, lookAngles, rise, highest, end))
+	}
+	if propagationErr != nil {
+		return Passes{}, propagationErr
+	}
+
+	return passes, nil
+}
+
+// ValidatePassSearch checks the observer, the window and the options of a pass search
+func ValidatePassSearch(observer frames.Geodetic, start, end time.Time, options PassOptions) error {
+	if err := ValidateObserver(observer); err != nil {
+		return err
+	}
+	if options.MinElevation < 0 || options.MinElevation >= 90 {
+		return fmt.Errorf("minimum elevation must be between 0 and 90 degrees")
+	}
+	if options.SunElevation < -90 || options.SunElevation > 90 {
+		return fmt.Errorf("sun elevation must be between -90 and 90 degrees")
+	}
+	return validateSearch(start, end, options.Step)
+}
+
+// newPass refines the culmination around the highest sample of the pass, and searches its optical visibility
+func newPass(p *sgp4.Propagator, observer frames.Geodetic, options PassOptions, lookAngles func(t time.Time) frames.LookAngles, rise, highest, set time.Time) Pass {
+	// golden section search of the maximum elevation between the samples around the highest one
+	low, high := highest.Add(-options.Step), highest.Add(options.Step)
+	if low.Before(rise) {
+		low = rise
+	}
+	if high.After(set) {
+		high = set
+	}
+	const ratio = 0.6180339887498949
+	for high.Sub(low) > eventPrecision {
+		left := high.Add(-time.Duration(ratio * float64(high.Sub(low))))
+		right := low.Add(time.Duration(ratio * float64(high.Sub(low))))
+		if lookAngles(left).Elevation < lookAngles(right).Elevation {
+			low = left
+		} else {
+			high = right
+		}
+	}
+	culmination := low.Add(high.Sub(low) / 2).Round(eventPrecision)
+	if culmination.Before(rise) || culmination.After(set) {
+		culmination = highest
+	}
+
+	riseAngles, culminationAngles, setAngles := lookAngles(rise), lookAngles(culmination), lookAngles(set)
+	pass := Pass{
+		Rise:               rise,
+		RiseAzimuth:        riseAngles.Azimuth,
+		Culmination:        culmination,
+		MaxElevation:       culminationAngles.Elevation,
+		CulminationAzimuth: culminationAngles.Azimuth,
+		Set:                set,
+		SetAzimuth:         setAngles.Azimuth,
+		DurationSeconds:    set.Sub(rise).Seconds(),
+	}
+
+	visible := func(t time.Time) bool {
+		shadow, _, err := SatelliteShadow(p, t)
+		if err != nil || shadow == ShadowUmbra {
+			return false
+		}
+		eop, _ := options.EOP.At(t)
+		return frames.SunElevation(observer, t, eop) < options.SunElevation
+	}
+	var first, last, previous time.Time
+	previousVisible := false
+	forEachSample(rise, set, visibilityStep, func(t time.Time) bool {
+		isVisible := visible(t)
+		if isVisible && first.IsZero() {
+			first = t
+			if !t.Equal(rise) {
+				first = bisect(previous, t, visible)
+			}
+		}
+		if !isVisible && previousVisible {
+			last = bisect(previous, t, visible)
+		}
+		if isVisible && t.Equal(set) {
+			last = set
+		}
+		previous, previousVisible = t, isVisible
+		return true
+	})
+	if !first.IsZero() {
+		pass.Visible = true
+		pass.VisibleStart, pass.VisibleEnd = &first, &last
+	}
+
+	return pass
+}
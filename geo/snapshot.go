@@ -0,0 +1,161 @@
+package geo
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+// Snapshot frames
+const (
+	FrameTEME     = frames.FrameTEME
+	FrameJ2000    = frames.FrameJ2000
+	FrameGCRF     = frames.FrameGCRF
+	FrameITRF     = frames.FrameITRF
+	FrameECEF     = frames.FrameECEF
+	FrameGeodetic = "geodetic"
+)
+
+// Snapshot positions of a set of satellites at the same time, as flat arrays of 3 values per satellite in the order of NORADIDs:
+// x, y, z in km for the cartesian frames, latitude, longitude in degrees and altitude in km for the geodetic frame.
+// Velocities are in km/s, and not available in the geodetic frame.
+type Snapshot struct {
+	Time       time.Time `json:"time"`
+	Frame      string    `json:"frame"`
+	NORADIDs   []int     `json:"norad_ids"`
+	Names      []string  `json:"names,omitempty"`
+	Positions  []float64 `json:"positions"`
+	Velocities []float64 `json:"velocities,omitempty"`
+	Failed     []int     `json:"failed"`
+}
+
+func (s Snapshot) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// SnapshotOptions content of the snapshot
+type SnapshotOptions struct {
+	Frame    string
+	Names    bool
+	Velocity bool
+	// EOP Earth orientation parameters at the time of the snapshot, used by the Earth fixed frames
+	EOP frames.EOP
+	// Workers number of concurrent propagations, the number of CPUs when 0
+	Workers int
+}
+
+// ValidateFrame checks that the frame is supported by the snapshots
+func ValidateFrame(frame string) error {
+	if frame == FrameGeodetic {
+		return nil
+	}
+	if err := frames.ValidateFrame(frame); err != nil {
+		return fmt.Errorf("invalid frame %v, expected teme, j2000, gcrf, itrf, ecef or geodetic", frame)
+	}
+	return nil
+}
+
+// NewSnapshot propagates the satellites to the given time, concurrently. The satellites which cannot be propagated
+// are listed in Failed, and left out of the position arrays.
+func NewSnapshot(propagators []*sgp4.Propagator, t time.Time, options SnapshotOptions) (Snapshot, error) {
+	if err := ValidateFrame(options.Frame); err != nil {
+		return Snapshot{}, err
+	}
+	if options.Velocity && options.Frame == FrameGeodetic {
+		return Snapshot{}, fmt.Errorf("velocities are not available in the geodetic frame")
+	}
+
+	// all workers write to their own indexes of the same arrays, the failed satellites being removed afterwards
+	positions := make([]float64, 3*len(propagators))
+	var velocities []float64
+	if options.Velocity {
+		velocities = make([]float64, 3*len(propagators))
+	}
+	failed := make([]bool, len(propagators))
+
+	parallelize(len(propagators), options.Workers, func(i int) {
+		state, err := propagators[i].Propagate(t)
+		if err != nil {
+			failed[i] = true
+			return
+		}
+		position, velocity := snapshotState(state, t, options.Frame, options.EOP)
+		copy(positions[3*i:3*i+3], position[:])
+		if velocities != nil {
+			copy(velocities[3*i:3*i+3], velocity[:])
+		}
+	})
+
+	snapshot := Snapshot{
+		Time:      t,
+		Frame:     options.Frame,
+		NORADIDs:  make([]int, 0, len(propagators)),
+		Positions: positions[:0],
+		Failed:    []int{},
+	}
+	if options.Names {
+		snapshot.Names = make([]string, 0, len(propagators))
+	}
+	if velocities != nil {
+		snapshot.Velocities = velocities[:0]
+	}
+
+	// compaction in place, the arrays being only read ahead of the write index
+	for i, p := range propagators {
+		if failed[i] {
+			snapshot.Failed = append(snapshot.Failed, p.Satellite.NORADID)
+			continue
+		}
+		snapshot.NORADIDs = append(snapshot.NORADIDs, p.Satellite.NORADID)
+		if options.Names {
+			snapshot.Names = append(snapshot.Names, p.Satellite.SatelliteName)
+		}
+		snapshot.Positions = append(snapshot.Positions, positions[3*i:3*i+3]...)
+		if velocities != nil {
+			snapshot.Velocities = append(snapshot.Velocities, velocities[3*i:3*i+3]...)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// snapshotState converts the TEME state to the frame of the snapshot
+func snapshotState(state sgp4.State, t time.Time, frame string, eop frames.EOP) ([3]float64, [3]float64) {
+	if frame == FrameGeodetic {
+		position, _ := frames.TEMEToITRF(state.Position, state.Velocity, t, eop)
+		g := frames.ECEFToGeodetic(position)
+		return [3]float64{g.Latitude, g.Longitude, g.Altitude}, [3]float64{}
+	}
+	position, velocity, _ := frames.FromTEME(frame, state.Position, state.Velocity, t, eop)
+	return position, velocity
+}
+
+// parallelize calls fn for the indexes from 0 to n-1, split in contiguous chunks over the workers, the number of CPUs when 0
+func parallelize(n, workers int, fn func(i int)) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	chunk := (n + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += chunk {
+		end := start + chunk
+		if end > n {
+			end = n
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				fn(i)
+			}
+		}(start, end)
+	}
+	wg.Wait()
+}
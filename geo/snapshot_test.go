@@ -0,0 +1,132 @@
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+// catalogPropagators initializes the propagators of the active satellites sample
+func catalogPropagators(tb testing.TB) []*sgp4.Propagator {
+	tb.Helper()
+	sats, err := data.NewFileSource("../samples/active_satellites_tle.txt").GetData()
+	if err != nil {
+		tb.Fatalf("GetData() error = %v", err)
+	}
+
+	propagators := make([]*sgp4.Propagator, 0, len(sats))
+	for _, sat := range sats {
+		p, err := sgp4.New(sat)
+		if err != nil {
+			continue
+		}
+		propagators = append(propagators, p)
+	}
+	return propagators
+}
+
+func TestNewSnapshot(t *testing.T) {
+	p := testPropagator(t)
+	decayed, err := data.GenerateTLE(data.KeplerianElements{NORADID: 99902, Epoch: p.Epoch, ApogeeKm: 180, PerigeeKm: 150, BStar: 0.5})
+	if err != nil {
+		t.Fatalf("GenerateTLE() error = %v", err)
+	}
+	decayedPropagator, err := sgp4.New(decayed)
+	if err != nil {
+		t.Fatalf("sgp4.New() error = %v", err)
+	}
+	propagators := []*sgp4.Propagator{p, decayedPropagator, p}
+	at := p.Epoch.Add(30 * 24 * time.Hour)
+
+	state, err := p.Propagate(at)
+	if err != nil {
+		t.Fatalf("Propagate() error = %v", err)
+	}
+	point, err := SubSatellitePoint(p, at)
+	if err != nil {
+		t.Fatalf("SubSatellitePoint() error = %v", err)
+	}
+	gcrf, _ := frames.TEMEToGCRF(state.Position, state.Velocity, at)
+	eop := frames.EOP{XP: 0.2, YP: 0.35, DUT1: -0.1}
+	itrf, _ := frames.TEMEToITRF(state.Position, state.Velocity, at, eop)
+
+	tests := []struct {
+		name         string
+		options      SnapshotOptions
+		wantPosition [3]float64
+		wantVelocity bool
+		wantErr      bool
+	}{
+		{"TEME with velocities", SnapshotOptions{Frame: FrameTEME, Velocity: true, Names: true, Workers: 2}, state.Position, true, false},
+		{"geodetic", SnapshotOptions{Frame: FrameGeodetic, Workers: 8}, [3]float64{point.Latitude, point.Longitude, point.Altitude}, false, false},
+		{"ECEF", SnapshotOptions{Frame: FrameECEF}, [3]float64{}, false, false},
+		{"geodetic velocities", SnapshotOptions{Frame: FrameGeodetic, Velocity: true}, [3]float64{}, false, true},
+		{"GCRF", SnapshotOptions{Frame: FrameGCRF, Velocity: true}, gcrf, true, false},
+		{"ITRF with EOPs", SnapshotOptions{Frame: FrameITRF, EOP: eop}, itrf, false, false},
+		{"invalid frame", SnapshotOptions{Frame: "icrf"}, [3]float64{}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSnapshot(propagators, at, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSnapshot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(got.NORADIDs) != 2 || len(got.Positions) != 6 || len(got.Failed) != 1 || got.Failed[0] != 99902 {
+				t.Fatalf("expected 2 positions and 1 failure, got %+v", got)
+			}
+			if tt.options.Names != (len(got.Names) == 2) || tt.wantVelocity != (len(got.Velocities) == 6) {
+				t.Errorf("invalid names or velocities %+v", got)
+			}
+
+			r := math.Sqrt(got.Positions[3]*got.Positions[3] + got.Positions[4]*got.Positions[4] + got.Positions[5]*got.Positions[5])
+			if tt.options.Frame == FrameECEF && math.Abs(r-(6378+1200)) > 100 {
+				t.Errorf("invalid ECEF position %v", got.Positions[3:6])
+			}
+			if tt.options.Frame == FrameECEF {
+				return
+			}
+			for i := 0; i < 3; i++ {
+				if got.Positions[3+i] != tt.wantPosition[i] {
+					t.Errorf("position = %v, want %v", got.Positions[3:6], tt.wantPosition)
+					break
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewSnapshot(b *testing.B) {
+	propagators := catalogPropagators(b)
+	at := time.Date(2022, 7, 27, 0, 0, 0, 0, time.UTC)
+
+	benchmarks := []struct {
+		name    string
+		options SnapshotOptions
+	}{
+		{"teme", SnapshotOptions{Frame: FrameTEME}},
+		{"teme single worker", SnapshotOptions{Frame: FrameTEME, Workers: 1}},
+		{"geodetic", SnapshotOptions{Frame: FrameGeodetic}},
+		{"ecef with velocities", SnapshotOptions{Frame: FrameECEF, Velocity: true}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := NewSnapshot(propagators, at, bm.options); err != nil {
+					b.Fatalf("NewSnapshot() error = %v", err)
+				}
+			}
+			b.ReportMetric(float64(len(propagators)), "satellites")
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package geo
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+// VisibleSatellite satellite above the minimum elevation of an observer
+type VisibleSatellite struct {
+	SatelliteName string `json:"satellite_name"`
+	NORADID       int    `json:"norad_id"`
+	frames.LookAngles
+}
+
+// Visibility satellites seen by an observer at a given time, sorted by decreasing elevation
+type Visibility struct {
+	Time         time.Time          `json:"time"`
+	Observer     frames.Geodetic    `json:"observer"`
+	MinElevation float64            `json:"min_elevation"`
+	Satellites   []VisibleSatellite `json:"satellites"`
+	Failed       []int              `json:"failed"`
+}
+
+func (v Visibility) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// ValidateObserver checks the coordinates of an observer, latitude and longitude in degrees
+func ValidateObserver(observer frames.Geodetic) error {
+	if observer.Latitude < -90 || observer.Latitude > 90 {
+		return fmt.Errorf("invalid latitude %v, expected a value between -90 and 90", observer.Latitude)
+	}
+	if observer.Longitude < -180 || observer.Longitude > 180 {
+		return fmt.Errorf("invalid longitude %v, expected a value between -180 and 180", observer.Longitude)
+	}
+	return nil
+}
+
+// NewVisibility propagates the satellites to the given time, concurrently, and keeps the ones above the minimum elevation
+// in degrees. The look angles use the Earth orientation parameters at that time, zero when unknown. The satellites which
+// cannot be propagated are listed in Failed.
+func NewVisibility(propagators []*sgp4.Propagator, observer frames.Geodetic, t time.Time, minElevation float64, workers int, eop frames.EOP) (Visibility, error) {
+	if err := ValidateObserver(observer); err != nil {
+		return Visibility{}, err
+	}
+	if minElevation < -90 || minElevation > 90 {
+		return Visibility{}, fmt.Errorf("invalid minimum elevation %v, expected a value between -90 and 90", minElevation)
+	}
+
+	angles := make([]frames.LookAngles, len(propagators))
+	failed := make([]bool, len(propagators))
+	parallelize(len(propagators), workers, func(i int) {
+		state, err := propagators[i].Propagate(t)
+		if err != nil {
+			failed[i] = true
+			return
+		}
+		r, v := frames.TEMEToITRF(state.Position, state.Velocity, t, eop)
+		angles[i] = frames.NewLookAngles(observer, r, v)
+	})
+
+	visibility := Visibility{
+		Time:         t,
+		Observer:     observer,
+		MinElevation: minElevation,
+		Satellites:   []VisibleSatellite{},
+		Failed:       []int{},
+	}
+	for i, p := range propagators {
+		if failed[i] {
+			visibility.Failed = append(visibility.Failed, p.Satellite.NORADID)
+			continue
+		}
+		if angles[i].Elevation < minElevation {
+			continue
+		}
+		visibility.Satellites = append(visibility.Satellites, VisibleSatellite{
+			SatelliteName: p.Satellite.SatelliteName,
+			NORADID:       p.Satellite.NORADID,
+			LookAngles:    angles[i],
+		})
+	}
+
+	sort.SliceStable(visibility.Satellites, func(i, j int) bool {
+		return visibility.Satellites[i].Elevation > visibility.Satellites[j].Elevation
+	})
+
+	return visibility, nil
+}
@@ -0,0 +1,109 @@
+package geo
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+	"github.com/Funkit/tle-provider/frames"
+	"github.com/Funkit/tle-provider/sgp4"
+)
+
+func TestNewVisibility(t *testing.T) {
+	p := testPropagator(t)
+	decayed, err := data.GenerateTLE(data.KeplerianElements{NORADID: 99902, Epoch: p.Epoch, ApogeeKm: 180, PerigeeKm: 150, BStar: 0.5})
+	if err != nil {
+		t.Fatalf("GenerateTLE() error = %v", err)
+	}
+	decayedPropagator, err := sgp4.New(decayed)
+	if err != nil {
+		t.Fatalf("sgp4.New() error = %v", err)
+	}
+	propagators := []*sgp4.Propagator{decayedPropagator, p}
+	at := p.Epoch.Add(30 * 24 * time.Hour)
+
+	point, err := SubSatellitePoint(p, at)
+	if err != nil {
+		t.Fatalf("SubSatellitePoint() error = %v", err)
+	}
+	below := frames.Geodetic{Latitude: point.Latitude, Longitude: point.Longitude}
+	antipode := frames.Geodetic{Latitude: -point.Latitude, Longitude: normalizeLongitude(point.Longitude + 180)}
+
+	tests := []struct {
+		name         string
+		observer     frames.Geodetic
+		minElevation float64
+		wantVisible  int
+		wantErr      bool
+	}{
+		{"below the satellite", below, 10, 1, false},
+		{"antipode", antipode, 0, 0, false},
+		{"below the horizon included", antipode, -90, 1, false},
+		{"invalid latitude", frames.Geodetic{Latitude: 91}, 0, 0, true},
+		{"invalid longitude", frames.Geodetic{Longitude: -181}, 0, 0, true},
+		{"invalid elevation", below, 95, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewVisibility(propagators, tt.observer, at, tt.minElevation, 0, frames.EOP{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewVisibility() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(got.Satellites) != tt.wantVisible || len(got.Failed) != 1 || got.Failed[0] != 99902 {
+				t.Fatalf("expected %d visible satellites and 1 failure, got %+v", tt.wantVisible, got)
+			}
+			if tt.name == "below the satellite" && (got.Satellites[0].Elevation < 89.9 || math.Abs(got.Satellites[0].Range-point.Altitude) > 1) {
+				t.Errorf("expected the satellite at the zenith, got %+v", got.Satellites[0])
+			}
+		})
+	}
+}
+
+func TestNewVisibility_EOP(t *testing.T) {
+	p := testPropagator(t)
+	at := p.Epoch.Add(time.Hour)
+	observer := frames.Geodetic{Latitude: 48.85, Longitude: 2.35}
+	eop := frames.EOP{XP: 0.2, YP: 0.35, DUT1: -0.8}
+
+	state, err := p.Propagate(at)
+	if err != nil {
+		t.Fatalf("Propagate() error = %v", err)
+	}
+	r, v := frames.TEMEToITRF(state.Position, state.Velocity, at, eop)
+	want := frames.NewLookAngles(observer, r, v)
+
+	withoutEOP, err := NewVisibility([]*sgp4.Propagator{p}, observer, at, -90, 0, frames.EOP{})
+	if err != nil {
+		t.Fatalf("NewVisibility() error = %v", err)
+	}
+	withEOP, err := NewVisibility([]*sgp4.Propagator{p}, observer, at, -90, 0, eop)
+	if err != nil {
+		t.Fatalf("NewVisibility() error = %v", err)
+	}
+	got := withEOP.Satellites[0].LookAngles
+	if math.Abs(got.Azimuth-want.Azimuth) > 1e-9 || math.Abs(got.Elevation-want.Elevation) > 1e-9 {
+		t.Errorf("look angles with EOP = %+v, want %+v", got, want)
+	}
+	if withoutEOP.Satellites[0].Elevation == got.Elevation {
+		t.Errorf("expected the EOPs to change the elevation")
+	}
+}
+
+func BenchmarkNewVisibility(b *testing.B) {
+	propagators := catalogPropagators(b)
+	at := time.Date(2022, 7, 27, 0, 0, 0, 0, time.UTC)
+	observer := frames.Geodetic{Latitude: 48.85, Longitude: 2.35, Altitude: 0.035}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewVisibility(propagators, observer, at, 10, 0, frames.EOP{}); err != nil {
+			b.Fatalf("NewVisibility() error = %v", err)
+		}
+	}
+}
@@ -4,14 +4,15 @@ go 1.18
 
 require (
 	github.com/Funkit/go-utils v0.0.0-20220823115447-58380e18fc10
+	github.com/fsnotify/fsnotify v1.6.0
 	github.com/go-chi/chi/v5 v5.0.0
 	github.com/go-chi/render v1.0.1
+	github.com/klauspost/compress v1.16.7
 	github.com/spf13/cobra v1.6.1
 	github.com/spf13/viper v1.14.0
 )
 
 require (
-	github.com/fsnotify/fsnotify v1.6.0 // indirect
 	github.com/hashicorp/hcl v1.0.0 // indirect
 	github.com/inconshreveable/mousetrap v1.0.1 // indirect
 	github.com/magiconair/properties v1.8.6 // indirect
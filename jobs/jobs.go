@@ -0,0 +1,542 @@
+// Package jobs runs the long running computations in the background, with a bounded number of workers.
+// Jobs and results can be persisted in a directory to survive restarts, and expire after a retention period.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+)
+
+// Status state of a job
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+const (
+	// DefaultWorkers number of jobs running concurrently when not configured
+	DefaultWorkers = 2
+	// DefaultQueueSize maximum number of queued jobs when not configured
+	DefaultQueueSize = 100
+	// DefaultRetention retention period of the completed jobs when not configured
+	DefaultRetention = 24 * time.Hour
+
+	// cleanupInterval period of the removal of the expired jobs
+	cleanupInterval = time.Minute
+	resultSuffix    = ".result.json"
+)
+
+var (
+	ErrUnknownType  = errors.New("unknown job type")
+	ErrNotFound     = errors.New("job not found")
+	ErrQueueFull    = errors.New("job queue full")
+	ErrNotCompleted = errors.New("job not completed")
+	ErrCompleted    = errors.New("job already completed")
+	ErrNoResult     = errors.New("job without result")
+)
+
+// Job background computation of a given type, from its JSON parameters
+type Job struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Status    Status          `json:"status"`
+	Params    json.RawMessage `json:"params"`
+	Submitted time.Time       `json:"submitted"`
+	Started   *time.Time      `json:"started,omitempty"`
+	Completed *time.Time      `json:"completed,omitempty"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+func (j Job) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// completed true if the job will not run anymore
+func (j Job) completed() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed || j.Status == StatusCanceled
+}
+
+// expired true if the retention period of the completed job is over
+func (j Job) expired(now time.Time) bool {
+	return j.ExpiresAt != nil && !now.Before(*j.ExpiresAt)
+}
+
+// Type kind of job
+type Type struct {
+	// Prepare validates the parameters at submission, and returns them with their default values set.
+	// The returned parameters are stored with the job, so that it runs identically after a restart.
+	Prepare func(params json.RawMessage) (interface{}, error)
+	// Run executes the job and returns its result, marshaled as JSON. It must return when the context is canceled.
+	Run func(ctx context.Context, params json.RawMessage) (interface{}, error)
+}
+
+// Manager queues the submitted jobs and runs them with a bounded number of workers
+type Manager struct {
+	workers   int
+	queueSize int
+	retention time.Duration
+	directory string
+
+	mu      sync.Mutex
+	types   map[string]Type
+	jobs    map[string]*Job
+	results map[string][]byte
+	cancels map[string]context.CancelFunc
+	// pending identifiers of the queued jobs, by submission time
+	pending []string
+	// wake signals the waiting workers that a job was queued
+	wake chan struct{}
+	ctx  context.Context
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+// NewManager creates a manager with the configured workers, queue size and retention. With a store directory,
+// the jobs saved by a previous run are loaded, the interrupted ones being queued again when the manager starts.
+func NewManager(config data.JobsConfiguration) (*Manager, error) {
+	m := &Manager{
+		workers:   config.Workers,
+		queueSize: config.QueueSize,
+		retention: time.Duration(config.RetentionMinutes) * time.Minute,
+		directory: config.StoreDirectory,
+		types:     make(map[string]Type),
+		jobs:      make(map[string]*Job),
+		results:   make(map[string][]byte),
+		cancels:   make(map[string]context.CancelFunc),
+		wake:      make(chan struct{}, 1),
+	}
+	if m.workers <= 0 {
+		m.workers = DefaultWorkers
+	}
+	if m.queueSize <= 0 {
+		m.queueSize = DefaultQueueSize
+	}
+	if m.retention <= 0 {
+		m.retention = DefaultRetention
+	}
+
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+
+	// the loaded jobs are queued before the new submissions, even beyond the queue size
+	var queued []*Job
+	for _, job := range m.jobs {
+		if job.Status == StatusQueued {
+			queued = append(queued, job)
+		}
+	}
+	sort.Slice(queued, func(i, j int) bool {
+		return queued[i].Submitted.Before(queued[j].Submitted)
+	})
+	for _, job := range queued {
+		m.pending = append(m.pending, job.ID)
+	}
+
+	return m, nil
+}
+
+// Register adds a job type, before the manager starts
+func (m *Manager) Register(name string, jobType Type) {
+	m.mu.Lock()
+	m.types[name] = jobType
+	m.mu.Unlock()
+}
+
+// Start launches the workers and the removal of the expired jobs
+func (m *Manager) Start() {
+	m.ctx, m.stop = context.WithCancel(context.Background())
+	for i := 0; i < m.workers; i++ {
+		m.wg.Add(1)
+		go m.work()
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case now := <-ticker.C:
+				m.removeExpired(now)
+			}
+		}
+	}()
+}
+
+// Stop interrupts the running jobs and waits for the workers. The interrupted jobs are saved as queued,
+// to run again at the next start.
+func (m *Manager) Stop() {
+	if m.stop == nil {
+		return
+	}
+	m.stop()
+	m.wg.Wait()
+}
+
+// Submit validates the parameters of the job and queues it
+func (m *Manager) Submit(jobType string, params json.RawMessage) (Job, error) {
+	m.mu.Lock()
+	t, ok := m.types[jobType]
+	m.mu.Unlock()
+	if !ok {
+		return Job{}, fmt.Errorf("%w %v", ErrUnknownType, jobType)
+	}
+
+	prepared, err := t.Prepare(params)
+	if err != nil {
+		return Job{}, err
+	}
+	content, err := json.Marshal(prepared)
+	if err != nil {
+		return Job{}, err
+	}
+	id, err := generateID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	job := &Job{
+		ID:        id,
+		Type:      jobType,
+		Status:    StatusQueued,
+		Params:    content,
+		Submitted: time.Now().UTC(),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.pending) >= m.queueSize {
+		return Job{}, ErrQueueFull
+	}
+	m.jobs[id] = job
+	m.pending = append(m.pending, id)
+	m.save(job)
+	m.signal()
+
+	return *job, nil
+}
+
+// Get returns a copy of the job
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok || job.expired(time.Now()) {
+		return Job{}, fmt.Errorf("%w: %v", ErrNotFound, id)
+	}
+	return *job, nil
+}
+
+// List returns the jobs by submission time
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if !job.expired(now) {
+			jobs = append(jobs, *job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Submitted.Before(jobs[j].Submitted)
+	})
+	return jobs
+}
+
+// Result returns the JSON result of a succeeded job
+func (m *Manager) Result(id string) ([]byte, error) {
+	job, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case StatusQueued, StatusRunning:
+		return nil, fmt.Errorf("%w: %v is %v", ErrNotCompleted, id, job.Status)
+	case StatusFailed, StatusCanceled:
+		return nil, fmt.Errorf("%w: %v is %v", ErrNoResult, id, job.Status)
+	}
+
+	m.mu.Lock()
+	result, ok := m.results[id]
+	m.mu.Unlock()
+	if ok {
+		return result, nil
+	}
+	return os.ReadFile(m.resultPath(id))
+}
+
+// Cancel stops a queued or running job
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok || job.expired(time.Now()) {
+		return Job{}, fmt.Errorf("%w: %v", ErrNotFound, id)
+	}
+	if job.completed() {
+		return Job{}, fmt.Errorf("%w: %v is %v", ErrCompleted, id, job.Status)
+	}
+
+	if cancel, ok := m.cancels[id]; ok {
+		cancel()
+	}
+	for i, pendingID := range m.pending {
+		if pendingID == id {
+			m.pending = append(m.pending[:i], m.pending[i+1:]...)
+			break
+		}
+	}
+	m.complete(job, StatusCanceled, "")
+	return *job, nil
+}
+
+// signal wakes a waiting worker, without blocking when a wake up is already pending
+func (m *Manager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// work runs the queued jobs until the manager stops
+func (m *Manager) work() {
+	defer m.wg.Done()
+	m.signal()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-m.wake:
+			for m.ctx.Err() == nil && m.run() {
+			}
+		}
+	}
+}
+
+// run executes the next queued job, and returns false when the queue is empty
+func (m *Manager) run() bool {
+	m.mu.Lock()
+	if len(m.pending) == 0 {
+		m.mu.Unlock()
+		return false
+	}
+	id := m.pending[0]
+	m.pending = m.pending[1:]
+	if len(m.pending) > 0 {
+		// another worker may take the remaining jobs
+		m.signal()
+	}
+
+	job := m.jobs[id]
+	t, ok := m.types[job.Type]
+	if !ok {
+		m.complete(job, StatusFailed, fmt.Sprintf("%v %v", ErrUnknownType, job.Type))
+		m.mu.Unlock()
+		return true
+	}
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	defer cancel()
+	m.cancels[id] = cancel
+	started := time.Now().UTC()
+	job.Status = StatusRunning
+	job.Started = &started
+	params := job.Params
+	m.save(job)
+	m.mu.Unlock()
+
+	result, err := t.Run(ctx, params)
+	var content []byte
+	if err == nil {
+		content, err = json.Marshal(result)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.cancels, id)
+
+	switch {
+	case job.Status == StatusCanceled:
+		// canceled while running, already saved
+	case m.ctx.Err() != nil:
+		job.Status = StatusQueued
+		job.Started = nil
+		m.pending = append([]string{id}, m.pending...)
+		m.save(job)
+	case err != nil:
+		log.Printf("job %s (%s) failed: %v\n", id, job.Type, err)
+		m.complete(job, StatusFailed, err.Error())
+	default:
+		m.storeResult(id, content)
+		m.complete(job, StatusSucceeded, "")
+	}
+	return true
+}
+
+// complete sets the final status of the job and its expiry date
+func (m *Manager) complete(job *Job, status Status, message string) {
+	completed := time.Now().UTC()
+	expiresAt := completed.Add(m.retention)
+	job.Status = status
+	job.Error = message
+	job.Completed = &completed
+	job.ExpiresAt = &expiresAt
+	m.save(job)
+}
+
+// removeExpired deletes the expired jobs and their results
+func (m *Manager) removeExpired(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, job := range m.jobs {
+		if !job.expired(now) {
+			continue
+		}
+		delete(m.jobs, id)
+		delete(m.results, id)
+		if m.directory != "" {
+			for _, path := range []string{m.jobPath(id), m.resultPath(id)} {
+				if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+					log.Println(err.Error())
+				}
+			}
+		}
+	}
+}
+
+// storeResult keeps the result in memory, or writes it in the store directory
+func (m *Manager) storeResult(id string, content []byte) {
+	if m.directory == "" {
+		m.results[id] = content
+		return
+	}
+	if err := writeFile(m.resultPath(id), content); err != nil {
+		log.Printf("job %s: result not saved, kept in memory: %v\n", id, err)
+		m.results[id] = content
+	}
+}
+
+// save writes the job in the store directory, errors being logged as the job is still available in memory
+func (m *Manager) save(job *Job) {
+	if m.directory == "" {
+		return
+	}
+	content, err := json.Marshal(job)
+	if err == nil {
+		err = writeFile(m.jobPath(job.ID), content)
+	}
+	if err != nil {
+		log.Printf("job %s not saved: %v\n", job.ID, err)
+	}
+}
+
+// load reads the jobs saved in the store directory, which is created if it does not exist.
+// The running jobs were interrupted, and are queued again.
+func (m *Manager) load() error {
+	if m.directory == "" {
+		return nil
+	}
+	if err := os.MkdirAll(m.directory, 0o755); err != nil {
+		return err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(m.directory, "*.json"))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, path := range paths {
+		if strings.HasSuffix(path, resultSuffix) {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var job Job
+		if err := json.Unmarshal(content, &job); err != nil {
+			return fmt.Errorf("invalid job file %v: %w", path, err)
+		}
+
+		if job.Status == StatusRunning {
+			job.Status = StatusQueued
+			job.Started = nil
+		}
+		m.jobs[job.ID] = &job
+		if job.expired(now) {
+			continue
+		}
+		if job.Status == StatusSucceeded {
+			if _, err := os.Stat(m.resultPath(job.ID)); err != nil {
+				m.complete(&job, StatusFailed, "result lost")
+			}
+		}
+	}
+	m.removeExpired(now)
+
+	return nil
+}
+
+func (m *Manager) jobPath(id string) string {
+	return filepath.Join(m.directory, id+".json")
+}
+
+func (m *Manager) resultPath(id string) string {
+	return filepath.Join(m.directory, id+resultSuffix)
+}
+
+// writeFile writes to a temporary file renamed afterwards, so that the file is never partially written
+func writeFile(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func generateID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,229 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+)
+
+type testParams struct {
+	Value int  `json:"value"`
+	Fail  bool `json:"fail"`
+	Block bool `json:"block"`
+}
+
+// testType doubles the value of the parameters, fails or blocks until canceled
+var testType = Type{
+	Prepare: func(params json.RawMessage) (interface{}, error) {
+		var p testParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		if p.Value < 0 {
+			return nil, fmt.Errorf("negative value")
+		}
+		return p, nil
+	},
+	Run: func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var p testParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, err
+		}
+		if p.Block {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		if p.Fail {
+			return nil, fmt.Errorf("failure")
+		}
+		return map[string]int{"value": 2 * p.Value}, nil
+	},
+}
+
+func newTestManager(t *testing.T, config data.JobsConfiguration) *Manager {
+	t.Helper()
+	m, err := NewManager(config)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	m.Register("test", testType)
+	m.Start()
+	t.Cleanup(m.Stop)
+	return m
+}
+
+// waitStatus waits until the job reaches the status
+func waitStatus(t *testing.T, m *Manager, id string, status Status) Job {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if job.Status == status {
+			return job
+		}
+	}
+	t.Fatalf("job %s did not reach status %v", id, status)
+	return Job{}
+}
+
+func TestManager(t *testing.T) {
+	m := newTestManager(t, data.JobsConfiguration{Workers: 2})
+
+	tests := []struct {
+		name       string
+		jobType    string
+		params     string
+		wantStatus Status
+		wantResult string
+		wantErr    error
+	}{
+		{"succeeded", "test", `{"value":21}`, StatusSucceeded, `{"value":42}`, nil},
+		{"failed", "test", `{"fail":true}`, StatusFailed, "", ErrNoResult},
+		{"invalid parameters", "test", `{"value":-1}`, "", "", nil},
+		{"unknown type", "unknown", `{}`, "", "", ErrUnknownType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := m.Submit(tt.jobType, json.RawMessage(tt.params))
+			if (err != nil) != (tt.wantStatus == "") {
+				t.Fatalf("Submit() error = %v", err)
+			}
+			if err != nil {
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("Submit() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			job = waitStatus(t, m, job.ID, tt.wantStatus)
+			if job.Completed == nil || job.ExpiresAt == nil || job.Started == nil {
+				t.Errorf("expected completion times, got %+v", job)
+			}
+			result, err := m.Result(job.ID)
+			if !errors.Is(err, tt.wantErr) || string(result) != tt.wantResult {
+				t.Errorf("Result() = %s, %v, want %s, %v", result, err, tt.wantResult, tt.wantErr)
+			}
+		})
+	}
+
+	if jobs := m.List(); len(jobs) != 2 || !jobs[0].Submitted.Before(jobs[1].Submitted) && !jobs[0].Submitted.Equal(jobs[1].Submitted) {
+		t.Errorf("expected 2 jobs by submission time, got %+v", jobs)
+	}
+	if _, err := m.Get("unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestManagerCancel(t *testing.T) {
+	m := newTestManager(t, data.JobsConfiguration{Workers: 1, QueueSize: 1})
+
+	running, err := m.Submit("test", json.RawMessage(`{"block":true}`))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	waitStatus(t, m, running.ID, StatusRunning)
+
+	queued, err := m.Submit("test", json.RawMessage(`{"value":1}`))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if _, err := m.Submit("test", json.RawMessage(`{"value":2}`)); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Submit() error = %v, want %v", err, ErrQueueFull)
+	}
+	if _, err := m.Result(queued.ID); !errors.Is(err, ErrNotCompleted) {
+		t.Errorf("Result() error = %v, want %v", err, ErrNotCompleted)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr error
+	}{
+		{"queued", queued.ID, nil},
+		{"running", running.ID, nil},
+		{"already canceled", running.ID, ErrCompleted},
+		{"unknown", "unknown", ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := m.Cancel(tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Cancel() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && job.Status != StatusCanceled {
+				t.Errorf("expected a canceled job, got %+v", job)
+			}
+		})
+	}
+
+	// the worker is released by the cancellation, and the canceled queued job does not run
+	next, err := m.Submit("test", json.RawMessage(`{"value":3}`))
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	waitStatus(t, m, next.ID, StatusSucceeded)
+	if job, _ := m.Get(queued.ID); job.Status != StatusCanceled || job.Started != nil {
+		t.Errorf("expected the queued job canceled without running, got %+v", job)
+	}
+}
+
+func TestManagerPersistence(t *testing.T) {
+	config := data.JobsConfiguration{Workers: 1, StoreDirectory: t.TempDir()}
+
+	first, err := NewManager(config)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	first.Register("test", testType)
+	first.Start()
+
+	done, _ := first.Submit("test", json.RawMessage(`{"value":1}`))
+	waitStatus(t, first, done.ID, StatusSucceeded)
+	interrupted, _ := first.Submit("test", json.RawMessage(`{"value":2,"block":true}`))
+	waitStatus(t, first, interrupted.ID, StatusRunning)
+	queued, _ := first.Submit("test", json.RawMessage(`{"value":3}`))
+	first.Stop()
+
+	second, err := NewManager(config)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if jobs := second.List(); len(jobs) != 3 || jobs[1].Status != StatusQueued || jobs[2].Status != StatusQueued {
+		t.Fatalf("expected the interrupted and queued jobs to be queued again, got %+v", jobs)
+	}
+	if result, err := second.Result(done.ID); err != nil || string(result) != `{"value":2}` {
+		t.Errorf("Result() = %s, %v, want the saved result", result, err)
+	}
+
+	// the interrupted job is canceled so that the queued one runs
+	second.Register("test", testType)
+	second.Start()
+	defer second.Stop()
+	waitStatus(t, second, interrupted.ID, StatusRunning)
+	if _, err := second.Cancel(interrupted.ID); err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	waitStatus(t, second, queued.ID, StatusSucceeded)
+
+	second.removeExpired(time.Now().Add(DefaultRetention + time.Minute))
+	if jobs := second.List(); len(jobs) != 0 {
+		t.Errorf("expected the expired jobs to be removed, got %+v", jobs)
+	}
+
+	third, err := NewManager(config)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if jobs := third.List(); len(jobs) != 0 {
+		t.Errorf("expected the expired jobs to be removed from the store, got %+v", jobs)
+	}
+}
@@ -0,0 +1,457 @@
+// Package sgp4 propagates TLEs with the SGP4/SDP4 model, following the revised implementation
+// of Vallado, Crawford, Hujsak and Kelso, "Revisiting Spacetrack Report #3" (AIAA 2006-6753), with WGS72 constants
+// and the improved operation mode.
+package sgp4
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+)
+
+// WGS72 constants used by SGP4
+const (
+	mu            = 398600.8
+	radiusEarthKm = 6378.135
+	j2            = 0.001082616
+	j3            = -0.00000253881
+	j4            = -0.00000165597
+	j3oj2         = j3 / j2
+	twoPi         = 2 * math.Pi
+	x2o3          = 2.0 / 3.0
+	xpdotp        = 1440.0 / twoPi
+	// julian date of 1950 January 0, origin of the epoch used by the deep space equations
+	jd1950 = 2433281.5
+)
+
+var xke = 60.0 / math.Sqrt(radiusEarthKm*radiusEarthKm*radiusEarthKm/mu)
+
+// Propagation errors
+var (
+	ErrEccentricity    = errors.New("mean eccentricity out of range")
+	ErrMeanMotion      = errors.New("mean motion is negative")
+	ErrPerturbedEcc    = errors.New("perturbed eccentricity out of range")
+	ErrSemiLatusRectum = errors.New("semi-latus rectum is negative")
+	ErrDecayed         = errors.New("satellite has decayed")
+	errInvalidElements = errors.New("invalid orbital elements")
+)
+
+// State position in km and velocity in km/s in the TEME frame (True Equator, Mean Equinox) at a given time
+type State struct {
+	Time     time.Time  `json:"time"`
+	Position [3]float64 `json:"position"`
+	Velocity [3]float64 `json:"velocity"`
+}
+
+// Propagator SGP4 propagator initialized from a TLE
+type Propagator struct {
+	Satellite data.Satellite
+	Epoch     time.Time
+
+	// elements at epoch
+	bstar, ecco, argpo, inclo, mo, noKozai, nodeo, noUnkozai float64
+
+	// near earth
+	isimp                                               bool
+	deepSpace                                           bool
+	aycof, con41, cc1, cc4, cc5, d2, d3, d4, delmo, eta float64
+	argpdot, omgcof, sinmao, t2cof, t3cof, t4cof, t5cof float64
+	x1mth2, x7thm1, mdot, nodedot, xlcof, xmcof, nodecf float64
+	gsto                                                float64
+
+	// deep space
+	irez                                                       int
+	d2201, d2211, d3210, d3222, d4410, d4422, d5220, d5232     float64
+	d5421, d5433, dedt, del1, del2, del3, didt, dmdt, dnodt    float64
+	domdt, e3, ee2, peo, pgho, pho, pinco, plo, se2, se3       float64
+	sgh2, sgh3, sgh4, sh2, sh3, si2, si3, sl2, sl3, sl4        float64
+	xfact, xgh2, xgh3, xgh4, xh2, xh3, xi2, xi3, xl2, xl3, xl4 float64
+	xlamo, zmol, zmos                                          float64
+}
+
+// New initializes the propagator from the TLE lines of the satellite
+func New(sat data.Satellite) (*Propagator, error) {
+	el, err := data.ParseElements(sat)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Propagator{
+		Satellite: sat,
+		Epoch:     el.Epoch,
+		bstar:     el.BStar,
+		ecco:      el.Eccentricity,
+		argpo:     el.ArgOfPericenter * math.Pi / 180,
+		inclo:     el.Inclination * math.Pi / 180,
+		mo:        el.MeanAnomaly * math.Pi / 180,
+		noKozai:   el.MeanMotion / xpdotp,
+		nodeo:     el.RAAN * math.Pi / 180,
+	}
+
+	if p.noKozai <= 0 || p.ecco < 0 || p.ecco >= 1 {
+		return nil, fmt.Errorf("%w for %v", errInvalidElements, sat.SatelliteName)
+	}
+
+	if err := p.init(); err != nil {
+		return nil, fmt.Errorf("%v: %w", sat.SatelliteName, err)
+	}
+
+	return p, nil
+}
+
+// Propagate computes the state of the satellite at the given time
+func (p *Propagator) Propagate(t time.Time) (State, error) {
+	tsince := t.Sub(p.Epoch).Minutes()
+	r, v, err := p.propagate(tsince)
+	if err != nil {
+		return State{}, fmt.Errorf("%v at %s: %w", p.Satellite.SatelliteName, t.UTC().Format(time.RFC3339), err)
+	}
+	return State{Time: t, Position: r, Velocity: v}, nil
+}
+
+// Period orbital period derived from the unkozaied mean motion
+func (p *Propagator) Period() time.Duration {
+	return time.Duration(twoPi / p.noUnkozai * float64(time.Minute))
+}
+
+// julianDate julian date of the time
+func julianDate(t time.Time) float64 {
+	return float64(t.UnixNano())/86400e9 + 2440587.5
+}
+
+// gstime Greenwich mean sidereal time in radians, IAU-82 model
+func gstime(jdut1 float64) float64 {
+	tut1 := (jdut1 - 2451545.0) / 36525.0
+	temp := -6.2e-6*tut1*tut1*tut1 + 0.093104*tut1*tut1 + (876600.0*3600+8640184.812866)*tut1 + 67310.54841
+	temp = math.Mod(temp*math.Pi/180/240.0, twoPi)
+	if temp < 0 {
+		temp += twoPi
+	}
+	return temp
+}
+
+// init initializes the SGP4 constants from the elements, sgp4init in the reference implementation
+func (p *Propagator) init() error {
+	const temp4 = 1.5e-12
+
+	jdEpoch := julianDate(p.Epoch)
+	epoch := jdEpoch - jd1950
+
+	ss := 78.0/radiusEarthKm + 1.0
+	qzms2ttemp := (120.0 - 78.0) / radiusEarthKm
+	qzms2t := qzms2ttemp * qzms2ttemp * qzms2ttemp * qzms2ttemp
+
+	// initl
+	eccsq := p.ecco * p.ecco
+	omeosq := 1.0 - eccsq
+	rteosq := math.Sqrt(omeosq)
+	cosio := math.Cos(p.inclo)
+	cosio2 := cosio * cosio
+
+	ak := math.Pow(xke/p.noKozai, x2o3)
+	d1 := 0.75 * j2 * (3.0*cosio2 - 1.0) / (rteosq * omeosq)
+	del := d1 / (ak * ak)
+	adel := ak * (1.0 - del*del - del*(1.0/3.0+134.0*del*del/81.0))
+	del = d1 / (adel * adel)
+	p.noUnkozai = p.noKozai / (1.0 + del)
+
+	ao := math.Pow(xke/p.noUnkozai, x2o3)
+	sinio := math.Sin(p.inclo)
+	po := ao * omeosq
+	con42 := 1.0 - 5.0*cosio2
+	p.con41 = -con42 - cosio2 - cosio2
+	posq := po * po
+	rp := ao * (1.0 - p.ecco)
+	p.gsto = gstime(jdEpoch)
+
+	if omeosq < 0 && p.noUnkozai < 0 {
+		return errInvalidElements
+	}
+
+	p.isimp = rp < 220.0/radiusEarthKm+1.0
+	sfour := ss
+	qzms24 := qzms2t
+	perige := (rp - 1.0) * radiusEarthKm
+
+	// for perigees below 156 km, s and qoms2t are altered
+	if perige < 156.0 {
+		sfour = perige - 78.0
+		if perige < 98.0 {
+			sfour = 20.0
+		}
+		qzms24temp := (120.0 - sfour) / radiusEarthKm
+		qzms24 = qzms24temp * qzms24temp * qzms24temp * qzms24temp
+		sfour = sfour/radiusEarthKm + 1.0
+	}
+	pinvsq := 1.0 / posq
+
+	tsi := 1.0 / (ao - sfour)
+	p.eta = ao * p.ecco * tsi
+	etasq := p.eta * p.eta
+	eeta := p.ecco * p.eta
+	psisq := math.Abs(1.0 - etasq)
+	coef := qzms24 * math.Pow(tsi, 4.0)
+	coef1 := coef / math.Pow(psisq, 3.5)
+	cc2 := coef1 * p.noUnkozai * (ao*(1.0+1.5*etasq+eeta*(4.0+etasq)) +
+		0.375*j2*tsi/psisq*p.con41*(8.0+3.0*etasq*(8.0+etasq)))
+	p.cc1 = p.bstar * cc2
+	cc3 := 0.0
+	if p.ecco > 1.0e-4 {
+		cc3 = -2.0 * coef * tsi * j3oj2 * p.noUnkozai * sinio / p.ecco
+	}
+	p.x1mth2 = 1.0 - cosio2
+	p.cc4 = 2.0 * p.noUnkozai * coef1 * ao * omeosq *
+		(p.eta*(2.0+0.5*etasq) + p.ecco*(0.5+2.0*etasq) -
+			j2*tsi/(ao*psisq)*(-3.0*p.con41*(1.0-2.0*eeta+etasq*(1.5-0.5*eeta))+
+				0.75*p.x1mth2*(2.0*etasq-eeta*(1.0+etasq))*math.Cos(2.0*p.argpo)))
+	p.cc5 = 2.0 * coef1 * ao * omeosq * (1.0 + 2.75*(etasq+eeta) + eeta*etasq)
+	cosio4 := cosio2 * cosio2
+	temp1 := 1.5 * j2 * pinvsq * p.noUnkozai
+	temp2 := 0.5 * temp1 * j2 * pinvsq
+	temp3 := -0.46875 * j4 * pinvsq * pinvsq * p.noUnkozai
+	p.mdot = p.noUnkozai + 0.5*temp1*rteosq*p.con41 + 0.0625*temp2*rteosq*(13.0-78.0*cosio2+137.0*cosio4)
+	p.argpdot = -0.5*temp1*con42 + 0.0625*temp2*(7.0-114.0*cosio2+395.0*cosio4) + temp3*(3.0-36.0*cosio2+49.0*cosio4)
+	xhdot1 := -temp1 * cosio
+	p.nodedot = xhdot1 + (0.5*temp2*(4.0-19.0*cosio2)+2.0*temp3*(3.0-7.0*cosio2))*cosio
+	xpidot := p.argpdot + p.nodedot
+	p.omgcof = p.bstar * cc3 * math.Cos(p.argpo)
+	p.xmcof = 0.0
+	if p.ecco > 1.0e-4 {
+		p.xmcof = -x2o3 * coef * p.bstar / eeta
+	}
+	p.nodecf = 3.5 * omeosq * xhdot1 * p.cc1
+	p.t2cof = 1.5 * p.cc1
+	// divide by zero for inclination = 180 deg
+	if math.Abs(cosio+1.0) > 1.5e-12 {
+		p.xlcof = -0.25 * j3oj2 * sinio * (3.0 + 5.0*cosio) / (1.0 + cosio)
+	} else {
+		p.xlcof = -0.25 * j3oj2 * sinio * (3.0 + 5.0*cosio) / temp4
+	}
+	p.aycof = -0.5 * j3oj2 * sinio
+	delmotemp := 1.0 + p.eta*math.Cos(p.mo)
+	p.delmo = delmotemp * delmotemp * delmotemp
+	p.sinmao = math.Sin(p.mo)
+	p.x7thm1 = 7.0*cosio2 - 1.0
+
+	// deep space initialization, for periods of 225 minutes or more
+	if twoPi/p.noUnkozai >= 225.0 {
+		p.deepSpace = true
+		p.isimp = true
+		tc := 0.0
+		inclm := p.inclo
+
+		ds := p.dscom(epoch, p.ecco, p.argpo, tc, p.inclo, p.nodeo, p.noUnkozai)
+
+		p.dsinit(ds, tc, xpidot, eccsq, inclm)
+	}
+
+	// set variables if not deep space
+	if !p.isimp {
+		cc1sq := p.cc1 * p.cc1
+		p.d2 = 4.0 * ao * tsi * cc1sq
+		temp := p.d2 * tsi * p.cc1 / 3.0
+		p.d3 = (17.0*ao + sfour) * temp
+		p.d4 = 0.5 * temp * ao * tsi * (221.0*ao + 31.0*sfour) * p.cc1
+		p.t3cof = p.d2 + 2.0*cc1sq
+		p.t4cof = 0.25 * (3.0*p.d3 + p.cc1*(12.0*p.d2+10.0*cc1sq))
+		p.t5cof = 0.2 * (3.0*p.d4 + 12.0*p.cc1*p.d3 + 6.0*p.d2*p.d2 + 15.0*cc1sq*(2.0*p.d2+cc1sq))
+	}
+
+	_, _, err := p.propagate(0)
+	return err
+}
+
+// propagate computes the position in km and velocity in km/s in TEME, tsince minutes after the epoch
+func (p *Propagator) propagate(tsince float64) ([3]float64, [3]float64, error) {
+	const temp4 = 1.5e-12
+	var r, v [3]float64
+
+	vkmpersec := radiusEarthKm * xke / 60.0
+	t := tsince
+
+	// update for secular gravity and atmospheric drag
+	xmdf := p.mo + p.mdot*t
+	argpdf := p.argpo + p.argpdot*t
+	nodedf := p.nodeo + p.nodedot*t
+	argpm := argpdf
+	mm := xmdf
+	t2 := t * t
+	nodem := nodedf + p.nodecf*t2
+	tempa := 1.0 - p.cc1*t
+	tempe := p.bstar * p.cc4 * t
+	templ := p.t2cof * t2
+
+	if !p.isimp {
+		delomg := p.omgcof * t
+		delmtemp := 1.0 + p.eta*math.Cos(xmdf)
+		delm := p.xmcof * (delmtemp*delmtemp*delmtemp - p.delmo)
+		temp := delomg + delm
+		mm = xmdf + temp
+		argpm = argpdf - temp
+		t3 := t2 * t
+		t4 := t3 * t
+		tempa = tempa - p.d2*t2 - p.d3*t3 - p.d4*t4
+		tempe = tempe + p.bstar*p.cc5*(math.Sin(mm)-p.sinmao)
+		templ = templ + p.t3cof*t3 + t4*(p.t4cof+t*p.t5cof)
+	}
+
+	nm := p.noUnkozai
+	em := p.ecco
+	inclm := p.inclo
+	if p.deepSpace {
+		em, argpm, inclm, mm, nodem, nm = p.dspace(t, em, argpm, inclm, mm, nodem)
+	}
+
+	if nm <= 0.0 {
+		return r, v, ErrMeanMotion
+	}
+	am := math.Pow(xke/nm, x2o3) * tempa * tempa
+	nm = xke / math.Pow(am, 1.5)
+	em = em - tempe
+
+	if em >= 1.0 || em < -0.001 {
+		return r, v, ErrEccentricity
+	}
+	// avoid a divide by zero
+	if em < 1.0e-6 {
+		em = 1.0e-6
+	}
+	mm = mm + p.noUnkozai*templ
+	xlm := mm + argpm + nodem
+
+	nodem = math.Mod(nodem, twoPi)
+	argpm = math.Mod(argpm, twoPi)
+	xlm = math.Mod(xlm, twoPi)
+	mm = math.Mod(xlm-argpm-nodem, twoPi)
+
+	// compute extra mean quantities
+	sinim := math.Sin(inclm)
+	cosim := math.Cos(inclm)
+
+	// add lunar-solar periodics
+	ep := em
+	xincp := inclm
+	argpp := argpm
+	nodep := nodem
+	mp := mm
+	sinip := sinim
+	cosip := cosim
+	aycof := p.aycof
+	xlcof := p.xlcof
+	con41, x1mth2, x7thm1 := p.con41, p.x1mth2, p.x7thm1
+	if p.deepSpace {
+		ep, xincp, nodep, argpp, mp = p.dpper(t, ep, xincp, nodep, argpp, mp)
+		if xincp < 0.0 {
+			xincp = -xincp
+			nodep = nodep + math.Pi
+			argpp = argpp - math.Pi
+		}
+		if ep < 0.0 || ep > 1.0 {
+			return r, v, ErrPerturbedEcc
+		}
+
+		// long period periodics
+		sinip = math.Sin(xincp)
+		cosip = math.Cos(xincp)
+		aycof = -0.5 * j3oj2 * sinip
+		if math.Abs(cosip+1.0) > 1.5e-12 {
+			xlcof = -0.25 * j3oj2 * sinip * (3.0 + 5.0*cosip) / (1.0 + cosip)
+		} else {
+			xlcof = -0.25 * j3oj2 * sinip * (3.0 + 5.0*cosip) / temp4
+		}
+	}
+	axnl := ep * math.Cos(argpp)
+	temp := 1.0 / (am * (1.0 - ep*ep))
+	aynl := ep*math.Sin(argpp) + temp*aycof
+	xl := mp + argpp + nodep + temp*xlcof*axnl
+
+	// solve Kepler's equation
+	u := math.Mod(xl-nodep, twoPi)
+	eo1 := u
+	tem5 := 9999.9
+	var sineo1, coseo1 float64
+	for ktr := 1; math.Abs(tem5) >= 1.0e-12 && ktr <= 10; ktr++ {
+		sineo1 = math.Sin(eo1)
+		coseo1 = math.Cos(eo1)
+		tem5 = 1.0 - coseo1*axnl - sineo1*aynl
+		tem5 = (u - aynl*coseo1 + axnl*sineo1 - eo1) / tem5
+		if math.Abs(tem5) >= 0.95 {
+			if tem5 > 0.0 {
+				tem5 = 0.95
+			} else {
+				tem5 = -0.95
+			}
+		}
+		eo1 = eo1 + tem5
+	}
+
+	// short period preliminary quantities
+	ecose := axnl*coseo1 + aynl*sineo1
+	esine := axnl*sineo1 - aynl*coseo1
+	el2 := axnl*axnl + aynl*aynl
+	pl := am * (1.0 - el2)
+	if pl < 0.0 {
+		return r, v, ErrSemiLatusRectum
+	}
+
+	rl := am * (1.0 - ecose)
+	rdotl := math.Sqrt(am) * esine / rl
+	rvdotl := math.Sqrt(pl) / rl
+	betal := math.Sqrt(1.0 - el2)
+	temp = esine / (1.0 + betal)
+	sinu := am / rl * (sineo1 - aynl - axnl*temp)
+	cosu := am / rl * (coseo1 - axnl + aynl*temp)
+	su := math.Atan2(sinu, cosu)
+	sin2u := (cosu + cosu) * sinu
+	cos2u := 1.0 - 2.0*sinu*sinu
+	temp = 1.0 / pl
+	temp1 := 0.5 * j2 * temp
+	temp2 := temp1 * temp
+
+	// update for short period periodics
+	if p.deepSpace {
+		cosisq := cosip * cosip
+		con41 = 3.0*cosisq - 1.0
+		x1mth2 = 1.0 - cosisq
+		x7thm1 = 7.0*cosisq - 1.0
+	}
+	mrt := rl*(1.0-1.5*temp2*betal*con41) + 0.5*temp1*x1mth2*cos2u
+	su = su - 0.25*temp2*x7thm1*sin2u
+	xnode := nodep + 1.5*temp2*cosip*sin2u
+	xinc := xincp + 1.5*temp2*cosip*sinip*cos2u
+	mvt := rdotl - nm*temp1*x1mth2*sin2u/xke
+	rvdot := rvdotl + nm*temp1*(x1mth2*cos2u+1.5*con41)/xke
+
+	// orientation vectors
+	sinsu := math.Sin(su)
+	cossu := math.Cos(su)
+	snod := math.Sin(xnode)
+	cnod := math.Cos(xnode)
+	sini := math.Sin(xinc)
+	cosi := math.Cos(xinc)
+	xmx := -snod * cosi
+	xmy := cnod * cosi
+	ux := xmx*sinsu + cnod*cossu
+	uy := xmy*sinsu + snod*cossu
+	uz := sini * sinsu
+	vx := xmx*cossu - cnod*sinsu
+	vy := xmy*cossu - snod*sinsu
+	vz := sini * cossu
+
+	// position and velocity in km and km/s
+	r[0] = mrt * ux * radiusEarthKm
+	r[1] = mrt * uy * radiusEarthKm
+	r[2] = mrt * uz * radiusEarthKm
+	v[0] = (mvt*ux + rvdot*vx) * vkmpersec
+	v[1] = (mvt*uy + rvdot*vy) * vkmpersec
+	v[2] = (mvt*uz + rvdot*vz) * vkmpersec
+
+	if mrt < 1.0 {
+		return r, v, ErrDecayed
+	}
+
+	return r, v, nil
+}
@@ -0,0 +1,153 @@
+package sgp4
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/Funkit/tle-provider/data"
+)
+
+// verification cases from the test set published with "Revisiting Spacetrack Report #3"
+var (
+	vanguard = data.Satellite{
+		SatelliteName: "VANGUARD 1",
+		NORADID:       5,
+		TLELine1:      "1 00005U 58002B   00179.78495062  .00000023  00000-0  28098-4 0  4753",
+		TLELine2:      "2 00005  34.2682 348.7242 1859667 331.7664  19.3264 10.82419157413667",
+	}
+	molniya = data.Satellite{
+		SatelliteName: "11801",
+		NORADID:       11801,
+		TLELine1:      "1 11801U          80230.29629788  .01431103  00000-0  14311-1 0    13",
+		TLELine2:      "2 11801  46.7916 230.4354 7318036  47.4722  10.4117  2.28537848    13",
+	}
+	geosynchronous = data.Satellite{
+		SatelliteName: "28626",
+		NORADID:       28626,
+		TLELine1:      "1 28626U 05008A   06176.46683397 -.00000205  00000-0  10000-3 0  2190",
+		TLELine2:      "2 28626   0.0019 286.9433 0000335  13.7918  55.6504  1.00270176  4891",
+	}
+)
+
+func TestPropagate(t *testing.T) {
+	tests := []struct {
+		name         string
+		sat          data.Satellite
+		minutes      float64
+		wantPosition [3]float64
+		wantVelocity [3]float64
+		tolerance    float64
+	}{
+		{"near earth at epoch", vanguard, 0, [3]float64{7022.46529266, -1400.08296755, 0.03995155}, [3]float64{1.893841015, 6.405893759, 4.534807250}, 1e-3},
+		{"near earth", vanguard, 360, [3]float64{-7154.03120202, -3783.17682504, -3536.19412294}, [3]float64{4.741887409, -4.151817765, -2.093935425}, 1e-3},
+		{"deep space at epoch", molniya, 0, [3]float64{7473.37066650, 428.95261765, 5828.74786377}, [3]float64{5.10715130, 6.44468284, -0.18613096}, 1e-2},
+		{"deep space half-day resonance 360 min", molniya, 360, [3]float64{-3305.22148694, 32410.84323331, -24697.16974954}, [3]float64{-1.30113732, -1.15131560, -0.28333582}, 1e-2},
+		{"deep space half-day resonance 720 min", molniya, 720, [3]float64{14271.29083858, 24110.44309009, -4725.76320143}, [3]float64{-0.32050453, 2.67984154, -2.08405435}, 1e-2},
+		{"deep space half-day resonance 1080 min", molniya, 1080, [3]float64{-9990.05800009, 22717.34212448, -23616.88515553}, [3]float64{-1.01667439, -2.29026798, 0.72892334}, 1e-2},
+		{"deep space half-day resonance 1440 min", molniya, 1440, [3]float64{9787.87836256, 33753.32249667, -15030.79874625}, [3]float64{-1.09425155, 0.92358991, -1.52231101}, 1e-2},
+		{"geosynchronous resonance at epoch", geosynchronous, 0, [3]float64{42080.71852213, -2646.86387436, 0.81851294}, [3]float64{0.19310518, 3.06868825, 0.00043845}, 1e-2},
+		{"geosynchronous resonance 720 min", geosynchronous, 720, [3]float64{-42103.20138132, 2291.06228893, -0.13274964}, [3]float64{-0.16697482, -3.07010456, -0.00031101}, 1e-2},
+		{"geosynchronous resonance 1440 min", geosynchronous, 1440, [3]float64{42119.96263499, -1925.77567263, -0.19827433}, [3]float64{0.14052121, 3.07154161, 0.00017956}, 1e-2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(tt.sat)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			got, err := p.Propagate(p.Epoch.Add(time.Duration(tt.minutes * float64(time.Minute))))
+			if err != nil {
+				t.Fatalf("Propagate() error = %v", err)
+			}
+			for i := range got.Position {
+				if math.Abs(got.Position[i]-tt.wantPosition[i]) > tt.tolerance {
+					t.Errorf("position = %v, want %v", got.Position, tt.wantPosition)
+					break
+				}
+			}
+			for i := range got.Velocity {
+				if math.Abs(got.Velocity[i]-tt.wantVelocity[i]) > tt.tolerance/1000 {
+					t.Errorf("velocity = %v, want %v", got.Velocity, tt.wantVelocity)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestPropagateDeepSpaceResonance(t *testing.T) {
+	epoch := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		elements   data.KeplerianElements
+		wantPeriod time.Duration
+		minRadius  float64
+		maxRadius  float64
+	}{
+		{
+			name:       "geostationary",
+			elements:   data.KeplerianElements{NORADID: 99901, Epoch: epoch, MeanMotion: 1.00273791, Eccentricity: 0.0001, Inclination: 0.05, RAAN: 80, MeanAnomaly: 120},
+			wantPeriod: 23*time.Hour + 56*time.Minute,
+			minRadius:  42164 - 50,
+			maxRadius:  42164 + 50,
+		},
+		{
+			name:       "molniya",
+			elements:   data.KeplerianElements{NORADID: 99902, Epoch: epoch, MeanMotion: 2.00563, Eccentricity: 0.72, Inclination: 63.4, RAAN: 200, ArgOfPericenter: 270, MeanAnomaly: 10},
+			wantPeriod: 11*time.Hour + 58*time.Minute,
+			minRadius:  6378 + 400,
+			maxRadius:  6378 + 40000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sat, err := data.GenerateTLE(tt.elements)
+			if err != nil {
+				t.Fatalf("GenerateTLE() error = %v", err)
+			}
+			p, err := New(sat)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if diff := p.Period() - tt.wantPeriod; diff < -time.Minute || diff > time.Minute {
+				t.Errorf("Period() = %v, want %v", p.Period(), tt.wantPeriod)
+			}
+
+			// the resonance terms are integrated in both directions, over several integration steps
+			for _, hours := range []float64{-72, 0, 5, 13, 24, 24 * 7, 24 * 30} {
+				state, err := p.Propagate(p.Epoch.Add(time.Duration(hours * float64(time.Hour))))
+				if err != nil {
+					t.Fatalf("Propagate() error = %v", err)
+				}
+				r := math.Sqrt(state.Position[0]*state.Position[0] + state.Position[1]*state.Position[1] + state.Position[2]*state.Position[2])
+				if r < tt.minRadius || r > tt.maxRadius {
+					t.Errorf("radius after %v hours = %v km, want between %v and %v", hours, r, tt.minRadius, tt.maxRadius)
+				}
+			}
+		})
+	}
+}
+
+func TestPropagateDecayed(t *testing.T) {
+	sat, err := data.GenerateTLE(data.KeplerianElements{
+		NORADID:   99902,
+		Epoch:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		ApogeeKm:  180,
+		PerigeeKm: 150,
+		BStar:     0.5,
+	})
+	if err != nil {
+		t.Fatalf("GenerateTLE() error = %v", err)
+	}
+	p, err := New(sat)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := p.Propagate(p.Epoch.Add(30 * 24 * time.Hour)); err == nil {
+		t.Errorf("Propagate() of a decayed satellite did not fail")
+	}
+}